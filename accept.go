@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// WithAccept is a client option for accepting the response media types (e.g. application/json
+// and application/yaml), ordered by preference, instead of the ContentType of the client. They
+// are sent in the Accept header and the response body is decoded with the media type of the
+// response, if it is accepted, or the first accepted media type. If decoding fails, the next one
+// is tried, e.g. during migrations from one media type to another. The media types must be
+// supported by the Unmarshaler (or UnmarshalerContext) of the client.
+func WithAccept(mediaTypes ...string) Opt {
+	return func(c *Client) error {
+		if len(mediaTypes) == 0 {
+			return errors.New("media types cannot be empty")
+		}
+
+		for _, mt := range mediaTypes {
+			if _, _, err := mime.ParseMediaType(mt); err != nil {
+				return fmt.Errorf("invalid media type %s: %w", mt, err)
+			}
+		}
+
+		c.accept = append([]string(nil), mediaTypes...)
+
+		return nil
+	}
+}
+
+// unmarshalAccepted decodes the body of resp into v with the first accepted media type which
+// succeeds.
+func (c *Client) unmarshalAccepted(ctx context.Context, unmarshaler UnmarshalerContextFunc, resp *http.Response, v interface{}) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, mt := range c.acceptedMediaTypes(resp) {
+		if len(errs) > 0 {
+			resetValue(v)
+		}
+
+		r := *resp
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		err := unmarshaler(ctx, &r, v, mt)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", mt, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// acceptedMediaTypes returns the accepted media types in the order they are tried for resp.
+func (c *Client) acceptedMediaTypes(resp *http.Response) []string {
+	mediaTypes := make([]string, 0, len(c.accept))
+
+	if mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		for _, accepted := range c.accept {
+			if strings.EqualFold(mediaType(accepted), mt) {
+				mediaTypes = append(mediaTypes, accepted)
+				break
+			}
+		}
+	}
+
+	for _, accepted := range c.accept {
+		if len(mediaTypes) == 0 || accepted != mediaTypes[0] {
+			mediaTypes = append(mediaTypes, accepted)
+		}
+	}
+
+	return mediaTypes
+}
+
+// mediaType returns the media type of mt without parameters.
+func mediaType(mt string) string {
+	t, _, _ := mime.ParseMediaType(mt)
+	return t
+}
+
+// resetValue sets the value v points to to its zero value, if v is a pointer.
+func resetValue(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	}
+}
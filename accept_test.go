@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAccept(t *testing.T) {
+	var accept string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+
+		switch r.URL.Path {
+		case "/yaml":
+			w.Header().Set("Content-Type", ContentTypeYAML)
+			_, _ = w.Write([]byte("text: yaml\n"))
+		case "/mislabeled":
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			_, _ = w.Write([]byte("text: mislabeled\n"))
+		case "/json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"Text":"json"}`))
+		default:
+			_, _ = w.Write([]byte("{"))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithAccept(ContentTypeJSON, ContentTypeYAML))
+	assert.Nil(t, err)
+
+	for _, path := range []string{"yaml", "mislabeled", "json"} {
+		path := path
+		t.Run(path, func(t *testing.T) {
+			req, err := c.NewRequest(http.MethodGet, path, nil)
+			assert.Nil(t, err)
+
+			m := message{}
+
+			_, err = c.Do(context.Background(), req, &m)
+			assert.Nil(t, err)
+			assert.Equal(t, path, m.Text)
+			assert.Equal(t, "application/json, application/yaml", accept)
+		})
+	}
+
+	t.Run("no media type succeeds", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "invalid", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, &message{})
+		assert.EqualError(t, err, "application/json: unexpected EOF\napplication/yaml: yaml: line 1: did not find expected node content")
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "mislabeled", nil)
+		assert.Nil(t, err)
+
+		r, err := DoResult[string](context.Background(), c, req)
+		assert.NotNil(t, err)
+		assert.Equal(t, "text: mislabeled\n", string(r.Body()))
+	})
+
+	t.Run("invalid media type", func(t *testing.T) {
+		_, err := New(ts.URL, WithAccept("application/"))
+		assert.NotNil(t, err)
+
+		_, err = New(ts.URL, WithAccept())
+		assert.EqualError(t, err, "media types cannot be empty")
+	})
+}
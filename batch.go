@@ -0,0 +1,164 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+)
+
+// ErrBatchResponse is the error of the calls of a Batch without response part.
+var ErrBatchResponse = errors.New("missing batch response")
+
+// Batch packs several requests into one multipart/mixed request, e.g. for the OData $batch
+// endpoint, and splits the multipart response into the responses of the calls:
+//
+//	b := httpclient.NewBatch(c)
+//	post := b.Add(getPostRequest, &p)
+//	user := b.Add(getUserRequest, &u)
+//	if _, err := b.Do(ctx, "$batch"); err != nil {
+//		...
+//	}
+//	if post.Err != nil {
+//		...
+//	}
+//
+// Each request is encoded as part with the media type application/http, the responses are
+// expected in the same order.
+type Batch struct {
+	client *Client
+	calls  []*BatchCall
+}
+
+// BatchCall is a call of a Batch. After Batch.Do, Response is the response of the call and
+// Err the error of the response middleware or the ResponseCallback of the client or of decoding
+// the body (see Do).
+type BatchCall struct {
+	Response *http.Response
+	Err      error
+
+	req *http.Request
+	v   interface{}
+}
+
+// NewBatch returns a new Batch of the client c.
+func NewBatch(c *Client) *Batch {
+	return &Batch{client: c}
+}
+
+// Add adds the request req (e.g. created with NewRequest) to the batch. Its response body is
+// decoded into v like with Do.
+func (b *Batch) Add(req *http.Request, v interface{}) *BatchCall {
+	call := &BatchCall{req: req, v: v}
+	b.calls = append(b.calls, call)
+
+	return call
+}
+
+// Do sends the batch as POST request to urlStr (see NewRequest) and sets the results of the
+// calls. It returns an error if the batch request itself fails, the errors of the calls are in
+// BatchCall.Err.
+func (b *Batch) Do(ctx context.Context, urlStr string, opts ...RequestOpt) (*http.Response, error) {
+	c := b.client
+
+	ctx, cancel := c.withDefaultDeadline(ctx)
+	defer cancel()
+
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+
+	for i, call := range b.calls {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"application/http"},
+			"Content-Transfer-Encoding": {"binary"},
+			"Content-Id":                {strconv.Itoa(i + 1)},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := call.req.Write(part); err != nil {
+			return nil, fmt.Errorf("batch request %d: %w", i+1, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	contentType := mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": w.Boundary()})
+
+	req, err := c.newRequest(ctx, http.MethodPost, urlStr, body, contentType, "multipart/mixed", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.send(ctx, req)
+	if err != nil || resp == nil {
+		if resp != nil && resp.Body != nil {
+			_ = c.closeBody(resp.Body)
+		}
+
+		return resp, err
+	}
+
+	defer func() {
+		_ = c.closeBody(resp.Body)
+	}()
+
+	return resp, b.split(resp)
+}
+
+// split splits the multipart response resp into the responses of the calls.
+func (b *Batch) split(resp *http.Response) error {
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" || params["boundary"] == "" {
+		return fmt.Errorf("unexpected batch response content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	r := multipart.NewReader(resp.Body, params["boundary"])
+
+	for _, call := range b.calls {
+		part, err := r.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		call.Response, call.Err = b.response(part, call)
+	}
+
+	for _, call := range b.calls {
+		if call.Response == nil && call.Err == nil {
+			call.Err = ErrBatchResponse
+		}
+	}
+
+	return nil
+}
+
+// response reads the response of call from part and decodes its body.
+func (b *Batch) response(part *multipart.Part, call *BatchCall) (*http.Response, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(part), call.req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp, err = b.client.responseMiddlewareChain(resp); err != nil {
+		return resp, err
+	}
+
+	return resp, b.client.Unmarshal(resp, call.v)
+}
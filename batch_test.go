@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchHandler answers the requests of a batch, the request /missing is not answered.
+func batchHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		assert.Nil(t, err)
+
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		mw := multipart.NewWriter(w)
+
+		w.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+
+			assert.Equal(t, "application/http", part.Header.Get("Content-Type"))
+
+			req, err := http.ReadRequest(bufio.NewReader(part))
+			assert.Nil(t, err)
+
+			if req.URL.Path == "/missing" {
+				continue
+			}
+
+			pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/http"}})
+			assert.Nil(t, err)
+
+			if req.URL.Path == "/notfound" {
+				fmt.Fprint(pw, "HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n")
+				continue
+			}
+
+			body := fmt.Sprintf(`{"Text":"%s %s"}`, req.Method, req.URL.Path)
+			fmt.Fprintf(pw, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+		}
+
+		_ = mw.Close()
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ts := httptest.NewServer(batchHandler(t))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	newRequest := func(method, path string) *http.Request {
+		req, err := c.NewRequest(method, path, nil)
+		assert.Nil(t, err)
+
+		return req
+	}
+
+	t.Run("calls", func(t *testing.T) {
+		var get, del message
+
+		b := NewBatch(c)
+		getCall := b.Add(newRequest(http.MethodGet, "/posts/1"), &get)
+		notFoundCall := b.Add(newRequest(http.MethodGet, "/notfound"), &message{})
+		delCall := b.Add(newRequest(http.MethodDelete, "/posts/2"), &del)
+
+		resp, err := b.Do(context.Background(), "$batch")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.Nil(t, getCall.Err)
+		assert.Equal(t, "GET /posts/1", get.Text)
+
+		var httpErr *HTTPError
+
+		assert.True(t, errors.As(notFoundCall.Err, &httpErr))
+		assert.Equal(t, http.StatusNotFound, notFoundCall.Response.StatusCode)
+
+		assert.Nil(t, delCall.Err)
+		assert.Equal(t, "DELETE /posts/2", del.Text)
+	})
+
+	t.Run("missing response", func(t *testing.T) {
+		b := NewBatch(c)
+		missing := b.Add(newRequest(http.MethodGet, "/missing"), nil)
+
+		_, err := b.Do(context.Background(), "$batch")
+		assert.Nil(t, err)
+		assert.True(t, errors.Is(missing.Err, ErrBatchResponse))
+	})
+
+	t.Run("no multipart response", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer ts.Close()
+
+		c, err := New(ts.URL)
+		assert.Nil(t, err)
+
+		_, err = NewBatch(c).Do(context.Background(), "$batch")
+		assert.EqualError(t, err, `unexpected batch response content type "text/plain; charset=utf-8"`)
+	})
+}
@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type benchItem struct {
+	ID   int    `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// benchItems returns n items.
+func benchItems(n int) []benchItem {
+	items := make([]benchItem, n)
+	for i := range items {
+		items[i] = benchItem{i, strings.Repeat("x", 32)}
+	}
+
+	return items
+}
+
+func BenchmarkNewRequest(b *testing.B) {
+	c, err := New(baseurl,
+		WithUsername(username),
+		WithPassword(password),
+		WithHeader(http.Header{"X-Requested-By": {"benchmark"}, "X-Tenant": {"a", "b"}}),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("no body", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := c.NewRequest(http.MethodGet, "nodes", nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	for _, ct := range []string{ContentTypeJSON, ContentTypeYAML} {
+		for _, n := range []int{1, 100, 10000} {
+			c := c.Clone()
+			c.ContentType = ct
+			body := benchItems(n)
+
+			b.Run(fmt.Sprintf("%s/%d", ct, n), func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					if _, err := c.NewRequest(http.MethodPost, "nodes", body); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDo(b *testing.B) {
+	for _, ct := range []string{ContentTypeJSON, ContentTypeYAML} {
+		for _, n := range []int{1, 100, 10000} {
+			payload := new(strings.Builder)
+			if _, err := marshal(payload, benchItems(n), ct); err != nil {
+				b.Fatal(err)
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, payload.String())
+			}))
+
+			c, err := New(ts.URL, WithContentType(ct))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.Run(fmt.Sprintf("%s/%d", ct, n), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(payload.Len()))
+
+				for i := 0; i < b.N; i++ {
+					req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+					if err != nil {
+						b.Fatal(err)
+					}
+
+					items := []benchItem{}
+					if _, err := c.Do(context.Background(), req, &items); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/%d/raw", ct, n), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(payload.Len()))
+
+				for i := 0; i < b.N; i++ {
+					req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+					if err != nil {
+						b.Fatal(err)
+					}
+
+					if _, err := c.Do(context.Background(), req, ioutil.Discard); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			ts.Close()
+		}
+	}
+}
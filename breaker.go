@@ -0,0 +1,267 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned for requests not sent because the circuit breaker of the client is
+// open (see WithCircuitBreaker).
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// States of a circuit breaker.
+const (
+	// CircuitClosed sends the requests.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen fails the requests with ErrCircuitOpen.
+	CircuitOpen
+
+	// CircuitHalfOpen sends trial requests to check whether the server recovered.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreakerPolicy controls the circuit breaker of a client (see WithCircuitBreaker).
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failed requests opening the circuit.
+	FailureThreshold int
+
+	// Cooldown is the time the circuit stays open before it becomes half-open.
+	Cooldown time.Duration
+
+	// HalfOpenRequests is the number of concurrent trial requests while the circuit is
+	// half-open (default 1). The circuit is closed if a trial request succeeds and opened
+	// again if it fails. Requests failed by their canceled or expired context neither
+	// succeed nor fail.
+	HalfOpenRequests int
+
+	// IsFailure reports whether a request failed with the response (after the
+	// ResponseCallback) and the error of Do, if nil network errors and responses with status
+	// 429 or 5xx are failures.
+	IsFailure func(*http.Response, error) bool
+
+	// OnStateChange is called after the state of the circuit changed, e.g. for logging. It is
+	// called by the goroutine of the request changing the state.
+	OnStateChange func(from, to CircuitState)
+}
+
+// WithCircuitBreaker is a client option for failing the requests of Do fast with
+// ErrCircuitOpen, instead of sending them to a failing server: after FailureThreshold
+// consecutive failures the circuit opens for the Cooldown, afterwards trial requests are sent
+// until one of them succeeds (see CircuitBreakerPolicy). The circuit breaker is shared by the
+// copies of the client (see Clone). With WithRetry, every attempt is a request of the circuit
+// breaker and ErrCircuitOpen is not retried.
+func WithCircuitBreaker(p CircuitBreakerPolicy) Opt {
+	return func(c *Client) error {
+		if p.FailureThreshold < 1 {
+			return errors.New("failure threshold must be at least 1")
+		}
+
+		if p.Cooldown <= 0 {
+			return errors.New("cooldown must be positive")
+		}
+
+		if p.HalfOpenRequests < 0 {
+			return errors.New("half-open requests cannot be negative")
+		}
+
+		if p.HalfOpenRequests == 0 {
+			p.HalfOpenRequests = 1
+		}
+
+		if p.IsFailure == nil {
+			p.IsFailure = circuitFailure
+		}
+
+		c.breaker = &circuitBreaker{policy: p}
+
+		return nil
+	}
+}
+
+// CircuitState returns the state of the circuit breaker of the client, CircuitClosed without
+// WithCircuitBreaker.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+
+	return c.breaker.currentState(c.Now())
+}
+
+// circuitBreaker is the state of a circuit breaker.
+type circuitBreaker struct {
+	policy   CircuitBreakerPolicy
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+	changes  [][2]CircuitState
+}
+
+// currentState returns the state at the time now.
+func (b *circuitBreaker) currentState(now time.Time) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && !now.Before(b.openedAt.Add(b.policy.Cooldown)) {
+		return CircuitHalfOpen
+	}
+
+	return b.state
+}
+
+// allow reports whether a request can be sent at the time now and returns the state it is sent
+// in, which has to be passed to done.
+func (b *circuitBreaker) allow(now time.Time) (CircuitState, error) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	if b.state == CircuitOpen {
+		if now.Before(b.openedAt.Add(b.policy.Cooldown)) {
+			return b.state, ErrCircuitOpen
+		}
+
+		b.setState(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.trials >= b.policy.HalfOpenRequests {
+			return b.state, ErrCircuitOpen
+		}
+
+		b.trials++
+	}
+
+	return b.state, nil
+}
+
+// release releases the trial slot of a request sent in the state s without recording a result.
+func (b *circuitBreaker) release(s CircuitState) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.releaseTrial(s)
+}
+
+// releaseTrial releases the trial slot of a request sent in the state s.
+func (b *circuitBreaker) releaseTrial(s CircuitState) {
+	if s == CircuitHalfOpen && b.state == CircuitHalfOpen {
+		b.trials--
+	}
+}
+
+// done records the result of a request sent in the state s at the time now.
+func (b *circuitBreaker) done(s CircuitState, failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.unlock()
+
+	b.releaseTrial(s)
+
+	switch {
+	case !failed:
+		b.failures = 0
+
+		if b.state == CircuitHalfOpen {
+			b.setState(CircuitClosed)
+		}
+	case b.state == CircuitHalfOpen:
+		b.open(now)
+	case b.state == CircuitClosed:
+		if b.failures++; b.failures >= b.policy.FailureThreshold {
+			b.open(now)
+		}
+	}
+}
+
+// open opens the circuit at the time now.
+func (b *circuitBreaker) open(now time.Time) {
+	b.openedAt = now
+	b.failures = 0
+	b.setState(CircuitOpen)
+}
+
+// setState sets the state to s.
+func (b *circuitBreaker) setState(s CircuitState) {
+	from := b.state
+	if from == s {
+		return
+	}
+
+	b.state = s
+	b.trials = 0
+
+	if b.policy.OnStateChange != nil {
+		b.changes = append(b.changes, [2]CircuitState{from, s})
+	}
+}
+
+// unlock unlocks the circuit breaker and calls OnStateChange for the state changes.
+func (b *circuitBreaker) unlock() {
+	changes := b.changes
+	b.changes = nil
+
+	b.mu.Unlock()
+
+	for _, ch := range changes {
+		b.policy.OnStateChange(ch[0], ch[1])
+	}
+}
+
+// circuitFailure reports whether resp or err is a network error or a response with status 429
+// (too many requests) or 5xx.
+func circuitFailure(resp *http.Response, err error) bool {
+	if resp != nil {
+		return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	}
+
+	var urlErr *url.Error
+
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+}
+
+// doBreaker sends the request req with do through the circuit breaker of the client, if it has
+// one.
+func (c *Client) doBreaker(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	if c.breaker == nil {
+		return c.do(ctx, req, v)
+	}
+
+	s, err := c.breaker.allow(c.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, req, v)
+	if err != nil && ctx.Err() != nil {
+		// failed by the caller, not by the server
+		c.breaker.release(s)
+		return resp, err
+	}
+
+	c.breaker.done(s, c.breaker.policy.IsFailure(resp, err), c.Now())
+
+	return resp, err
+}
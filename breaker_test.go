@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		hits   int32
+		status int32 = http.StatusServiceUnavailable
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer ts.Close()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var changes []string
+
+	c, err := New(ts.URL,
+		WithClock(ClockFunc(func() time.Time { return now })),
+		WithCircuitBreaker(CircuitBreakerPolicy{
+			FailureThreshold: 2,
+			Cooldown:         time.Minute,
+			OnStateChange: func(from, to CircuitState) {
+				changes = append(changes, from.String()+" -> "+to.String())
+			},
+		}),
+	)
+	assert.Nil(t, err)
+
+	do := func() error {
+		req, err := c.NewRequest(http.MethodGet, "", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+
+		return err
+	}
+
+	t.Run("open", func(t *testing.T) {
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(do()))
+		assert.Equal(t, CircuitClosed, c.CircuitState())
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(do()))
+		assert.Equal(t, CircuitOpen, c.CircuitState())
+
+		assert.True(t, errors.Is(do(), ErrCircuitOpen))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("half-open failure", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		assert.Equal(t, CircuitHalfOpen, c.CircuitState())
+
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(do()))
+		assert.Equal(t, CircuitOpen, c.CircuitState())
+		assert.True(t, errors.Is(do(), ErrCircuitOpen))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("half-open success", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		atomic.StoreInt32(&status, http.StatusNotFound) // no failure
+
+		assert.Equal(t, http.StatusNotFound, statusCode(do()))
+		assert.Equal(t, CircuitClosed, c.CircuitState())
+		assert.Equal(t, http.StatusNotFound, statusCode(do()))
+		assert.Equal(t, CircuitClosed, c.Clone().CircuitState())
+	})
+
+	t.Run("state changes", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"closed -> open",
+			"open -> half-open",
+			"half-open -> open",
+			"open -> half-open",
+			"half-open -> closed",
+		}, changes)
+	})
+
+	t.Run("canceled trial", func(t *testing.T) {
+		atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(do()))
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(do()))
+		assert.Equal(t, CircuitOpen, c.CircuitState())
+
+		now = now.Add(time.Minute)
+		atomic.StoreInt32(&status, http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req, err := c.NewRequest(http.MethodGet, "", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, CircuitHalfOpen, c.CircuitState())
+
+		assert.Nil(t, do()) // the trial slot was released
+		assert.Equal(t, CircuitClosed, c.CircuitState())
+	})
+
+	t.Run("half-open requests", func(t *testing.T) {
+		b := &circuitBreaker{policy: CircuitBreakerPolicy{FailureThreshold: 1, Cooldown: time.Minute, HalfOpenRequests: 1, IsFailure: circuitFailure}}
+		b.open(now)
+
+		s, err := b.allow(now.Add(time.Minute))
+		assert.Nil(t, err)
+		assert.Equal(t, CircuitHalfOpen, s)
+
+		_, err = b.allow(now.Add(time.Minute))
+		assert.Equal(t, ErrCircuitOpen, err)
+
+		b.done(s, false, now.Add(time.Minute))
+		assert.Equal(t, CircuitClosed, b.currentState(now))
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		assert.False(t, retryableDefault(nil, ErrCircuitOpen, nil))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, p := range []CircuitBreakerPolicy{
+			{Cooldown: time.Second},
+			{FailureThreshold: 1},
+			{FailureThreshold: 1, Cooldown: time.Second, HalfOpenRequests: -1},
+		} {
+			_, err := New(ts.URL, WithCircuitBreaker(p))
+			assert.NotNil(t, err)
+		}
+
+		assert.Equal(t, "unknown", CircuitState(-1).String())
+	})
+}
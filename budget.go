@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+// ErrMemoryBudget is returned if buffering a response body would exceed the MemoryBudget.
+var ErrMemoryBudget = errors.New("memory budget exceeded")
+
+// MemoryBudget limits the memory (bytes) of the response bodies buffered by the in-flight
+// requests of the clients sharing it (see WithMemoryBudget), e.g. to protect small services
+// from concurrent huge responses. The memory is released when the request is done, for
+// coalesced requests (see WithSingleflight) when all callers decoded the shared response.
+type MemoryBudget struct {
+	mu   sync.Mutex
+	size int64
+	used int64
+}
+
+// NewMemoryBudget returns a MemoryBudget of size bytes.
+func NewMemoryBudget(size int64) *MemoryBudget {
+	return &MemoryBudget{size: size}
+}
+
+// Used returns the number of bytes in use.
+func (b *MemoryBudget) Used() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.used
+}
+
+// reserve reserves n bytes and reports whether they are available.
+func (b *MemoryBudget) reserve(n int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.used+n > b.size {
+		return false
+	}
+
+	b.used += n
+
+	return true
+}
+
+// release releases n reserved bytes.
+func (b *MemoryBudget) release(n int64) {
+	b.mu.Lock()
+	b.used -= n
+	b.mu.Unlock()
+}
+
+// WithMemoryBudget is a client option for limiting the memory of the buffered response bodies
+// (DoResult and the generic helpers, WithSingleflight) with the budget b, which can be shared by
+// several clients. Requests exceeding the budget fail with ErrMemoryBudget, the memory of
+// responses decoded by Do is not limited.
+func WithMemoryBudget(b *MemoryBudget) Opt {
+	return func(c *Client) error {
+		if b == nil || b.size <= 0 {
+			return errors.New("memory budget must be positive")
+		}
+
+		c.budget = b
+
+		return nil
+	}
+}
+
+// bodyBuffer is a buffer for response bodies charged to a MemoryBudget, if it is not nil. It
+// does not embed bytes.Buffer, io.Copy would bypass Write with its ReadFrom.
+type bodyBuffer struct {
+	buf      bytes.Buffer
+	budget   *MemoryBudget
+	reserved int64
+}
+
+// Write appends p to the buffer, if the budget allows it.
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	if b.budget != nil {
+		if !b.budget.reserve(int64(len(p))) {
+			return 0, ErrMemoryBudget
+		}
+
+		b.reserved += int64(len(p))
+	}
+
+	return b.buf.Write(p)
+}
+
+// Bytes returns the buffered bytes.
+func (b *bodyBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// release releases the memory reserved by the buffer.
+func (b *bodyBuffer) release() {
+	if b.budget != nil {
+		b.budget.release(b.reserved)
+		b.reserved = 0
+	}
+}
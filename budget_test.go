@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = w.Write([]byte(`"` + strings.Repeat("x", 1000) + `"`))
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+
+	t.Run("within budget", func(t *testing.T) {
+		b := NewMemoryBudget(2000)
+
+		c, err := New(ts.URL, WithMemoryBudget(b))
+		assert.Nil(t, err)
+
+		r, err := Get[string](ctx, c, "nodes")
+		assert.Nil(t, err)
+		assert.Len(t, r.Value, 1000)
+		assert.Equal(t, int64(0), b.Used())
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		b := NewMemoryBudget(500)
+
+		c, err := New(ts.URL, WithMemoryBudget(b))
+		assert.Nil(t, err)
+
+		_, err = Get[string](ctx, c, "nodes")
+		assert.True(t, errors.Is(err, ErrMemoryBudget))
+		assert.Equal(t, int64(0), b.Used())
+	})
+
+	t.Run("shared by clients", func(t *testing.T) {
+		b := NewMemoryBudget(1500)
+		assert.True(t, b.reserve(1000))
+
+		c, err := New(ts.URL, WithMemoryBudget(b), WithSingleflight())
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, new(string))
+		assert.True(t, errors.Is(err, ErrMemoryBudget))
+
+		b.release(1000)
+
+		_, err = c.Do(ctx, req, new(string))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), b.Used())
+	})
+
+	t.Run("shared response", func(t *testing.T) {
+		b := NewMemoryBudget(1500)
+		release := make(chan struct{})
+
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			ts.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer slow.Close()
+
+		var (
+			mu   sync.Mutex
+			used []int64
+		)
+
+		c, err := New(slow.URL, WithMemoryBudget(b), WithSingleflight(), WithUnmarshalerContext(func(_ context.Context, r *http.Response, v interface{}, mediaType string) error {
+			mu.Lock()
+			used = append(used, b.Used())
+			mu.Unlock()
+
+			return unmarshal(r.Body, v, mediaType)
+		}))
+		assert.Nil(t, err)
+
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+				assert.Nil(t, err)
+
+				_, err = c.Do(ctx, req, new(string))
+				assert.Nil(t, err)
+			}()
+		}
+
+		time.Sleep(50 * time.Millisecond) // let all callers join
+		close(release)
+		wg.Wait()
+
+		// the body stays reserved until every caller decoded it
+		assert.Equal(t, []int64{1002, 1002, 1002}, used)
+		assert.Equal(t, int64(0), b.Used())
+	})
+
+	t.Run("invalid budget", func(t *testing.T) {
+		_, err := New(ts.URL, WithMemoryBudget(NewMemoryBudget(0)))
+		assert.NotNil(t, err)
+
+		_, err = New(ts.URL, WithMemoryBudget(nil))
+		assert.NotNil(t, err)
+	})
+}
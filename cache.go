@@ -0,0 +1,185 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCache is a client option for caching the responses with status 200 (OK) of GET requests
+// for the duration ttl, e.g. for reference data. The responses are cached by URL and request
+// headers, responses with Cache-Control no-store are not cached. The cache is shared by the
+// copies of the client (see Clone) and can be warmed and invalidated with the methods of
+// Client.Cache.
+func WithCache(ttl time.Duration) Opt {
+	return func(c *Client) error {
+		if ttl <= 0 {
+			return errors.New("cache ttl must be positive")
+		}
+
+		c.cache = &Cache{client: c, ttl: ttl, entries: map[string]*cacheEntry{}}
+
+		return nil
+	}
+}
+
+// Cache are the cached responses of a client (see WithCache).
+type Cache struct {
+	client  *Client
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	url      *url.URL
+	response *sharedResponse
+	expires  time.Time
+}
+
+// Cache returns the cache of the client, nil without WithCache.
+func (c *Client) Cache() *Cache {
+	return c.cache
+}
+
+// Prefetch sends GET requests for urls (see NewRequest) concurrently with the client of the
+// option WithCache and caches their responses, replacing cached responses, e.g. to warm hot
+// reference data at startup. It returns the errors of the failed requests.
+func (ca *Cache) Prefetch(ctx context.Context, urls ...string) error {
+	errs := make([]error, len(urls))
+	wg := sync.WaitGroup{}
+
+	for i, u := range urls {
+		wg.Add(1)
+
+		go func(i int, u string) {
+			defer wg.Done()
+
+			errs[i] = ca.prefetch(ctx, u)
+		}(i, u)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// prefetch sends a GET request for u and caches the response.
+func (ca *Cache) prefetch(ctx context.Context, u string) error {
+	req, err := ca.client.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	ca.remove(requestKey(req))
+
+	_, err = ca.client.Do(ctx, req, ioutil.Discard)
+
+	return err
+}
+
+// Invalidate removes the cached responses whose URL path or one of its parent paths matches the
+// pattern (see path.Match), e.g. after known writes. The pattern /posts/* matches /posts/1 and
+// /posts/1/comments, the pattern /posts only /posts. It returns the number of removed responses.
+func (ca *Cache) Invalidate(pattern string) (int, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, err
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	n := 0
+
+	for k, e := range ca.entries {
+		if matchParents(pattern, e.url.Path) {
+			delete(ca.entries, k)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// Len returns the number of cached responses, including expired ones not yet removed.
+func (ca *Cache) Len() int {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	return len(ca.entries)
+}
+
+// get returns the cached response of key, if it has not expired.
+func (ca *Cache) get(key string, now time.Time) (*sharedResponse, bool) {
+	if ca == nil {
+		return nil, false
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	e, ok := ca.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !now.Before(e.expires) {
+		delete(ca.entries, key)
+		return nil, false
+	}
+
+	return e.response, true
+}
+
+// put caches the response of key with the URL u, if it is cacheable, and removes the expired
+// responses.
+func (ca *Cache) put(key string, u *url.URL, response *sharedResponse, now time.Time) {
+	if ca == nil || response.resp.StatusCode != http.StatusOK ||
+		strings.Contains(strings.ToLower(response.resp.Header.Get("Cache-Control")), "no-store") {
+		return
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	for k, e := range ca.entries {
+		if !now.Before(e.expires) {
+			delete(ca.entries, k)
+		}
+	}
+
+	ca.entries[key] = &cacheEntry{url: u, response: response, expires: now.Add(ca.ttl)}
+}
+
+// remove removes the cached response of key.
+func (ca *Cache) remove(key string) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	delete(ca.entries, key)
+}
+
+// matchParents reports whether p or one of its parent paths matches pattern.
+func matchParents(pattern, p string) bool {
+	for p != "" {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+
+		i := strings.LastIndexByte(p, '/')
+		if i < 0 {
+			break
+		}
+
+		p = p[:i]
+	}
+
+	return false
+}
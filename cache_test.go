@@ -0,0 +1,126 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	var hits int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+
+		switch r.URL.Path {
+		case "/error":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_ = json.NewEncoder(w).Encode(message{Text: r.URL.Path + " " + string(rune('0'+n))})
+	}))
+	defer ts.Close()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c, err := New(ts.URL, WithCache(time.Minute), WithClock(ClockFunc(func() time.Time { return now })))
+	assert.Nil(t, err)
+
+	get := func(c *Client, path string) (string, error) {
+		req, err := c.NewRequest(http.MethodGet, path, nil)
+		assert.Nil(t, err)
+
+		m := message{}
+		_, err = c.Do(context.Background(), req, &m)
+
+		return m.Text, err
+	}
+
+	t.Run("hit", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		text, err := get(c, "/posts/1")
+		assert.Nil(t, err)
+		assert.Equal(t, "/posts/1 1", text)
+
+		text, err = get(c.Clone(), "/posts/1")
+		assert.Nil(t, err)
+		assert.Equal(t, "/posts/1 1", text)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		text, err := get(c, "/posts/1")
+		assert.Nil(t, err)
+		assert.Equal(t, "/posts/1 2", text)
+	})
+
+	t.Run("not cacheable", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		for i := 0; i < 2; i++ {
+			_, err := get(c, "/error")
+			assert.Equal(t, http.StatusNotFound, statusCode(err))
+
+			_, err = get(c, "/nostore")
+			assert.Nil(t, err)
+		}
+
+		assert.Equal(t, int32(4), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		for _, p := range []string{"/posts", "/posts/2", "/posts/2/comments", "/users/1"} {
+			_, err := get(c, p)
+			assert.Nil(t, err)
+		}
+
+		assert.Equal(t, 5, c.Cache().Len()) // with /posts/1
+
+		n, err := c.Cache().Invalidate("/posts/*")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, n) // /posts/1, /posts/2 and /posts/2/comments
+
+		n, err = c.Cache().Invalidate("/posts")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, n)
+
+		_, err = c.Cache().Invalidate("[")
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, c.Cache().Len())
+	})
+
+	t.Run("prefetch", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		err := c.Cache().Prefetch(context.Background(), "/users/1", "/users/2", "/error")
+		assert.Equal(t, http.StatusNotFound, statusCode(err))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+		assert.Equal(t, 2, c.Cache().Len())
+
+		_, err = get(c, "/users/2")
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		c, err := New(ts.URL)
+		assert.Nil(t, err)
+		assert.Nil(t, c.Cache())
+
+		_, err = New(ts.URL, WithCache(0))
+		assert.NotNil(t, err)
+	})
+}
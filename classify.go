@@ -0,0 +1,80 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// IsTimeout reports whether err is a timeout: a network timeout, an exceeded deadline of the
+// context or an HTTPError with status 408 (request timeout) or 504 (gateway timeout).
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	switch statusCode(err) {
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// IsConnectionRefused reports whether err is caused by a refused connection.
+func IsConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// IsDNSError reports whether err is caused by a failed DNS lookup.
+func IsDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// IsTemporary reports whether err is likely temporary and the request can be retried later:
+// timeouts (see IsTimeout), refused or reset connections, temporary DNS errors, ErrTooManyRequest
+// and HTTPErrors with status 429 (too many requests), 502 (bad gateway) or 503 (service
+// unavailable). Errors of the generated clients are recognized if they wrap an HTTPError.
+func IsTemporary(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if IsTimeout(err) || IsConnectionRefused(err) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, ErrTooManyRequest) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+
+	switch statusCode(err) {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	}
+
+	return false
+}
+
+// statusCode returns the status code of the HTTPError in the chain of err, 0 if there is none.
+func statusCode(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+
+	return 0
+}
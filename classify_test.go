@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	c, err := New(down.URL)
+	assert.Nil(t, err)
+
+	req, err := c.NewRequest(http.MethodGet, "/", nil)
+	assert.Nil(t, err)
+
+	_, refused := c.Do(context.Background(), req, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+
+	<-ctx.Done()
+
+	_, timeout := c.Do(ctx, req, nil)
+
+	dns := &net.DNSError{Err: "no such host", Name: "hostname.invalid", IsNotFound: true}
+
+	tt := []struct {
+		name      string
+		err       error
+		timeout   bool
+		refused   bool
+		dns       bool
+		temporary bool
+	}{
+		{"nil", nil, false, false, false, false},
+		{"connection refused", refused, false, true, false, true},
+		{"deadline", timeout, true, false, false, true},
+		{"dns", fmt.Errorf("lookup: %w", dns), false, false, true, false},
+		{"temporary dns", &net.DNSError{IsTemporary: true}, false, false, true, true},
+		{"404", &HTTPError{StatusCode: http.StatusNotFound}, false, false, false, false},
+		{"504", &HTTPError{StatusCode: http.StatusGatewayTimeout}, true, false, false, true},
+		{"wrapped 503", fmt.Errorf("service: %w", &HTTPError{StatusCode: http.StatusServiceUnavailable}), false, false, false, true},
+		{"rate limiter", ErrTooManyRequest, false, false, false, true},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.timeout, IsTimeout(tc.err), "IsTimeout")
+			assert.Equal(t, tc.refused, IsConnectionRefused(tc.err), "IsConnectionRefused")
+			assert.Equal(t, tc.dns, IsDNSError(tc.err), "IsDNSError")
+			assert.Equal(t, tc.temporary, IsTemporary(tc.err), "IsTemporary")
+		})
+	}
+}
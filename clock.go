@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock is the time source of the client (see WithClock) for the expiry of cache entries, the
+// cooldown of the circuit breaker, scheduled requests, Retry-After dates and the timestamps and
+// latencies of results. Timers (e.g. the backoff of retries) use the system clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a function implementing Clock.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SkewedClock returns a Clock with the time of clock shifted by skew, e.g. to compensate the
+// known clock skew of a server sending Retry-After dates. If clock is nil, the system clock is
+// used.
+func SkewedClock(clock Clock, skew time.Duration) Clock {
+	if clock == nil {
+		clock = ClockFunc(time.Now)
+	}
+
+	return ClockFunc(func() time.Time {
+		return clock.Now().Add(skew)
+	})
+}
+
+// WithClock is a client option for setting the clock of the client, e.g. a frozen clock in
+// tests. The default is the system clock.
+func WithClock(clock Clock) Opt {
+	return func(c *Client) error {
+		if clock == nil {
+			return errors.New("clock cannot be nil")
+		}
+
+		c.clock = clock
+
+		return nil
+	}
+}
+
+// Now returns the current time of the clock of the client (see WithClock).
+func (c *Client) Now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+
+	return c.clock.Now()
+}
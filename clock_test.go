@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	frozen := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return frozen })
+
+	t.Run("system clock", func(t *testing.T) {
+		c, err := New(baseurl)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+	})
+
+	t.Run("frozen clock", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"Text":"hello"}`))
+		}))
+		defer ts.Close()
+
+		c, err := New(ts.URL, WithClock(clock))
+		assert.Nil(t, err)
+		assert.Equal(t, frozen, c.Now())
+
+		r, err := Get[message](context.Background(), c, "/")
+		assert.Nil(t, err)
+		assert.Equal(t, frozen, r.Start)
+		assert.Equal(t, time.Duration(0), r.Duration)
+	})
+
+	t.Run("skewed clock", func(t *testing.T) {
+		c, err := New(baseurl, WithClock(SkewedClock(clock, -time.Minute)))
+		assert.Nil(t, err)
+		assert.Equal(t, frozen.Add(-time.Minute), c.Now())
+
+		assert.WithinDuration(t, time.Now().Add(time.Hour), SkewedClock(nil, time.Hour).Now(), time.Second)
+	})
+
+	t.Run("nil clock", func(t *testing.T) {
+		_, err := New(baseurl, WithClock(nil))
+		assert.EqualError(t, err, "clock cannot be nil")
+	})
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/postfinance/httpclient/gen"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// defaultConfig is the configuration file used if -config is not set.
+const defaultConfig = ".httpclient-gen.yaml"
+
+// config is the content of the configuration file. Relative paths are
+// resolved against the directory of the configuration file.
+type config struct {
+	Package       string                        `yaml:"package"`
+	Paths         []string                      `yaml:"paths"`
+	Out           string                        `yaml:"out"`
+	Suffixes      []string                      `yaml:"suffixes"`
+	Match         string                        `yaml:"match"`
+	Include       []string                      `yaml:"include"`
+	Exclude       []string                      `yaml:"exclude"`
+	GoImports     string                        `yaml:"goimports"`
+	Force         bool                          `yaml:"force"`
+	Recursive     bool                          `yaml:"recursive"`
+	Timestamp     bool                          `yaml:"timestamp"`
+	Impl          bool                          `yaml:"impl"`
+	Split         bool                          `yaml:"split"`
+	Schemas       []string                      `yaml:"schemas"`
+	Models        string                        `yaml:"models"`
+	Protos        []string                      `yaml:"protos"`
+	ProtoOut      string                        `yaml:"protoOut"`
+	ProtoProtocol string                        `yaml:"protoProtocol"`
+	Postman       string                        `yaml:"postman"`
+	PostmanOut    string                        `yaml:"postmanOut"`
+	Template      string                        `yaml:"template"`
+	TemplateDir   string                        `yaml:"templateDir"`
+	CLI           string                        `yaml:"cli"`
+	Server        string                        `yaml:"server"`
+	Docs          string                        `yaml:"docs"`
+	Plugins       []string                      `yaml:"plugins"`
+	Data          map[string]string             `yaml:"data"`
+	Services      map[string]gen.ServiceOptions `yaml:"services"`
+
+	// Versions are generated one after the other, each with the settings above
+	// overridden by the settings of the version.
+	Versions []config `yaml:"versions"`
+}
+
+// readConfig reads the configuration file. A missing default configuration
+// file is not an error.
+func readConfig(file string) (*config, error) {
+	name := file
+	if name == "" {
+		name = defaultConfig
+	}
+
+	b, err := ioutil.ReadFile(name) // nolint: gosec // G304: file inclusion is intended
+	if err != nil {
+		if file == "" && os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("could not read configuration: %w", err)
+	}
+
+	c := &config{}
+
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, fmt.Errorf("could not parse configuration %s: %w", name, err)
+	}
+
+	c.resolve(filepath.Dir(name))
+
+	return c, nil
+}
+
+// resolve resolves the relative paths of c (and its versions) against dir.
+func (c *config) resolve(dir string) {
+	rel := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+
+		return filepath.Join(dir, p)
+	}
+
+	for i, p := range c.Paths {
+		// filepath.Join would remove the trailing /...
+		if strings.HasSuffix(p, "/...") {
+			c.Paths[i] = rel(strings.TrimSuffix(p, "/...")) + "/..."
+			continue
+		}
+
+		c.Paths[i] = rel(p)
+	}
+
+	c.Out = rel(c.Out)
+	c.Template = rel(c.Template)
+	c.TemplateDir = rel(c.TemplateDir)
+	c.CLI = rel(c.CLI)
+	c.Server = rel(c.Server)
+	c.Docs = rel(c.Docs)
+	c.Models = rel(c.Models)
+	c.ProtoOut = rel(c.ProtoOut)
+	c.Postman = rel(c.Postman)
+	c.PostmanOut = rel(c.PostmanOut)
+
+	for i, p := range c.Schemas {
+		c.Schemas[i] = rel(p)
+	}
+
+	for i, p := range c.Protos {
+		c.Protos[i] = rel(p)
+	}
+
+	for i := range c.Versions {
+		c.Versions[i].resolve(dir)
+	}
+}
+
+// merge returns the settings of c overridden by the settings of the version v, which
+// are not empty.
+func (c config) merge(v config) config {
+	m := c
+	m.Versions = nil
+
+	src, dst := reflect.ValueOf(v), reflect.ValueOf(&m).Elem()
+
+	for i := 0; i < src.NumField(); i++ {
+		if !src.Field(i).IsZero() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+
+	return m
+}
+
+// generateVersions generates the clients of the versions of the configuration c.
+func generateVersions(c *config) error {
+	var err error
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "package" || f.Name == "path" || f.Name == "out" {
+			err = fmt.Errorf("flag %s cannot be combined with versions", f.Name)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for i, v := range c.Versions {
+		if len(v.Versions) > 0 {
+			return fmt.Errorf("version %d: versions cannot be nested", i+1)
+		}
+
+		m := c.merge(v)
+		if m.Package == "" || m.Out == "" {
+			return fmt.Errorf("version %d: package and out are required", i+1)
+		}
+
+		restore := saveSettings()
+
+		m.apply()
+
+		err := checkFiles()
+		if err == nil {
+			err = run()
+		}
+
+		restore()
+
+		if err != nil {
+			return fmt.Errorf("version %s: %w", m.Package, err)
+		}
+	}
+
+	return nil
+}
+
+// saveSettings saves the values of all flags and the service settings and returns a function
+// restoring them.
+func saveSettings() func() {
+	values := map[string]string{}
+
+	flag.VisitAll(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+
+	services := serviceConfigs
+	saved := append(listFlag(nil), plugins...)
+
+	data := dataFlag{}
+	for k, v := range templateValues {
+		data[k] = v
+	}
+
+	return func() {
+		flag.VisitAll(func(f *flag.Flag) {
+			if f.Name != "data" && f.Name != "plugin" {
+				_ = f.Value.Set(values[f.Name])
+			}
+		})
+
+		serviceConfigs = services
+		plugins = saved
+
+		for k := range templateValues {
+			delete(templateValues, k)
+		}
+
+		for k, v := range data {
+			templateValues[k] = v
+		}
+	}
+}
+
+// apply sets the settings of c which are not overridden by a command line flag.
+// nolint: gocyclo
+func (c *config) apply() {
+	set := map[string]bool{}
+
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	str := func(name string, dst *string, v string) {
+		if !set[name] && v != "" {
+			*dst = v
+		}
+	}
+
+	list := func(name string, dst *string, v []string) {
+		str(name, dst, strings.Join(v, ","))
+	}
+
+	boolean := func(name string, dst *bool, v bool) {
+		if !set[name] && v {
+			*dst = v
+		}
+	}
+
+	str("package", &targetPackage, c.Package)
+	list("path", &sourcePath, c.Paths)
+	str("out", &outputFile, c.Out)
+	list("suffix", &svcSuffix, c.Suffixes)
+	str("match", &svcMatch, c.Match)
+	list("include", &include, c.Include)
+	list("exclude", &exclude, c.Exclude)
+	str("goimports", &goImports, c.GoImports)
+	boolean("force", &force, c.Force)
+	boolean("recursive", &recursive, c.Recursive)
+	boolean("timestamp", &timestamp, c.Timestamp)
+	boolean("impl", &genImpl, c.Impl)
+	boolean("split", &split, c.Split)
+	list("schema", &schemaFiles, c.Schemas)
+	str("models", &modelsFile, c.Models)
+	list("proto", &protoFiles, c.Protos)
+	str("proto-out", &protoOutFile, c.ProtoOut)
+	str("proto-protocol", &protoProtocol, c.ProtoProtocol)
+	str("postman", &postmanFile, c.Postman)
+	str("postman-out", &postmanOutFile, c.PostmanOut)
+	str("template", &templateFile, c.Template)
+	str("template-dir", &templateDir, c.TemplateDir)
+	str("cli", &cliFile, c.CLI)
+	str("server", &serverFile, c.Server)
+	str("docs", &docsFile, c.Docs)
+
+	if !set["plugin"] && len(c.Plugins) > 0 {
+		plugins = c.Plugins
+	}
+
+	for k, v := range c.Data {
+		if _, ok := templateValues[k]; !ok {
+			templateValues[k] = v
+		}
+	}
+
+	if c.Services != nil {
+		serviceConfigs = c.Services
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/postfinance/httpclient/gen"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `package: inventory
+paths: [./api/..., /abs]
+out: httpclient.go
+suffixes: [Service, API]
+impl: true
+services:
+  NodeService:
+    field: Nodes
+    basepath: /v2
+  LegacyService:
+    skip: true
+`
+
+func TestReadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "gen.yaml")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(testConfig), 0o600))
+
+	t.Run("resolve", func(t *testing.T) {
+		c, err := readConfig(file)
+		assert.Nil(t, err)
+		assert.Equal(t, "inventory", c.Package)
+		assert.Equal(t, []string{filepath.Join(dir, "api") + "/...", "/abs"}, c.Paths)
+		assert.Equal(t, filepath.Join(dir, "httpclient.go"), c.Out)
+		assert.Equal(t, []string{"Service", "API"}, c.Suffixes)
+		assert.True(t, c.Impl)
+		assert.Equal(t, map[string]gen.ServiceOptions{
+			"NodeService":   {Field: "Nodes", BasePath: "/v2"},
+			"LegacyService": {Skip: true},
+		}, c.Services)
+	})
+
+	t.Run("missing default", func(t *testing.T) {
+		wd, err := os.Getwd()
+		assert.Nil(t, err)
+
+		assert.Nil(t, os.Chdir(dir))
+		defer func() { assert.Nil(t, os.Chdir(wd)) }()
+
+		c, err := readConfig("")
+		assert.Nil(t, err)
+		assert.Nil(t, c)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readConfig(filepath.Join(dir, "missing.yaml"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("unknown setting", func(t *testing.T) {
+		invalid := filepath.Join(dir, "invalid.yaml")
+		assert.Nil(t, ioutil.WriteFile(invalid, []byte("packge: inventory\n"), 0o600))
+
+		_, err := readConfig(invalid)
+		assert.NotNil(t, err)
+	})
+}
+
+const testVersionService = `package %s
+
+import (
+	"context"
+	"net/http"
+)
+
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*http.Response, error)
+}
+`
+
+const testVersions = `impl: true
+versions:
+  - package: clientv1
+    paths: [./v1]
+    out: v1/httpclient.go
+  - package: clientv2
+    paths: [./v2]
+    out: v2/httpclient.go
+    services:
+      NodeService: {field: Nodes}
+`
+
+func TestGenerateVersions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "versions")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	for _, v := range []string{"v1", "v2"} {
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, v), 0o700))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, v, "api.go"), []byte(fmt.Sprintf(testVersionService, "client"+v)), 0o600))
+	}
+
+	file := filepath.Join(dir, "gen.yaml")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(testVersions), 0o600))
+
+	c, err := readConfig(file)
+	assert.Nil(t, err)
+	assert.Nil(t, generateVersions(c))
+
+	v1, err := ioutil.ReadFile(filepath.Join(dir, "v1", "httpclient.go"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(v1), "package clientv1")
+	assert.Contains(t, string(v1), "Node NodeService")
+
+	v2, err := ioutil.ReadFile(filepath.Join(dir, "v2", "httpclient.go"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(v2), "package clientv2")
+	assert.Contains(t, string(v2), "Nodes NodeService")
+
+	// the settings of the versions are restored
+	assert.Equal(t, "main", targetPackage)
+	assert.Empty(t, serviceConfigs)
+
+	t.Run("merge", func(t *testing.T) {
+		m := c.merge(c.Versions[1])
+		assert.True(t, m.Impl)
+		assert.Equal(t, "clientv2", m.Package)
+		assert.Nil(t, m.Versions)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.EqualError(t, generateVersions(&config{Versions: []config{{Package: "clientv1"}}}), "version 1: package and out are required")
+		assert.EqualError(t, generateVersions(&config{Versions: []config{{Versions: []config{{}}}}}), "version 1: versions cannot be nested")
+	})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+)
+
+// goGenerate reports whether the generator runs for a //go:generate directive and applies
+// the package context of go generate: the package name is the package of the file with
+// the directive (GOPACKAGE), unless it is set by a flag or the configuration c. The path and
+// the output file default to the directory of the package (the working directory of go
+// generate), beside the source.
+func goGenerate(c *config) bool {
+	pkg := os.Getenv("GOPACKAGE")
+	if pkg == "" {
+		return false
+	}
+
+	set := false
+
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == "package"
+	})
+
+	if !set && (c == nil || c.Package == "") {
+		targetPackage = pkg
+	}
+
+	return true
+}
+
+// isGenerated reports whether file is a generated go file (with the standard generated code
+// header) or not a go file at all, so go generate can overwrite it.
+func isGenerated(file string) bool {
+	if filepath.Ext(file) != ".go" {
+		return true
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false
+	}
+
+	return ast.IsGenerated(f)
+}
@@ -4,201 +4,873 @@
 // and generate code for <package> with matching interface types
 // and computed type names in <out> file
 //
+// The generator is also available as library (package github.com/postfinance/httpclient/gen)
+// for build tools and other generators: gen.Scan, gen.Render and gen.Write with gen.Options
+// corresponding to the flags below.
+//
 //
 // Example:
 // package		package to generate code for (default: main)
-// path			path to search for interface types (default: .)
+// path			comma separated paths to search for interface types (default: .)
+//				a trailing /... searches all subdirectories too (e.g. ./...)
+// recursive	search all subdirectories of path (default: false)
 // out			file name for the generated code (default: client.http.go)
-// suffix		suffix of the interface type name we are looking for (default: Service)
-// goimports	path to the goimports tool (default: goimports)
+// suffix		comma separated suffixes of the interface type names we are looking for (default: Service)
+// match		regular expression matching the interface type names we are looking for, the first
+//				capture group (if any) is used as service name; takes precedence over suffix
+// include		comma separated glob patterns for the file or type names of services to include,
+//				services must match at least one of them (default: all)
+// exclude		comma separated glob patterns for the file or type names of services to exclude,
+//				e.g. *_test.go,Mock* (default: none)
+// goimports	path to an external goimports tool (default: none)
+//				the generated code is formatted in-process, use this only as an escape hatch
+// timestamp	add the time of the generation to the generated code (default: false)
+//				without it, the output is reproducible
+// stdout		print the generated code instead of writing it to out (default: false)
+// diff			print a unified diff between out and the generated code instead of writing it,
+//				exits with status 1 if out is not up to date (default: false)
+// impl			generate the Impl types of services with route annotations (default: false)
+// template		template file used instead of the embedded template
+// template-dir	directory with additional templates (*.tmpl), if template is not set
+//				the directory must contain the main template client.tmpl
+// data			key=value pair passed to the templates as .Data, can be repeated
+// validate	type check the packages and report Impl types, which are not generated and
+//				do not exist or do not implement the service interface (default: true)
+// reverse		generate the service interfaces of the Impl types in path instead of the client,
+//				see below (default: false)
+// instrument	wrap the services with decorators calling the Instrumentation of the client,
+//				see below (default: false)
+// tests		write a test file with a test server and table driven test skeletons for every
+//				service with a generated Impl type, e.g. node_client_test.go next to out; existing
+//				files are only overwritten with -force (default: false)
+// cli			file name for a cobra command line client in package, see below (default: none)
+// server		file name for a fake HTTP server in package, see below (default: none)
+// docs			file name for a Markdown documentation of the services, see below (default: none)
+// schema		comma separated JSON Schema files or directories (*.json) to generate models from,
+//				see below (default: none)
+// models		file name for the generated models (default: models.go next to out)
+// proto		comma separated .proto files or directories (*.proto) with google.api.http
+//				annotations to generate services from, see below (default: none)
+// proto-out	file name for the services generated from protobuf (default: proto.go next to out)
+// proto-protocol	RPC protocol (twirp or connect) of the services generated from protobuf
+//				instead of the google.api.http annotations, see below (default: none)
+// postman		Postman collection (v2.0 or v2.1) to generate services from, see below (default: none)
+// postman-out	file name for the services generated from the Postman collection (default:
+//				postman.go next to out)
+// plugin		plugin generating additional files, name[:parameter], see below (can be repeated)
+// split		write the code of every service into a separate file next to out, see below
+//				(default: false)
+// watch		regenerate the code whenever the go files in path or the templates change,
+//				until interrupted (default: false)
+// watch-interval	interval to check the files for changes (default: 1s)
+// config		configuration file (default: .httpclient-gen.yaml if it exists)
+//
+// Configuration file
+//
+// The settings can be stored in a YAML configuration file, flags set on the
+// command line take precedence. Relative paths are resolved against the
+// directory of the configuration file:
+//
+//	package: inventory
+//	paths: [./api/...]
+//	out: httpclient.go
+//	suffixes: [Service, API]
+//	exclude: ["*_test.go", "Mock*"]
+//	impl: true
+//	services:
+//	  NodeService:       # interface type name
+//	    field: Nodes     # field name in the Client type
+//	    error: APIError  # like //httpclient:error
+//	    basepath: /v2    # like //httpclient:basepath
+//	  LegacyService:
+//	    skip: true
+//
+// Directives in the source code take precedence over the service settings.
+//
+// Versions
+//
+// Several API versions (e.g. the packages clientv1 and clientv2) can be generated side
+// by side with the versions of the configuration file. Each version is generated with the
+// settings above overridden by its own settings, package and out are required. Models
+// shared by the versions are declared in a common package imported by the service
+// interfaces of all versions:
+//
+//	impl: true
+//	versions:
+//	  - package: clientv1
+//	    paths: [./clientv1]
+//	    out: clientv1/httpclient.go
+//	  - package: clientv2
+//	    paths: [./clientv2]
+//	    out: clientv2/httpclient.go
+//	    services:
+//	      NodeService: {field: Nodes}
+//
+// The flags package, path and out cannot be combined with versions.
+//
+// Custom templates are executed with the same data as the embedded template:
+//	.Timestamp	time of the generation (zero value without -timestamp)
+//	.Path		path scanned for services
+//	.Package	package name for the generated code
+//	.Imports	import paths of the services declared in other packages
+//	.Services	list of services with .FieldName, .VarName, .TypeName and .InterfaceName,
+//				.ImportPath and .Constructor (services declared in other packages),
+//				.ImplName, .TypeParams and .TypeArgs (generic services), .Error and .ErrorType,
+//				.Instrumented, .InstrumentedName, .Decorated and .Calls (-instrument),
+//				.Policies and .PoliciesName (timeout and retry directives)
+//				and, for generated Impl types, .Generate and .Methods
+//	.Instrument	at least one service is instrumented
+//	.Decorate	at least one service is instrumented or has policies
+//	.Split		the services are written into separate files (-split)
+//	.Data		key/value pairs of -data and of data in the configuration file
+//
+// Besides the functions of text/template, the templates can use lower, upper, title,
+// kebab (newPost becomes new-post), trim, trimPrefix, trimSuffix, hasPrefix, hasSuffix,
+// contains, replace, split and join (with the string argument first, e.g.
+// {{ .Package | trimSuffix "v1" }}), quote, default, comment (prefixes the lines with //),
+// env (value of an environment variable) and include (content of a file). For example,
+// a license header and build information can be added to a custom template with:
+//
+//	{{ include "LICENSE.txt" | comment }}
+//	// Version {{ .Data.version | default "dev" }}
 //
 // For a interface type named NodeService the following names will be computed:
 //	- NodeImpl	type implementing NodeService
-//				NodeImpl must exist
+//				NodeImpl must exist and implement NodeService (unless generated with -impl)
 //  - Node		field name in Client type
 //	- node		for initialization purpose only
+//
+// Services declared in other directories than out (e.g. with -recursive) are imported, their
+// Impl types are not generated but created with a constructor of their package, e.g.
+// posts.NewPostImpl(client) for posts.PostService. The field names of all services must be
+// unique.
+//
+// Plugins (-plugin)
+//
+// Plugins generate additional files (e.g. mocks, Terraform definitions) from the services
+// without changes to the generator. Like the plugins of protoc, a plugin is a program named
+// httpclient-gen-<name> (in PATH) or given by its path, -plugin mock:pkg=mocks runs
+// httpclient-gen-mock with the parameter pkg=mocks. The plugin reads a JSON request from stdin
+// and writes a JSON response to stdout (see gen.PluginRequest and gen.PluginResponse):
+//	{"version": 1, "parameter": "pkg=mocks", "package": "api", "out": "api/httpclient.go",
+//	 "services": [{"name": "Node", "interface": "NodeService", "impl": "NodeImpl",
+//	   "generated": true, "methods": [{"name": "Get",
+//	   "signature": "Get(ctx context.Context, id string) (*Node, *http.Response, error)",
+//	   "directives": {"route": "GET /nodes/{id}"}}]}]}
+// and responds with the generated files (relative to the directory of out) or an error:
+//	{"files": [{"name": "mocks/node.go", "content": "..."}], "error": ""}
+// Go files are formatted, errors can also be reported with a non-zero exit code and a
+// message on stderr. Programs embedding the generator implement gen.Plugin instead.
+//
+// go generate
+//
+// The generator can run without flags for a //go:generate directive in the package with the
+// service interfaces:
+//	//go:generate httpclient-gen-go -impl
+// The package name is inferred from the package of the directive (unless set with -package or
+// in the configuration file) and the path and the output file default to the package
+// directory. Generated files (with the standard "Code generated ... DO NOT EDIT." header)
+// are overwritten without -force, other existing go files are not.
+//
+// Models from JSON Schema (-schema)
+//
+// With -schema, the models are generated from JSON Schema files into the file models
+// (written before the client is generated, so the services can use them). The root schema
+// of a file (named after its title or the file name) and its definitions ($defs) become
+// types:
+//	- objects become structs with json tags, optional fields (not required) are omitted
+//	  if empty and structs, times and nullable values are pointers
+//	- string and integer enums become types with a constant for every value
+//	- allOf is merged, anyOf and oneOf become json.RawMessage (except for nullable types)
+//	- $ref references definitions in the same or in another file (e.g. common.json#/$defs/Address)
+//
+// Every struct and enum gets a Validate method, which checks the required fields and enum
+// values, minLength, maxLength, pattern, minimum, maximum, minItems and maxItems.
+//
+// Services from protobuf (-proto)
+//
+// With -proto, the services of gRPC-gateway APIs are generated from .proto files into the
+// file proto-out (written before the client is generated, like the models). Every service
+// with rpcs annotated with option (google.api.http) becomes a service interface (with the
+// first suffix appended, e.g. LibraryService) with route directives, so -impl generates
+// its Impl type, and every message and enum becomes a type:
+//	- fields get the JSON names of protobuf (lowerCamel or json_name), 64-bit integers
+//	  are encoded as strings and the well-known types are mapped (e.g. Timestamp to
+//	  *time.Time, wrappers to pointers, Empty to no result)
+//	- the path variables become parameters of the methods, a variable with a pattern,
+//	  e.g. {name=shelves/*}, becomes shelves/{name} (the parameter is the last segment)
+//	- body: "*" passes the request message as body, body: "<field>" the field, without
+//	  body the remaining fields of the request message are sent as query parameters
+//
+// Streaming rpcs, custom methods and additional_bindings are not supported (the latter
+// are ignored). For example:
+//	service Library {
+//		rpc GetBook(GetBookRequest) returns (Book) {
+//			option (google.api.http) = { get: "/v1/shelves/{shelf_id}/books/{book_id}" };
+//		}
+//	}
+// generates:
+//	type LibraryService interface {
+//		//httpclient:route GET /v1/shelves/{shelfID}/books/{bookID}
+//		GetBook(ctx context.Context, shelfID int64, bookID int64) (*Book, *http.Response, error)
+//	}
+//
+// With -proto-protocol twirp or connect, the services speak the JSON variant of the Twirp
+// or Connect protocol instead: every rpc (the google.api.http annotations are ignored) posts
+// its request message to /<package>.<service>/<method> (with the base path /twirp for
+// Twirp) and the error responses are decoded into the generated TwirpStatus or ConnectStatus
+// (like //httpclient:error). The clients use the settings of the httpclient.Client (e.g.
+// authentication, retries and instrumentation), Connect servers requiring the protocol header
+// get it with httpclient.WithHeader (Connect-Protocol-Version: 1).
+//
+// Services from Postman collections (-postman)
+//
+// With -postman, the services of an API documented only by a Postman collection are generated
+// into the file postman-out (written before the client is generated, like the models). Every
+// top-level folder becomes a service interface (the requests at the top level a service named
+// after the collection) with the first suffix appended, and every request (also in nested
+// folders) a method with a route directive, named after the request:
+//	- path variables (:id or {{id}}) become string parameters, the host (e.g. {{baseUrl}})
+//	  is ignored
+//	- the enabled query parameters become a struct <Method>Query with url tags
+//	- a raw JSON body becomes a struct <Method>Request inferred from the example
+//	- the first successful example response with a JSON body becomes the result type
+//	  <Method>Response
+//
+// The structs are inferred from single examples (e.g. numbers without fraction become int,
+// null becomes interface{}), so review the generated code and move it into a regular source
+// file if it needs changes. Other body modes (form data, files) are not supported.
+//
+// Multiple files (-split)
+//
+// With -split, out only contains the Client type, NewClient and the options. The code of
+// every service (error type, Impl type, iterators and decorators) is written into a
+// separate file next to out, named after the field name of the service and out, e.g.
+// node_httpclient.go. Custom templates need a template "service file" for this (see the
+// embedded template). The generator does not generate models, to keep them apart from
+// the client, declare them in a separate package (e.g. types) imported by the service
+// interfaces.
+//
+// Service options
+//
+// For every service a client option (e.g. WithNodeOptions) is generated, which sets options
+// only for this service. The service uses a copy of the client with these options, e.g.
+// with another base path, content type or headers:
+//
+//	c, err := NewClient(baseURL, WithNodeOptions(
+//		httpclient.WithBasePath("/v2"),
+//		httpclient.WithHeader(http.Header{"X-Tenant": {"inventory"}}),
+//	))
+//
+// Services can even use another host with httpclient.WithBaseURL, they share the transport and
+// the authentication with the other services:
+//
+//	c, err := NewClient("https://api.example.com/", WithSearchOptions(
+//		httpclient.WithBaseURL("https://search.example.com/"),
+//	))
+//
+// Generic services
+//
+// Generic service interfaces need at least one instantiation directive with the type
+// arguments and, if there are several instantiations, the field name in the Client type:
+//
+//	//httpclient:instantiate Post Posts
+//	//httpclient:instantiate Comment Comments
+//	type CRUDService[T any] interface {
+//		...
+//	}
+//
+// The (generic) CRUDImpl type must exist or is generated with -impl.
+//
+// Reverse generation (-reverse)
+//
+// If the Impl types were written first, -reverse generates the service interfaces for
+// the exported methods of all Impl types in path without an interface (e.g. NodeService
+// for NodeImpl, with the first suffix). The output is meant to be edited, so choose a
+// separate file:
+//
+//	httpclient-gen-go -reverse -path ./inventory -out ./inventory/services.go
+//
+// Timeouts and retries
+//
+// Methods annotated with //httpclient:timeout <duration> (e.g. 5s) are called with a
+// context with this timeout. Methods annotated with //httpclient:retry idempotent are
+// retried with httpclient.Client.Retry according to the RetryPolicy of the client (see
+// httpclient.WithRetryPolicy), the timeout includes the retries. The policies are applied
+// by a decorator (e.g. NodePolicies), which NewClient wraps around the service:
+//
+//	type NodeService interface {
+//		//httpclient:timeout 5s
+//		//httpclient:retry idempotent
+//		Get(ctx context.Context, id int) (*Node, *http.Response, error)
+//	}
+//
+// Instrumentation (-instrument)
+//
+// With -instrument, a decorator type (e.g. NodeInstrumented) implementing the service
+// interface is generated for every service declared in package. If the client is created
+// with the httpclient.WithInstrumentation option, NewClient wraps the services with the
+// decorators, which call the Instrumentation for every method call, e.g. to start a span
+// and to record the number and the duration of the calls per method:
+//
+//	c, err := NewClient(baseURL, httpclient.WithInstrumentation(httpclient.InstrumentationFunc(
+//		func(ctx context.Context, service, method string) (context.Context, func(*http.Response, error)) {
+//			ctx, span := tracer.Start(ctx, service+"."+method)
+//			return ctx, func(_ *http.Response, err error) {
+//				...
+//				span.End()
+//			}
+//		})))
+//
+// Fake server (-server)
+//
+// With -server, a FakeServer type (http.Handler) is generated, which serves the routes of
+// the services with generated Impl types with configurable fixtures. It can be used by
+// tests with httptest.NewServer or as runnable fake of the API before it exists, e.g.
+// with fixtures loaded from JSON files like testdata/Node.Get.json:
+//
+//	{"status": 200, "header": {"X-Total": ["1"]}, "body": {"id": 1, "name": "test"}}
+//
+// API documentation (-docs)
+//
+// With -docs, a Markdown file is generated, which documents every service with its doc
+// comment, base path and error type, the methods with signature, route and query options
+// and the struct types of the package used by the methods (with their JSON names). The
+// documentation is regenerated with the code, so it stays in sync with the client.
+//
+// Command line client (-cli)
+//
+// With -cli, a function NewCommand(opts ...httpclient.Opt) *cobra.Command is generated,
+// which returns a command line client with a subcommand for every service (e.g. node)
+// and method (e.g. node get). The method parameters are flags (e.g. --id), values of
+// structured types are passed as JSON (e.g. --new-post '{"title": "test"}'), the results
+// are printed as JSON. The base URL is set with --base-url. Generic services are skipped.
+// The command can be used in a small main package:
+//
+//	func main() {
+//		if err := inventory.NewCommand().Execute(); err != nil {
+//			os.Exit(1)
+//		}
+//	}
+//
+// Error types
+//
+// A service interface annotated with //httpclient:error <schema> gets an error type
+// (e.g. NodeError) embedding the struct <schema> of the error responses of the API.
+// The service uses a copy of the client with a ResponseCallback decoding error
+// responses into the error type:
+//
+//	//httpclient:error APIError
+//	type NodeService interface {
+//		...
+//	}
+//
+// Changes to the ResponseCallback of the generated Client after NewClient do not
+// affect such services.
+//
+// Impl generation (-impl)
+//
+// With -impl, the NodeImpl type is generated for every service whose methods are
+// annotated with a route directive:
+//
+//	type NodeService interface {
+//		//httpclient:route GET /nodes/{id}
+//		Get(ctx context.Context, id int) (*Node, *http.Response, error)
+//		//httpclient:route GET /nodes
+//		//httpclient:query ListOptions
+//		List(ctx context.Context, opts *ListOptions) ([]Node, *http.Response, error)
+//		//httpclient:route POST /nodes
+//		Create(ctx context.Context, n *Node) (*Node, *http.Response, error)
+//		//httpclient:route DELETE /nodes/{id}
+//		Delete(ctx context.Context, id int) (*http.Response, error)
+//	}
+//
+// The methods must have a context.Context as first parameter and return either
+// (T, *http.Response, error) or (*http.Response, error). Placeholders in the path are
+// replaced by the (escaped) parameter with the same name or, if the parameters are
+// unnamed, by the parameters in order. The parameter with the type of the query
+// directive is added with httpclient.QueryOptions, a remaining parameter is used as
+// request body. Methods of embedded interfaces declared in the same package are
+// part of the generated Impl type, methods embedded more than once are generated once.
+//
+// The routes of a service interface annotated with //httpclient:basepath <prefix> are
+// resolved under <prefix> (e.g. /v2/inventory), all services share the same client
+// and base URL.
+//
+// GET methods annotated with //httpclient:paginate get an iterator type (e.g.
+// NodeListIterator) with a constructor on the generated Client (NewNodeListIterator).
+// The iterator fetches the pages on demand and supports range-over-func loops (Go 1.23):
+//
+//	//httpclient:paginate link
+//		the method returns a slice, the next page is in the Link header (rel="next")
+//	//httpclient:paginate token <field> <param> <items>
+//		the method returns a page struct with the token for the next page in <field>,
+//		which is sent as query parameter <param>, and the items in the slice <items>
 
 package main
 
 import (
 	"flag"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
 	"log"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
-	"text/template"
 	"time"
 
-	"github.com/pkg/errors"
+	"github.com/postfinance/httpclient/gen"
 )
 
-const codeTemplate = `
-// Code generated by client-gen-go; DO NOT EDIT.
-// This file was generated by robots at
-// {{ .Timestamp }}
+// nolint: gochecknoglobals
+var (
+	targetPackage  string
+	sourcePath     string
+	outputFile     string
+	svcSuffix      string
+	svcMatch       string
+	include        string
+	exclude        string
+	goImports      string
+	force          bool
+	recursive      bool
+	timestamp      bool
+	toStdout       bool
+	showDiff       bool
+	genImpl        bool
+	templateFile   string
+	templateDir    string
+	configFile     string
+	validateImpl   bool
+	watchMode      bool
+	reverseMode    bool
+	cliFile        string
+	serverFile     string
+	docsFile       string
+	instrument     bool
+	scaffold       bool
+	split          bool
+	schemaFiles    string
+	modelsFile     string
+	protoFiles     string
+	protoOutFile   string
+	protoProtocol  string
+	postmanFile    string
+	postmanOutFile string
+	watchInterval  time.Duration
+
+	// generating is set if the generator runs for a //go:generate directive
+	generating bool
+
+	// plugins are the plugins (-plugin name[:parameter])
+	plugins listFlag
+
+	// templateValues are the key/value pairs passed to the templates (-data)
+	templateValues = dataFlag{}
+
+	// serviceConfigs are the per-service settings of the configuration file
+	serviceConfigs = map[string]gen.ServiceOptions{}
+)
 
-package {{.Package}}
+// nolint: gochecknoinits
+func init() {
+	flag.StringVar(&targetPackage, "package", "main", "package name for the generated code")
+	flag.StringVar(&sourcePath, "path", ".", "path to scan for services (a trailing /... scans subdirectories too)")
+	flag.StringVar(&outputFile, "out", "httpclient.go", "output filename")
+	flag.StringVar(&svcSuffix, "suffix", "Service", "comma separated list of service suffixes")
+	flag.StringVar(&svcMatch, "match", "", "regular expression for service interface names (first capture group is the service name)")
+	flag.StringVar(&include, "include", "", "comma separated glob patterns for file or type names of services to include")
+	flag.StringVar(&exclude, "exclude", "", "comma separated glob patterns for file or type names of services to exclude")
+	flag.StringVar(&goImports, "goimports", "", "path to an external goimports tool (default: format in-process)")
+	flag.BoolVar(&force, "force", false, "write file even it already exists")
+	flag.BoolVar(&recursive, "recursive", false, "scan all subdirectories of path for services")
+	flag.BoolVar(&timestamp, "timestamp", false, "add the generation time to the generated code")
+	flag.BoolVar(&toStdout, "stdout", false, "print the generated code instead of writing it")
+	flag.BoolVar(&showDiff, "diff", false, "print a diff against the existing file and exit with 1 on changes")
+	flag.BoolVar(&genImpl, "impl", false, "generate the Impl types of services with route annotations")
+	flag.StringVar(&templateFile, "template", "", "template file used instead of the embedded template")
+	flag.StringVar(&templateDir, "template-dir", "", "directory with additional templates (*.tmpl)")
+	flag.Var(templateValues, "data", "key=value pair passed to the templates as .Data (can be repeated)")
+	flag.BoolVar(&validateImpl, "validate", true, "type check the packages and validate the Impl types")
+	flag.BoolVar(&reverseMode, "reverse", false, "generate the service interfaces of the Impl types in path")
+	flag.StringVar(&cliFile, "cli", "", "file name for a generated cobra command line client (default: none)")
+	flag.StringVar(&schemaFiles, "schema", "", "comma separated JSON Schema files (or directories) to generate models from (default: none)")
+	flag.StringVar(&modelsFile, "models", "", "file name for the models generated from JSON Schema (default: models.go next to out)")
+	flag.StringVar(&protoFiles, "proto", "", "comma separated .proto files (or directories) with google.api.http annotations to generate services from (default: none)")
+	flag.StringVar(&protoOutFile, "proto-out", "", "file name for the services generated from protobuf (default: proto.go next to out)")
+	flag.StringVar(&protoProtocol, "proto-protocol", "", "RPC protocol (twirp or connect) of the services generated from protobuf (default: google.api.http annotations)")
+	flag.StringVar(&postmanFile, "postman", "", "Postman collection file to generate services from (default: none)")
+	flag.StringVar(&postmanOutFile, "postman-out", "", "file name for the services generated from the Postman collection (default: postman.go next to out)")
+	flag.BoolVar(&split, "split", false, "write the code of every service into a separate file (e.g. node_httpclient.go)")
+	flag.BoolVar(&instrument, "instrument", false, "wrap the services with decorators calling the client Instrumentation")
+	flag.BoolVar(&scaffold, "tests", false, "write test scaffolding for the services with generated Impl types")
+	flag.StringVar(&serverFile, "server", "", "file name for a generated fake HTTP server in package (default: none)")
+	flag.StringVar(&docsFile, "docs", "", "file name for a generated Markdown documentation of the services (default: none)")
+	flag.Var(&plugins, "plugin", "plugin (httpclient-gen-<name> or a path) generating additional files, name[:parameter] (can be repeated)")
+	flag.BoolVar(&watchMode, "watch", false, "regenerate the code whenever the source files change")
+	flag.DurationVar(&watchInterval, "watch-interval", time.Second, "interval to check the source files for changes")
+	flag.StringVar(&configFile, "config", "", "configuration file (default: "+defaultConfig+" if it exists)")
+}
 
-import (
-	"net/http"
-	"net/url"
+// nolint: gocyclo
+func main() {
+	flag.Parse()
 
-)
+	cfg, err := readConfig(configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if cfg != nil {
+		cfg.apply()
+	}
+
+	generating = goGenerate(cfg)
+
+	if cfg != nil && len(cfg.Versions) > 0 {
+		if watchMode || reverseMode {
+			log.Fatal("versions cannot be combined with watch or reverse")
+		}
+
+		if err := generateVersions(cfg); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if err := checkFiles(); err != nil {
+		log.Fatal(err)
+	}
+
+	if watchMode {
+		if toStdout || showDiff || reverseMode {
+			log.Fatal("watch cannot be combined with stdout, diff or reverse")
+		}
+
+		if err := watch(watchInterval); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if reverseMode {
+		f, err := gen.Interfaces(options())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := output(f); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// checkFiles returns an error if an output file already exists (unless overwritten). With
+// go generate, generated files are overwritten.
+func checkFiles() error {
+	models, protos, postman := "", "", ""
+	if schemaFiles != "" {
+		models = modelsOutput()
+	}
 
-// Client is a generated wrapper for a http client and detected services.
-type Client struct {
-	*httpclient.Client
+	if protoFiles != "" {
+		protos = protoOutput()
+	}
+
+	if postmanFile != "" {
+		postman = postmanOutput()
+	}
 
-	// Services used for communicating with the API
-{{- range .Services }}
-	{{ printf "%s %s" .FieldName .InterfaceName }}
-{{- end }}
+	for _, file := range []string{outputFile, cliFile, serverFile, docsFile, models, protos, postman} {
+		if _, err := os.Stat(file); err == nil && file != "" && !force && !toStdout && !showDiff && !watchMode && !(generating && isGenerated(file)) {
+			return fmt.Errorf("%s already exists - remove or choose a different file name", file)
+		}
+	}
+
+	return nil
 }
 
-// NewClient returns a new API client.
-func NewClient(baseURL string, opts ...httpclient.Opt) (*Client, error) {
+// modelsOutput returns the file name of the models generated from JSON Schema.
+func modelsOutput() string {
+	if modelsFile == "" {
+		return filepath.Join(filepath.Dir(outputFile), "models.go")
+	}
 
-	client, err := httpclient.New(baseURL, opts...)
-	if err != nil {
-		return nil, err
+	return modelsFile
+}
+
+// protoOutput returns the file name of the code generated from protobuf.
+func protoOutput() string {
+	if protoOutFile == "" {
+		return filepath.Join(filepath.Dir(outputFile), "proto.go")
 	}
 
-	// services
-{{- range .Services }}
-{{ printf "%s := &%s{client: client}" .VarName .TypeName }}
-{{- end }}
+	return protoOutFile
+}
+
+// postmanOutput returns the file name of the code generated from the Postman collection.
+func postmanOutput() string {
+	if postmanOutFile == "" {
+		return filepath.Join(filepath.Dir(outputFile), "postman.go")
+	}
 
-	return &Client{
-		client,
-{{- range .Services }}
-{{ printf "%s," .VarName }}
-{{- end }}
-	}, nil
+	return postmanOutFile
 }
-`
 
-// service contains all names for the code generation
-type service struct {
-	FieldName     string
-	VarName       string
-	TypeName      string
-	InterfaceName string
+// options returns the generator options of the flags.
+func options() gen.Options {
+	return gen.Options{
+		Package:       targetPackage,
+		Paths:         splitList(sourcePath),
+		Recursive:     recursive,
+		Out:           outputFile,
+		Suffixes:      splitList(svcSuffix),
+		Match:         svcMatch,
+		Include:       splitList(include),
+		Exclude:       splitList(exclude),
+		Services:      serviceConfigs,
+		Impl:          genImpl,
+		Instrument:    instrument,
+		Validate:      validateImpl,
+		Timestamp:     timestamp,
+		Split:         split,
+		CLI:           cliFile != "",
+		Docs:          docsFile != "",
+		Plugins:       len(plugins) > 0,
+		Template:      templateFile,
+		TemplateDir:   templateDir,
+		Data:          templateValues,
+		GoImports:     goImports,
+		ProtoProtocol: protoProtocol,
+	}
 }
 
-// nolint: gochecknoglobals
-var (
-	targetPackage string
-	sourcePath    string
-	outputFile    string
-	svcSuffix     string
-	goImports     string
-	force         bool
-)
+// splitList returns the elements of the comma separated list s.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
 
-// nolint: gochecknoinits
-func init() {
-	flag.StringVar(&targetPackage, "package", "main", "package name for the generated code")
-	flag.StringVar(&sourcePath, "path", ".", "path to scan for services")
-	flag.StringVar(&outputFile, "out", "httpclient.go", "output filename")
-	flag.StringVar(&svcSuffix, "suffix", "Service", "service suffix")
-	flag.StringVar(&goImports, "goimports", "goimports", "path to goimports tool")
-	flag.BoolVar(&force, "force", false, "write file even it already exists")
+	return strings.Split(s, ",")
 }
 
-// nolint: gocognit, gocyclo
-func main() {
-	flag.Parse()
+// run generates the client and the additional files (tests, server, cli and docs).
+// nolint: gocyclo
+func run() error {
+	opts := options()
+
+	if schemaFiles != "" {
+		f, err := gen.Models(splitList(schemaFiles), modelsOutput(), opts)
+		if err != nil {
+			return err
+		}
+
+		if err := output(f); err != nil {
+			return err
+		}
+	}
+
+	if protoFiles != "" {
+		f, err := gen.Proto(splitList(protoFiles), protoOutput(), opts)
+		if err != nil {
+			return err
+		}
 
-	// check if file exists
-	if _, err := os.Stat(outputFile); err == nil && !force {
-		log.Fatalf("%s already exists - remove or choose a different file name\n", outputFile)
+		if err := output(f); err != nil {
+			return err
+		}
 	}
 
-	// get all services
-	fset := token.NewFileSet()
+	if postmanFile != "" {
+		f, err := gen.Postman(postmanFile, postmanOutput(), opts)
+		if err != nil {
+			return err
+		}
 
-	pkgs, err := parser.ParseDir(fset, sourcePath, nil, parser.AllErrors)
+		if err := output(f); err != nil {
+			return err
+		}
+	}
+
+	data, err := gen.Scan(opts)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	services := []service{}
-
-	for _, p := range pkgs {
-		for _, f := range p.Files {
-			for _, d := range f.Decls {
-				if t, ok := d.(*ast.GenDecl); ok {
-					if t.Tok != token.TYPE {
-						continue
-					}
-
-					for _, s := range t.Specs {
-						if ts, ok := s.(*ast.TypeSpec); ok {
-							if _, ok := ts.Type.(*ast.InterfaceType); ok {
-								if !strings.HasSuffix(ts.Name.String(), svcSuffix) {
-									continue
-								}
-
-								name := strings.TrimSuffix(ts.Name.String(), svcSuffix)
-								typeName := fmt.Sprintf("%s.%sImpl", p.Name, name)              // {name}Impl
-								interfaceName := fmt.Sprintf("%s.%s", p.Name, ts.Name.String()) // {name}Service
-
-								if p.Name == targetPackage {
-									typeName = fmt.Sprintf("%sImpl", name)
-									interfaceName = ts.Name.String()
-								}
-
-								services = append(services, service{
-									FieldName:     name,
-									VarName:       strings.ToLower(name),
-									TypeName:      typeName,
-									InterfaceName: interfaceName,
-								})
-							}
-						}
-					}
-				}
+	files, err := gen.Render(data)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := output(f); err != nil {
+			return err
+		}
+	}
+
+	if toStdout || showDiff {
+		return nil
+	}
+
+	if scaffold {
+		tests, err := gen.Tests(data)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range tests {
+			if _, err := os.Stat(f.Name); err == nil && !force {
+				fmt.Printf("%s skipped (already exists)\n", f.Name)
+				continue
+			}
+
+			if err := write(f); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, o := range []struct {
+		file     string
+		generate func(*gen.Data, string) (gen.File, error)
+	}{
+		{serverFile, gen.Server},
+		{cliFile, gen.CLI},
+		{docsFile, gen.Docs},
+	} {
+		if o.file == "" {
+			continue
+		}
+
+		f, err := o.generate(data, o.file)
+		if err != nil {
+			return err
+		}
+
+		if err := write(f); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range plugins {
+		name, parameter, _ := strings.Cut(p, ":")
+
+		files, err := gen.RunPlugin(data, gen.ExecPlugin(pluginCommand(name)), parameter)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			if err := write(f); err != nil {
+				return err
 			}
 		}
 	}
 
-	// sort :-)
-	sort.Slice(services, func(i, j int) bool {
-		return services[i].InterfaceName < services[j].InterfaceName
-	})
+	return nil
+}
+
+// pluginCommand returns the program of the plugin name: name itself if it is a path,
+// httpclient-gen-<name> (in PATH) otherwise.
+func pluginCommand(name string) string {
+	if strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/') {
+		return name
+	}
+
+	return "httpclient-gen-" + name
+}
+
+// output prints, diffs (and exits with 1 on changes) or writes the generated file f
+// depending on the flags.
+func output(f gen.File) error {
+	switch {
+	case toStdout:
+		_, err := os.Stdout.Write(f.Content)
+		return err
+	case showDiff:
+		changed, err := gen.Diff(os.Stdout, f)
+		if err != nil {
+			return err
+		}
 
-	// render template
-	t := template.Must(template.New("Client Type Template").Parse(codeTemplate))
+		if changed {
+			os.Exit(1)
+		}
 
-	f, err := os.Create(outputFile)
+		return nil
+	default:
+		return write(f)
+	}
+}
+
+// generate returns the formatted generated files for all services.
+func generate() ([]gen.File, error) {
+	data, err := gen.Scan(options())
 	if err != nil {
-		log.Fatal(errors.Wrapf(err, "could not create output file %s", outputFile))
+		return nil, err
 	}
 
-	_ = t.Execute(f, struct {
-		Timestamp time.Time
-		Path      string
-		Package   string
-		Services  []service
-	}{
-		Timestamp: time.Now(),
-		Path:      sourcePath,
-		Package:   targetPackage,
-		Services:  services,
-	})
+	return gen.Render(data)
+}
+
+// write writes the generated file f.
+func write(f gen.File) error {
+	if err := gen.Write([]gen.File{f}); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s generated\n", f.Name)
+
+	return nil
+}
 
-	_ = f.Close()
+// dataFlag is the flag for the key=value pairs passed to the templates as .Data.
+type dataFlag map[string]string
 
-	// format code and fix imports
-	// nolint: gosec // G204: Subprocess launched with variable
-	if out, err := exec.Command(goImports, "-w", "-l", outputFile).CombinedOutput(); err != nil {
-		log.Fatal(errors.Wrap(err, string(out)))
+// String implements flag.Value.
+func (d dataFlag) String() string {
+	pairs := []string{}
+
+	for k, v := range d {
+		pairs = append(pairs, k+"="+v)
 	}
 
-	fmt.Printf("%s generated\n", outputFile)
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// Set implements flag.Value.
+func (d dataFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("invalid data %q (key=value)", s)
+	}
+
+	d[k] = v
+
+	return nil
+}
+
+// listFlag is a repeatable flag.
+type listFlag []string
+
+// String implements flag.Value.
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set implements flag.Value.
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+
+	return nil
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/postfinance/httpclient/gen"
+)
+
+// watch generates the code and regenerates it whenever the source files or
+// the templates change. Errors while generating are logged, watch only
+// returns if the files cannot be checked.
+func watch(interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("watch interval must be positive")
+	}
+
+	prev := map[string][]byte{}
+	last := ""
+
+	for ; ; time.Sleep(interval) {
+		fp, err := fingerprint()
+		if err != nil {
+			return err
+		}
+
+		if fp == last {
+			continue
+		}
+
+		last = fp
+
+		files, err := generate()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		for _, f := range files {
+			if _, ok := prev[f.Name]; !ok {
+				prev[f.Name], _ = ioutil.ReadFile(f.Name) // nolint: gosec // G304: file inclusion is intended
+			}
+
+			if bytes.Equal(f.Content, prev[f.Name]) {
+				continue
+			}
+
+			if err := write(f); err != nil {
+				log.Println(err)
+				continue
+			}
+
+			prev[f.Name] = f.Content
+		}
+	}
+}
+
+// fingerprint returns the names, sizes and modification times of all go files
+// in the source directories (except the generated files) and of the templates.
+func fingerprint() (string, error) {
+	dirs, err := gen.Dirs(options())
+	if err != nil {
+		return "", err
+	}
+
+	files := []string{}
+
+	for _, dir := range dirs {
+		f, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			return "", err
+		}
+
+		files = append(files, f...)
+	}
+
+	if templateFile != "" {
+		files = append(files, templateFile)
+	}
+
+	if templateDir != "" {
+		f, err := filepath.Glob(filepath.Join(templateDir, "*.tmpl"))
+		if err != nil {
+			return "", err
+		}
+
+		files = append(files, f...)
+	}
+
+	out, _ := filepath.Abs(outputFile)
+	b := strings.Builder{}
+
+	for _, f := range files {
+		abs, _ := filepath.Abs(f)
+		if abs == out || split && filepath.Dir(abs) == filepath.Dir(out) && strings.HasSuffix(abs, "_"+filepath.Base(out)) {
+			continue
+		}
+
+		fi, err := os.Stat(f)
+		if err != nil {
+			// removed in the meantime
+			continue
+		}
+
+		fmt.Fprintf(&b, "%s %d %d\n", f, fi.Size(), fi.ModTime().UnixNano())
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	defer saveSettings()()
+
+	sourcePath = dir
+	outputFile = filepath.Join(dir, "httpclient.go")
+	templateFile = filepath.Join(dir, "client.tmpl")
+	split = true
+
+	write := func(name, content string) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	write("api.go", "package api\n")
+	write("client.tmpl", "package {{ .Package }}\n")
+
+	fp, err := fingerprint()
+	assert.Nil(t, err)
+
+	t.Run("generated files", func(t *testing.T) {
+		write("httpclient.go", "package api\n\n// generated\n")
+		write("node_httpclient.go", "package api\n\n// generated\n")
+
+		f, err := fingerprint()
+		assert.Nil(t, err)
+		assert.Equal(t, fp, f)
+	})
+
+	t.Run("source", func(t *testing.T) {
+		write("api.go", "package api\n\ntype NodeService interface{}\n")
+
+		f, err := fingerprint()
+		assert.Nil(t, err)
+		assert.NotEqual(t, fp, f)
+
+		fp = f
+	})
+
+	t.Run("template", func(t *testing.T) {
+		write("client.tmpl", "package {{ .Package }}\n\n// changed\n")
+
+		f, err := fingerprint()
+		assert.Nil(t, err)
+		assert.NotEqual(t, fp, f)
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		assert.EqualError(t, watch(0), "watch interval must be positive")
+	})
+}
@@ -0,0 +1,296 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Config is the configuration of a client for NewFromConfig, e.g. read from the YAML or JSON
+// configuration file of an application:
+//
+//	baseURL: https://api.example.com/v1/
+//	auth:
+//	  username: user
+//	  password: secret
+//	timeout: 10s
+//	retry:
+//	  maxAttempts: 5
+//	  backoff: 200ms
+//	tls:
+//	  caFile: /etc/ssl/api-ca.pem
+//	headers:
+//	  X-Client: inventory
+//	rewrites:
+//	  - from: https://api.example.com/v1
+//	    to: https://gateway.example.com/inventory/v1
+type Config struct {
+	// BaseURL is the base URL of the API (required).
+	BaseURL string `json:"baseURL" yaml:"baseURL"`
+
+	// ContentType is the content type of the requests (default application/json).
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+
+	// Auth are the credentials for basic authentication.
+	Auth AuthConfig `json:"auth,omitempty" yaml:"auth,omitempty"`
+
+	// Timeout is the timeout of the requests (default 30s).
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Proxy is the proxy URL (default: HTTP_PROXY, HTTPS_PROXY and NO_PROXY).
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+
+	// TLS are the TLS settings.
+	TLS TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+
+	// Retry is the RetryPolicy used by Retry (default DefaultRetryPolicy) and, if Auto is set,
+	// by Do (see WithRetry).
+	Retry *RetryConfig `json:"retry,omitempty" yaml:"retry,omitempty"`
+
+	// Headers are sent with each request (see WithHeader).
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// Rewrites are the rewrite rules of the request URLs (see WithRewriteRules).
+	Rewrites []RewriteRule `json:"rewrites,omitempty" yaml:"rewrites,omitempty"`
+}
+
+// AuthConfig are the credentials of a Config, the username and password for basic
+// authentication or a bearer token (see WithBearerToken).
+type AuthConfig struct {
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	Token    string `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+// TLSConfig are the TLS settings of a Config.
+type TLSConfig struct {
+	// CAFile is a PEM file with the CA certificates (default: the system pool).
+	CAFile string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+
+	// CertFile and KeyFile are PEM files with the client certificate and key.
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the server certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+}
+
+// RetryConfig is the RetryPolicy of a Config.
+type RetryConfig struct {
+	MaxAttempts int      `json:"maxAttempts" yaml:"maxAttempts"`
+	Backoff     Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	MaxBackoff  Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+	Jitter      float64  `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	StatusCodes []int    `json:"statusCodes,omitempty" yaml:"statusCodes,omitempty"`
+
+	// Auto retries the failed idempotent requests of Do (see WithRetry).
+	Auto bool `json:"auto,omitempty" yaml:"auto,omitempty"`
+}
+
+// Duration is a time.Duration represented as string (e.g. 10s) in configuration files.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// Validate returns all errors of the configuration, nil if it is valid.
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if cfg.BaseURL == "" {
+		errs = append(errs, errors.New("base URL is not set"))
+	} else if _, err := url.Parse(cfg.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("invalid base URL: %w", redactError(err)))
+	}
+
+	if cfg.Auth.Password != "" && cfg.Auth.Username == "" {
+		errs = append(errs, errors.New("password requires a username"))
+	}
+
+	if cfg.Auth.Username != "" && cfg.Auth.Password == "" && cfg.Auth.Token == "" {
+		errs = append(errs, errors.New("username requires a password"))
+	}
+
+	if cfg.Auth.Token != "" && cfg.Auth.Username != "" {
+		errs = append(errs, errors.New("token cannot be combined with a username"))
+	}
+
+	if cfg.Timeout < 0 {
+		errs = append(errs, errors.New("timeout cannot be negative"))
+	}
+
+	if cfg.Proxy != "" {
+		if _, err := url.Parse(cfg.Proxy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid proxy: %w", redactError(err)))
+		}
+	}
+
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("client certificate requires a certificate and a key file"))
+	}
+
+	if cfg.Retry != nil && cfg.Retry.MaxAttempts < 1 {
+		errs = append(errs, errors.New("max attempts must be at least 1"))
+	}
+
+	if cfg.Retry != nil && (cfg.Retry.Jitter < 0 || cfg.Retry.Jitter > 1) {
+		errs = append(errs, errors.New("jitter must be between 0 and 1"))
+	}
+
+	for _, r := range cfg.Rewrites {
+		if _, err := r.parse(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// NewFromConfig returns a new client with the validated configuration cfg. The options opts are
+// applied after the settings of the configuration.
+func NewFromConfig(cfg Config, opts ...Opt) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	hc, err := cfg.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	cfgOpts := []Opt{WithHTTPClient(hc)}
+
+	if cfg.ContentType != "" {
+		cfgOpts = append(cfgOpts, WithContentType(cfg.ContentType))
+	}
+
+	if cfg.Auth.Username != "" {
+		cfgOpts = append(cfgOpts, WithUsername(cfg.Auth.Username))
+	}
+
+	if cfg.Auth.Password != "" {
+		cfgOpts = append(cfgOpts, WithPassword(cfg.Auth.Password))
+	}
+
+	if cfg.Auth.Token != "" {
+		cfgOpts = append(cfgOpts, WithBearerToken(cfg.Auth.Token))
+	}
+
+	if cfg.Retry != nil {
+		p := RetryPolicy{
+			MaxAttempts: cfg.Retry.MaxAttempts,
+			Backoff:     time.Duration(cfg.Retry.Backoff),
+			MaxBackoff:  time.Duration(cfg.Retry.MaxBackoff),
+			Jitter:      cfg.Retry.Jitter,
+			StatusCodes: cfg.Retry.StatusCodes,
+		}
+
+		if cfg.Retry.Auto {
+			cfgOpts = append(cfgOpts, WithRetry(p))
+		} else {
+			cfgOpts = append(cfgOpts, WithRetryPolicy(p))
+		}
+	}
+
+	if len(cfg.Headers) > 0 {
+		header := make(http.Header, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			header.Set(k, v)
+		}
+
+		cfgOpts = append(cfgOpts, WithHeader(header))
+	}
+
+	if len(cfg.Rewrites) > 0 {
+		cfgOpts = append(cfgOpts, WithRewriteRules(cfg.Rewrites...))
+	}
+
+	return New(cfg.BaseURL, append(cfgOpts, opts...)...)
+}
+
+// httpClient returns the HTTP client with the timeout, proxy and TLS settings of cfg.
+func (cfg Config) httpClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", redactError(err))
+		}
+
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	tlsConfig, err := cfg.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	timeout := time.Duration(cfg.Timeout)
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}, nil
+}
+
+// config returns the TLS configuration of t, nil if there are no TLS settings.
+func (t TLSConfig) config() (*tls.Config, error) {
+	if t == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify, // nolint: gosec // explicitly configured
+	}
+
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %w", err)
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in CA file %s", t.CAFile)
+		}
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const testConfigYAML = `
+baseURL: https://hostname.domain
+contentType: application/yaml
+auth:
+  username: cognitive
+  password: distortions
+timeout: 10s
+retry:
+  maxAttempts: 5
+  backoff: 200ms
+  maxBackoff: 1s
+  jitter: 0.2
+  statusCodes: [429, 503]
+  auto: true
+headers:
+  X-Client: test
+rewrites:
+  - from: https://hostname.domain/v1
+    to: https://gateway.domain/hostname/v1
+`
+
+func TestConfig(t *testing.T) {
+	expected := Config{
+		BaseURL:     baseurl,
+		ContentType: ContentTypeYAML,
+		Auth:        AuthConfig{Username: username, Password: password},
+		Timeout:     Duration(10 * time.Second),
+		Retry: &RetryConfig{
+			MaxAttempts: 5,
+			Backoff:     Duration(200 * time.Millisecond),
+			MaxBackoff:  Duration(time.Second),
+			Jitter:      0.2,
+			StatusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			Auto:        true,
+		},
+		Headers:  map[string]string{"X-Client": "test"},
+		Rewrites: []RewriteRule{{From: "https://hostname.domain/v1", To: "https://gateway.domain/hostname/v1"}},
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		var cfg Config
+
+		assert.Nil(t, yaml.Unmarshal([]byte(testConfigYAML), &cfg))
+		assert.Equal(t, expected, cfg)
+
+		b, err := yaml.Marshal(cfg)
+		assert.Nil(t, err)
+		assert.Contains(t, string(b), "timeout: 10s")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		b, err := json.Marshal(expected)
+		assert.Nil(t, err)
+		assert.Contains(t, string(b), `"timeout":"10s"`)
+
+		var cfg Config
+
+		assert.Nil(t, json.Unmarshal(b, &cfg))
+		assert.Equal(t, expected, cfg)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		var cfg Config
+
+		assert.EqualError(t, json.Unmarshal([]byte(`{"timeout":"10"}`), &cfg), `time: missing unit in duration "10"`)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		assert.Nil(t, expected.Validate())
+
+		cfg := Config{
+			Auth:     AuthConfig{Password: password},
+			Timeout:  -1,
+			TLS:      TLSConfig{KeyFile: "key.pem"},
+			Retry:    &RetryConfig{Jitter: 2},
+			Rewrites: []RewriteRule{{From: "/v1"}},
+		}
+		assert.EqualError(t, cfg.Validate(), `base URL is not set
+password requires a username
+timeout cannot be negative
+client certificate requires a certificate and a key file
+max attempts must be at least 1
+jitter must be between 0 and 1
+rewrite rule requires from and to`)
+
+		cfg = Config{BaseURL: baseurl, Auth: AuthConfig{Username: username}}
+		assert.EqualError(t, cfg.Validate(), "username requires a password")
+	})
+}
+
+func TestNewFromConfig(t *testing.T) {
+	t.Run("settings", func(t *testing.T) {
+		var cfg Config
+
+		assert.Nil(t, yaml.Unmarshal([]byte(testConfigYAML), &cfg))
+
+		c, err := NewFromConfig(cfg)
+		assert.Nil(t, err)
+		assert.Equal(t, baseurl, c.BaseURL.String())
+		assert.Equal(t, ContentTypeYAML, c.ContentType)
+		assert.Equal(t, username, c.username)
+		assert.Equal(t, password, c.password)
+		assert.Equal(t, 10*time.Second, c.client.Timeout)
+		assert.Equal(t, &RetryPolicy{
+			MaxAttempts: 5,
+			Backoff:     200 * time.Millisecond,
+			MaxBackoff:  time.Second,
+			Jitter:      0.2,
+			StatusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		}, c.RetryPolicy)
+		assert.True(t, c.autoRetry)
+		assert.Len(t, c.rewrites, 1)
+
+		req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "test", req.Header.Get("X-Client"))
+	})
+
+	t.Run("options", func(t *testing.T) {
+		c, err := NewFromConfig(Config{BaseURL: baseurl, ContentType: ContentTypeYAML}, WithContentType(ContentTypeJSON))
+		assert.Nil(t, err)
+		assert.Equal(t, ContentTypeJSON, c.ContentType)
+		assert.Nil(t, c.RetryPolicy)
+		assert.False(t, c.autoRetry)
+	})
+
+	t.Run("token", func(t *testing.T) {
+		c, err := NewFromConfig(Config{BaseURL: baseurl, Auth: AuthConfig{Token: "token"}})
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+		_, err = NewFromConfig(Config{BaseURL: baseurl, Auth: AuthConfig{Username: username, Token: "token"}})
+		assert.EqualError(t, err, "token cannot be combined with a username")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewFromConfig(Config{})
+		assert.EqualError(t, err, "base URL is not set")
+	})
+}
@@ -0,0 +1,36 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithDefaultDeadline is a client option for bounding the requests sent with Do (and the
+// functions using it) by the deadline d, if their context has no deadline. It protects callers
+// forgetting to bound their contexts, especially if the timeout of the HTTP client is disabled
+// (e.g. for streaming endpoints).
+func WithDefaultDeadline(d time.Duration) Opt {
+	return func(c *Client) error {
+		if d <= 0 {
+			return errors.New("default deadline must be positive")
+		}
+
+		c.defaultDeadline = d
+
+		return nil
+	}
+}
+
+// withDefaultDeadline returns ctx with the default deadline of the client, if it has none.
+func (c *Client) withDefaultDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.defaultDeadline <= 0 {
+		return ctx, func() {}
+	}
+
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, c.defaultDeadline)
+}
@@ -0,0 +1,49 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDefaultDeadline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithHTTPClient(&http.Client{}), WithDefaultDeadline(20*time.Millisecond))
+	assert.Nil(t, err)
+
+	t.Run("without deadline", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("with deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		req, err := c.NewRequest(http.MethodGet, "/", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid deadline", func(t *testing.T) {
+		_, err := New(ts.URL, WithDefaultDeadline(0))
+		assert.EqualError(t, err, "default deadline must be positive")
+	})
+}
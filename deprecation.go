@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deprecation is the deprecation of an API resource announced by the server in the Deprecation
+// (RFC 9745) and Sunset (RFC 8594) headers of a response (see ParseDeprecation).
+type Deprecation struct {
+	// Deprecated reports whether the Deprecation header is present and Date is its date (since
+	// or from when the resource is deprecated), zero if it has none (e.g. "true").
+	Deprecated bool
+	Date       time.Time
+
+	// Sunset is the time the resource becomes unavailable, zero if unknown.
+	Sunset time.Time
+
+	// Successor is the URL of the link with the relation type successor-version or
+	// latest-version and Info of the link with the relation type deprecation or sunset (with
+	// information about the deprecation), empty if there is none.
+	Successor string
+	Info      string
+}
+
+// ParseDeprecation parses the Deprecation and Sunset headers and the related Link headers of
+// the response resp and reports whether it announces a deprecation or sunset. The Deprecation
+// header can be a structured date (e.g. @1688169599), an HTTP date or "true".
+func ParseDeprecation(resp *http.Response) (Deprecation, bool) {
+	d := Deprecation{}
+
+	if v := strings.TrimSpace(resp.Header.Get("Deprecation")); v != "" {
+		d.Deprecated = v != "false"
+		d.Date = deprecationDate(v)
+	}
+
+	if t, err := http.ParseTime(resp.Header.Get("Sunset")); err == nil {
+		d.Sunset = t
+	}
+
+	if !d.Deprecated && d.Sunset.IsZero() {
+		return d, false
+	}
+
+	links := ResponseLinks(resp, nil)
+
+	for _, rel := range []string{"successor-version", "latest-version"} {
+		if l, ok := links.Get(rel); ok && d.Successor == "" {
+			d.Successor = l.Href
+		}
+	}
+
+	for _, rel := range []string{"deprecation", "sunset"} {
+		if l, ok := links.Get(rel); ok && d.Info == "" {
+			d.Info = l.Href
+		}
+	}
+
+	return d, true
+}
+
+// deprecationDate returns the date of the Deprecation header value v, zero if it has none.
+func deprecationDate(v string) time.Time {
+	if strings.HasPrefix(v, "@") {
+		if s, err := strconv.ParseInt(v[1:], 10, 64); err == nil {
+			return time.Unix(s, 0).UTC()
+		}
+
+		return time.Time{}
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return t
+	}
+
+	return time.Time{}
+}
+
+// WithDeprecationHandler is a client option for calling f with the request and the
+// Deprecation of every response announcing a deprecation or sunset (see ParseDeprecation),
+// e.g. to log a warning about the upcoming retirement of a partner API:
+//
+//	httpclient.WithDeprecationHandler(func(r *http.Request, d httpclient.Deprecation) {
+//	    log.Printf("%s %s deprecated, sunset %s, successor %s", r.Method, r.URL.Path, d.Sunset, d.Successor)
+//	})
+//
+// f is called before the ResponseCallback and must not modify the request.
+func WithDeprecationHandler(f func(*http.Request, Deprecation)) Opt {
+	return func(c *Client) error {
+		if f == nil {
+			return errors.New("deprecation handler cannot be nil")
+		}
+
+		c.deprecation = f
+
+		return nil
+	}
+}
+
+// reportDeprecation calls the deprecation handler of the client, if the response resp announces
+// a deprecation.
+func (c *Client) reportDeprecation(resp *http.Response) {
+	if c.deprecation == nil {
+		return
+	}
+
+	if d, ok := ParseDeprecation(resp); ok {
+		c.deprecation(resp.Request, d)
+	}
+}
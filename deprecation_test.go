@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeprecation(t *testing.T) {
+	u, _ := url.Parse("https://hostname.domain/v1/posts")
+	sunset := time.Date(2021, 6, 30, 23, 59, 59, 0, time.UTC)
+
+	var tt = []struct {
+		name   string
+		header http.Header
+		found  bool
+		d      Deprecation
+	}{
+		{"none", http.Header{}, false, Deprecation{}},
+		{"structured date", http.Header{
+			"Deprecation": []string{"@1688169599"},
+			"Link":        []string{`</v2/posts>; rel="successor-version", <https://docs.domain/deprecation>; rel="deprecation"`},
+		}, true, Deprecation{
+			Deprecated: true,
+			Date:       time.Date(2023, 6, 30, 23, 59, 59, 0, time.UTC),
+			Successor:  "https://hostname.domain/v2/posts",
+			Info:       "https://docs.domain/deprecation",
+		}},
+		{"true and sunset", http.Header{
+			"Deprecation": []string{"true"},
+			"Sunset":      []string{"Wed, 30 Jun 2021 23:59:59 GMT"},
+			"Link":        []string{`</v3/posts>; rel="latest-version", </sunset>; rel="sunset"`},
+		}, true, Deprecation{
+			Deprecated: true,
+			Sunset:     sunset,
+			Successor:  "https://hostname.domain/v3/posts",
+			Info:       "https://hostname.domain/sunset",
+		}},
+		{"http date", http.Header{"Deprecation": []string{"Wed, 30 Jun 2021 23:59:59 GMT"}}, true, Deprecation{Deprecated: true, Date: sunset}},
+		{"sunset only", http.Header{"Sunset": []string{"Wed, 30 Jun 2021 23:59:59 GMT"}}, true, Deprecation{Sunset: sunset}},
+		{"invalid sunset", http.Header{"Sunset": []string{"tomorrow"}}, false, Deprecation{}},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			d, found := ParseDeprecation(&http.Response{Header: tc.header, Request: &http.Request{URL: u}})
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.d, d)
+		})
+	}
+}
+
+func TestWithDeprecationHandler(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			w.Header().Set("Deprecation", "true")
+		}
+	}))
+	defer ts.Close()
+
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+
+	c, err := New(ts.URL, WithDeprecationHandler(func(r *http.Request, d Deprecation) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		paths = append(paths, r.URL.Path)
+		assert.True(t, d.Deprecated)
+	}))
+	assert.Nil(t, err)
+
+	for _, p := range []string{"/old", "/new"} {
+		req, err := c.NewRequest(http.MethodGet, p, nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, []string{"/old"}, paths)
+
+	_, err = New(ts.URL, WithDeprecationHandler(nil))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,325 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrDigestMismatch is returned by Downloader.Download if the digest of the download does not
+// match the expected one.
+var ErrDigestMismatch = errors.New("digest mismatch")
+
+// ErrModified is returned by Downloader.Download if the file was modified during the download
+// and the download could not be restarted.
+var ErrModified = errors.New("file modified during download")
+
+// Default settings of the Downloader.
+const (
+	DefaultChunkSize   = 8 << 20
+	DefaultConcurrency = 4
+)
+
+// Downloader downloads large files in chunks (byte ranges) fetched concurrently, which is
+// significantly faster than a single request over links with high latency. If the server does
+// not support ranges, the file is downloaded with a single request.
+type Downloader struct {
+	Client *Client
+
+	// ChunkSize is the size of the chunks (default DefaultChunkSize) and Concurrency the
+	// maximum number of chunks fetched concurrently (default DefaultConcurrency). At most
+	// Concurrency chunks are held in memory.
+	ChunkSize   int64
+	Concurrency int
+
+	// Hash (e.g. sha256.New) and Digest are the hash function and the expected digest of the
+	// file, if it is verified.
+	Hash   func() hash.Hash
+	Digest []byte
+}
+
+// NewDownloader returns a Downloader with the client c and the default settings.
+func NewDownloader(c *Client) *Downloader {
+	return &Downloader{
+		Client:      c,
+		ChunkSize:   DefaultChunkSize,
+		Concurrency: DefaultConcurrency,
+	}
+}
+
+// chunkResult is a fetched chunk.
+type chunkResult struct {
+	data []byte
+	err  error
+}
+
+// Download downloads the file urlStr (see NewRequest) and writes it in order to w. The request
+// options opts are applied to all requests. It returns the number of bytes written and an error
+// if a request failed, the file has not the announced length or the digest does not match.
+//
+// The chunks after the first one are requested with an If-Range header with the ETag or
+// Last-Modified of the first response. If the file was modified in the meantime, the server
+// sends it completely and the download is restarted once from the beginning: w is truncated
+// if it is an io.Seeker with a Truncate method (e.g. *os.File), otherwise ErrModified is
+// returned.
+func (d *Downloader) Download(ctx context.Context, urlStr string, w io.Writer, opts ...RequestOpt) (int64, error) {
+	n, err := d.download(ctx, urlStr, w, opts)
+	if !errors.Is(err, ErrModified) {
+		return n, err
+	}
+
+	t, ok := w.(truncater)
+	if !ok {
+		return n, err
+	}
+
+	if err := t.Truncate(0); err != nil {
+		return n, err
+	}
+
+	if _, err := t.Seek(0, io.SeekStart); err != nil {
+		return n, err
+	}
+
+	return d.download(ctx, urlStr, w, opts)
+}
+
+// truncater is a writer which can be reset to restart a download.
+type truncater interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+// download downloads the file urlStr and writes it to w.
+func (d *Downloader) download(ctx context.Context, urlStr string, w io.Writer, opts []RequestOpt) (int64, error) {
+	chunkSize := d.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var h hash.Hash
+	if d.Hash != nil {
+		h = d.Hash()
+		w = io.MultiWriter(w, h)
+	}
+
+	n, total, validator, err := d.first(ctx, urlStr, chunkSize, w, opts)
+	if err == nil && n < total {
+		var m int64
+
+		if validator != "" {
+			opts = append(opts[:len(opts):len(opts)], SetHeader("If-Range", validator))
+		}
+
+		m, err = d.rest(ctx, urlStr, n, total, chunkSize, concurrency, w, opts)
+		n += m
+	}
+
+	if err != nil {
+		return n, err
+	}
+
+	if total >= 0 && n != total {
+		return n, fmt.Errorf("downloaded %d of %d bytes", n, total)
+	}
+
+	if h != nil && !bytes.Equal(h.Sum(nil), d.Digest) {
+		return n, fmt.Errorf("%w: %x", ErrDigestMismatch, h.Sum(nil))
+	}
+
+	return n, nil
+}
+
+// first fetches the first chunk and writes it to w. It returns the number of bytes written, the
+// total size (-1 if unknown) and the validator of the file for If-Range headers. If the server
+// does not support ranges, it writes the whole file.
+func (d *Downloader) first(ctx context.Context, urlStr string, chunkSize int64, w io.Writer, opts []RequestOpt) (int64, int64, string, error) {
+	resp, err := d.get(ctx, urlStr, 0, chunkSize-1, opts)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	defer func() {
+		_ = d.Client.closeBody(resp.Body)
+	}()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		n, err := io.Copy(w, resp.Body)
+		return n, resp.ContentLength, "", err
+	}
+
+	start, end, total, err := contentRange(resp)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	if start != 0 || total < 0 {
+		return 0, 0, "", fmt.Errorf("unexpected content range %q", resp.Header.Get("Content-Range"))
+	}
+
+	n, err := io.CopyN(w, resp.Body, end-start+1)
+
+	return n, total, validator(resp.Header), err
+}
+
+// validator returns the strong ETag or the Last-Modified date of the header h for If-Range
+// headers, empty if there is none.
+func validator(h http.Header) string {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return h.Get("Last-Modified")
+}
+
+// rest fetches the chunks from offset to total concurrently and writes them in order to w.
+func (d *Downloader) rest(ctx context.Context, urlStr string, offset, total, chunkSize int64, concurrency int, w io.Writer, opts []RequestOpt) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	wg := sync.WaitGroup{}
+
+	defer wg.Wait()
+	defer cancel()
+
+	count := int((total - offset + chunkSize - 1) / chunkSize)
+	results := make([]chan chunkResult, count)
+
+	for i := range results {
+		results[i] = make(chan chunkResult, 1)
+	}
+
+	sem := make(chan struct{}, concurrency)
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < count; i++ {
+			start := offset + int64(i)*chunkSize
+
+			end := start + chunkSize - 1
+			if end >= total {
+				end = total - 1
+			}
+
+			select {
+			case <-ctx.Done():
+				results[i] <- chunkResult{err: ctx.Err()}
+				continue
+			case sem <- struct{}{}:
+			}
+
+			wg.Add(1)
+
+			go func(i int, start, end int64) {
+				defer wg.Done()
+
+				data, err := d.chunk(ctx, urlStr, start, end, opts)
+				results[i] <- chunkResult{data, err}
+			}(i, start, end)
+		}
+	}()
+
+	var n int64
+
+	for i := range results {
+		r := <-results[i]
+		if r.err != nil {
+			return n, r.err
+		}
+
+		m, err := w.Write(r.data)
+		n += int64(m)
+
+		if err != nil {
+			return n, err
+		}
+
+		<-sem
+	}
+
+	return n, nil
+}
+
+// chunk fetches the bytes from start to end (inclusive).
+func (d *Downloader) chunk(ctx context.Context, urlStr string, start, end int64, opts []RequestOpt) ([]byte, error) {
+	resp, err := d.get(ctx, urlStr, start, end, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = d.Client.closeBody(resp.Body)
+	}()
+
+	if resp.StatusCode == http.StatusOK {
+		// the If-Range validator does not match
+		return nil, ErrModified
+	}
+
+	s, e, _, err := contentRange(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusPartialContent || s != start || e != end {
+		return nil, fmt.Errorf("unexpected content range %q for bytes %d-%d", resp.Header.Get("Content-Range"), start, end)
+	}
+
+	data := make([]byte, end-start+1)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// get sends a GET request for the bytes from start to end (inclusive).
+func (d *Downloader) get(ctx context.Context, urlStr string, start, end int64, opts []RequestOpt) (*http.Response, error) {
+	opts = append(opts[:len(opts):len(opts)], SetHeader("Range", fmt.Sprintf("bytes=%d-%d", start, end)))
+
+	req, err := d.Client.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.Client.send(ctx, req)
+	if err != nil {
+		if resp != nil && resp.Body != nil {
+			_ = d.Client.closeBody(resp.Body)
+		}
+
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// contentRange returns the first and last byte and the total size (-1 if unknown) of the
+// Content-Range header of resp.
+func contentRange(resp *http.Response) (int64, int64, int64, error) {
+	v := resp.Header.Get("Content-Range")
+
+	var start, end, total int64
+
+	if _, err := fmt.Sscanf(v, "bytes %d-%d/%d", &start, &end, &total); err == nil {
+		return start, end, total, nil
+	}
+
+	if _, err := fmt.Sscanf(v, "bytes %d-%d/*", &start, &end); err == nil {
+		return start, end, -1, nil
+	}
+
+	return 0, 0, 0, fmt.Errorf("invalid content range %q", v)
+}
@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloader(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789abcdefghijklmnopqrstuvwxyz", 28))
+	digest := sha256.Sum256(content)
+
+	var (
+		requests int64
+		version  int64
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+
+		switch r.URL.Path {
+		case "/file":
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+		case "/modified":
+			// the file is modified after the first request
+			v := atomic.AddInt64(&version, 1)
+			if v > 1 {
+				v = 2
+			}
+
+			w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, v))
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(bytes.Repeat([]byte{byte('0' + v)}, len(content))))
+		case "/norange":
+			_, _ = w.Write(content)
+		case "/broken":
+			if strings.HasPrefix(r.Header.Get("Range"), "bytes=0-") {
+				http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+				return
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("chunks", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+
+		d := NewDownloader(c)
+		d.ChunkSize = 100
+		d.Concurrency = 3
+		d.Hash = sha256.New
+		d.Digest = digest[:]
+
+		buf := new(bytes.Buffer)
+
+		n, err := d.Download(ctx, "/file", buf)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, content, buf.Bytes())
+		assert.Equal(t, int64(11), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("small file", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+
+		buf := new(bytes.Buffer)
+
+		n, err := NewDownloader(c).Download(ctx, "/file", buf)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, content, buf.Bytes())
+		assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("without range support", func(t *testing.T) {
+		d := NewDownloader(c)
+		d.ChunkSize = 100
+
+		buf := new(bytes.Buffer)
+
+		n, err := d.Download(ctx, "/norange", buf)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, content, buf.Bytes())
+	})
+
+	t.Run("digest mismatch", func(t *testing.T) {
+		d := NewDownloader(c)
+		d.Hash = sha256.New
+		d.Digest = []byte("other")
+
+		_, err := d.Download(ctx, "/file", new(bytes.Buffer))
+		assert.True(t, errors.Is(err, ErrDigestMismatch))
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		d := NewDownloader(c)
+		d.ChunkSize = 100
+
+		atomic.StoreInt64(&version, 0)
+
+		_, err := d.Download(ctx, "/modified", new(bytes.Buffer))
+		assert.True(t, errors.Is(err, ErrModified))
+
+		atomic.StoreInt64(&version, 0)
+
+		f, err := ioutil.TempFile("", "download")
+		assert.Nil(t, err)
+
+		defer os.Remove(f.Name())
+		defer f.Close()
+
+		n, err := d.Download(ctx, "/modified", f)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(content)), n)
+
+		b, err := ioutil.ReadFile(f.Name())
+		assert.Nil(t, err)
+		assert.Equal(t, bytes.Repeat([]byte("2"), len(content)), b)
+	})
+
+	t.Run("failed chunk", func(t *testing.T) {
+		d := NewDownloader(c)
+		d.ChunkSize = 100
+
+		n, err := d.Download(ctx, "/broken", new(bytes.Buffer))
+		assert.EqualError(t, err, "500 Internal Server Error")
+		assert.Equal(t, int64(100), n)
+	})
+}
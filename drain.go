@@ -0,0 +1,38 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// DefaultDrainLimit is the maximum number of bytes of a response body Do reads and discards
+// before closing it, unless changed with WithDrainLimit.
+const DefaultDrainLimit = 64 << 10
+
+// WithDrainLimit is a client option for setting the maximum number of bytes (0 disables
+// draining) of the unread part of a response body, e.g. of error responses, Do discards before
+// closing it. The transport only reuses the connection for further requests if the body was
+// read to the end, otherwise it closes the connection and the next request has to establish a
+// new one (including the TLS handshake). Larger bodies are not drained, reading them would take
+// longer than setting up a new connection.
+func WithDrainLimit(n int64) Opt {
+	return func(c *Client) error {
+		if n < 0 {
+			return errors.New("drain limit cannot be negative")
+		}
+
+		c.drainLimit = n
+
+		return nil
+	}
+}
+
+// closeBody discards up to the drain limit of the client from body and closes it.
+func (c *Client) closeBody(body io.ReadCloser) error {
+	if c.drainLimit > 0 {
+		_, _ = io.CopyN(ioutil.Discard, body, c.drainLimit)
+	}
+
+	return body.Close()
+}
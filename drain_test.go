@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDrainLimit(t *testing.T) {
+	tt := []struct {
+		name  string
+		opts  []Opt
+		size  int
+		conns int64
+	}{
+		{"default", nil, 32 << 10, 1},
+		{"disabled", []Opt{WithDrainLimit(0)}, 1 << 20, 3},
+		{"too small", []Opt{WithDrainLimit(512)}, 1 << 20, 3},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var conns int64
+
+			body := strings.Repeat("x", tc.size)
+
+			ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(body))
+			}))
+			ts.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+				if s == http.StateNew {
+					atomic.AddInt64(&conns, 1)
+				}
+			}
+			ts.Start()
+
+			defer ts.Close()
+
+			c, err := New(ts.URL, append(tc.opts, WithHTTPClient(&http.Client{Transport: &http.Transport{}}))...)
+			assert.Nil(t, err)
+
+			for i := 0; i < 3; i++ {
+				req, err := c.NewRequest(http.MethodGet, "/", nil)
+				assert.Nil(t, err)
+
+				_, err = c.Do(context.Background(), req, nil)
+				assert.NotNil(t, err)
+			}
+
+			assert.Equal(t, tc.conns, atomic.LoadInt64(&conns))
+		})
+	}
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := New(baseurl, WithDrainLimit(-1))
+		assert.EqualError(t, err, "drain limit cannot be negative")
+	})
+}
@@ -0,0 +1,406 @@
+// Package dynamic calls the operations of an OpenAPI 3 document loaded at runtime with an
+// httpclient.Client, for generic tooling (e.g. API explorers or test harnesses) where the
+// generated clients are impractical:
+//
+//	c, err := httpclient.New("https://petstore.example.com", httpclient.WithBasePath("/v1"))
+//	if err != nil {
+//		return err
+//	}
+//
+//	d, err := dynamic.LoadFile(c, "petstore.yaml")
+//	if err != nil {
+//		return err
+//	}
+//
+//	var pet map[string]interface{}
+//	_, err = d.Call(ctx, "getPet", map[string]interface{}{"petId": 1}, nil, &pet)
+//
+// The parameters are validated against the document before the request is sent. The paths
+// of the operations are resolved against the BaseURL of the client like the routes of the
+// generated clients, the servers of the document are ignored (see httpclient.WithBasePath).
+package dynamic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/postfinance/httpclient"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrUnknownOperation is returned by Call for operation IDs which are not in the document.
+	ErrUnknownOperation = errors.New("unknown operation")
+
+	// ErrInvalidParameters is returned (with the validation errors) by Call for parameters or
+	// bodies not matching the operation.
+	ErrInvalidParameters = errors.New("invalid parameters")
+)
+
+// methods are the HTTP methods of the operations of a path item.
+// nolint: gochecknoglobals
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Client calls the operations of an OpenAPI document.
+type Client struct {
+	client     *httpclient.Client
+	operations map[string]*Operation
+}
+
+// Operation is an operation of the document.
+type Operation struct {
+	ID         string
+	Method     string
+	Path       string
+	Summary    string
+	Parameters []Parameter
+
+	// Body and BodyRequired report whether the operation has a (required) request body.
+	Body         bool
+	BodyRequired bool
+}
+
+// Parameter is a path, query or header parameter of an operation.
+type Parameter struct {
+	Name     string `yaml:"name"`
+	In       string `yaml:"in"`
+	Required bool   `yaml:"required"`
+	Schema   Schema `yaml:"schema"`
+}
+
+// Schema is the schema of a parameter (the subset used for validation).
+type Schema struct {
+	Type  string        `yaml:"type"`
+	Enum  []interface{} `yaml:"enum"`
+	Items *Schema       `yaml:"items"`
+}
+
+// document is an OpenAPI 3 document (the subset used by the client).
+type document struct {
+	OpenAPI    string                                `yaml:"openapi"`
+	Paths      map[string]map[string]operationObject `yaml:"paths"`
+	Components struct {
+		Parameters map[string]parameterObject `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+// operationObject is an operation (or, for the key parameters, the parameters) of a path item.
+type operationObject struct {
+	OperationID string            `yaml:"operationId"`
+	Summary     string            `yaml:"summary"`
+	Parameters  []parameterObject `yaml:"parameters"`
+	RequestBody *struct {
+		Required bool `yaml:"required"`
+	} `yaml:"requestBody"`
+}
+
+// UnmarshalYAML decodes an operation or the list of parameters of a path item.
+func (o *operationObject) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&o.Parameters); err == nil {
+		return nil
+	}
+
+	type plain operationObject
+
+	return unmarshal((*plain)(o))
+}
+
+// parameterObject is a parameter or a reference to a parameter of the components.
+type parameterObject struct {
+	Parameter `yaml:",inline"`
+	Ref       string `yaml:"$ref"`
+}
+
+// LoadFile returns a client for the operations of the OpenAPI document (JSON or YAML) in the
+// file name.
+func LoadFile(c *httpclient.Client, name string) (*Client, error) {
+	data, err := ioutil.ReadFile(name) // nolint: gosec // G304: file inclusion is intended
+	if err != nil {
+		return nil, fmt.Errorf("could not read OpenAPI document: %w", err)
+	}
+
+	return Load(c, data)
+}
+
+// Load returns a client for the operations of the OpenAPI document data (JSON or YAML).
+func Load(c *httpclient.Client, data []byte) (*Client, error) {
+	var doc document
+
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("could not decode OpenAPI document: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+
+	resolve := func(p parameterObject) (Parameter, error) {
+		if p.Ref == "" {
+			return p.Parameter, nil
+		}
+
+		name := strings.TrimPrefix(p.Ref, "#/components/parameters/")
+
+		r, ok := doc.Components.Parameters[name]
+		if !ok || name == p.Ref || r.Ref != "" {
+			return Parameter{}, fmt.Errorf("unresolved parameter %s", p.Ref)
+		}
+
+		return r.Parameter, nil
+	}
+
+	d := &Client{client: c, operations: map[string]*Operation{}}
+
+	for path, item := range doc.Paths {
+		common := item["parameters"].Parameters
+
+		for _, method := range methods {
+			o, ok := item[method]
+			if !ok {
+				continue
+			}
+
+			if o.OperationID == "" {
+				return nil, fmt.Errorf("%s %s has no operationId", strings.ToUpper(method), path)
+			}
+
+			if _, ok := d.operations[o.OperationID]; ok {
+				return nil, fmt.Errorf("duplicate operationId %s", o.OperationID)
+			}
+
+			op := &Operation{
+				ID:           o.OperationID,
+				Method:       strings.ToUpper(method),
+				Path:         path,
+				Summary:      o.Summary,
+				Body:         o.RequestBody != nil,
+				BodyRequired: o.RequestBody != nil && o.RequestBody.Required,
+			}
+
+			// parameters of the operation override the common parameters of the path
+			params := map[string]Parameter{}
+
+			for _, p := range append(append([]parameterObject{}, common...), o.Parameters...) {
+				p, err := resolve(p)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", o.OperationID, err)
+				}
+
+				params[p.In+"/"+p.Name] = p
+			}
+
+			for _, p := range params {
+				if p.In == "path" {
+					p.Required = true
+				}
+
+				op.Parameters = append(op.Parameters, p)
+			}
+
+			sort.Slice(op.Parameters, func(i, j int) bool { return op.Parameters[i].Name < op.Parameters[j].Name })
+
+			d.operations[op.ID] = op
+		}
+	}
+
+	return d, nil
+}
+
+// Operations returns the operations of the document sorted by ID.
+func (c *Client) Operations() []*Operation {
+	ops := make([]*Operation, 0, len(c.operations))
+	for _, op := range c.operations {
+		ops = append(ops, op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool { return ops[i].ID < ops[j].ID })
+
+	return ops
+}
+
+// Operation returns the operation with the ID id, nil if there is no such operation.
+func (c *Client) Operation(id string) *Operation {
+	return c.operations[id]
+}
+
+// Call sends the request of the operation with the ID operationID with the path, query and header
+// parameters params and the body (nil for none) and decodes the response into out (see
+// httpclient.Client.Do). The parameters and the body are validated against the operation first.
+func (c *Client) Call(ctx context.Context, operationID string, params map[string]interface{}, body, out interface{}) (*http.Response, error) {
+	op, ok := c.operations[operationID]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", operationID, ErrUnknownOperation)
+	}
+
+	if err := op.Validate(params, body); err != nil {
+		return nil, err
+	}
+
+	path := op.Path
+	query := url.Values{}
+	opts := []httpclient.RequestOpt{}
+
+	for _, p := range op.Parameters {
+		v, ok := params[p.Name]
+		if !ok {
+			continue
+		}
+
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(fmt.Sprint(v)))
+		case "query":
+			for _, s := range values(v) {
+				query.Add(p.Name, s)
+			}
+		case "header":
+			opts = append(opts, httpclient.SetHeader(p.Name, strings.Join(values(v), ",")))
+		}
+	}
+
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	req, err := c.client.NewRequestWithContext(ctx, op.Method, path, body, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.client.Do(ctx, req, out)
+}
+
+// Validate returns the errors (with ErrInvalidParameters) of the parameters params and the body
+// for the operation, nil if they are valid.
+func (op *Operation) Validate(params map[string]interface{}, body interface{}) error {
+	errs := []error{}
+	known := map[string]bool{}
+
+	for _, p := range op.Parameters {
+		if p.In == "cookie" {
+			continue
+		}
+
+		known[p.Name] = true
+
+		v, ok := params[p.Name]
+		if !ok {
+			if p.Required {
+				errs = append(errs, fmt.Errorf("%s parameter %s is required", p.In, p.Name))
+			}
+
+			continue
+		}
+
+		if err := p.Schema.validate(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s parameter %s: %w", p.In, p.Name, err))
+		}
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !known[name] {
+			errs = append(errs, fmt.Errorf("unknown parameter %s", name))
+		}
+	}
+
+	switch {
+	case body == nil && op.BodyRequired:
+		errs = append(errs, errors.New("request body is required"))
+	case body != nil && !op.Body:
+		errs = append(errs, errors.New("operation has no request body"))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w: %w", op.ID, ErrInvalidParameters, errors.Join(errs...))
+}
+
+// validate checks the type and the enum values of the parameter value v.
+func (s Schema) validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+
+	switch s.Type {
+	case "integer":
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return fmt.Errorf("%T is not an integer", v)
+		}
+	case "number":
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			return fmt.Errorf("%T is not a number", v)
+		}
+	case "boolean":
+		if rv.Kind() != reflect.Bool {
+			return fmt.Errorf("%T is not a boolean", v)
+		}
+	case "string":
+		if rv.Kind() != reflect.String {
+			return fmt.Errorf("%T is not a string", v)
+		}
+	case "array":
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Errorf("%T is not an array", v)
+		}
+
+		if s.Items == nil {
+			return nil
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if err := s.Items.validate(rv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+
+		return nil
+	}
+
+	if len(s.Enum) == 0 {
+		return nil
+	}
+
+	for _, e := range s.Enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v is not one of %v", v, s.Enum)
+}
+
+// values returns the string values of the parameter value v, the elements of arrays.
+func values(v interface{}) []string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []string{fmt.Sprint(v)}
+	}
+
+	s := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		s = append(s, fmt.Sprint(rv.Index(i).Interface()))
+	}
+
+	return s
+}
@@ -0,0 +1,224 @@
+package dynamic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/postfinance/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDocument = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+        - name: status
+          in: query
+          schema:
+            type: string
+            enum: [available, sold]
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json: {}
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/petId'
+    get:
+      operationId: getPet
+      parameters:
+        - name: X-Request-ID
+          in: header
+          schema:
+            type: string
+    delete:
+      operationId: deletePet
+components:
+  parameters:
+    petId:
+      name: petId
+      in: path
+      schema:
+        type: integer
+`
+
+type pet struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("operations", func(t *testing.T) {
+		d, err := Load(nil, []byte(testDocument))
+		assert.Nil(t, err)
+
+		ids := []string{}
+		for _, op := range d.Operations() {
+			ids = append(ids, op.ID)
+		}
+
+		assert.Equal(t, []string{"createPet", "deletePet", "getPet", "listPets"}, ids)
+		assert.Equal(t, &Operation{
+			ID:     "getPet",
+			Method: http.MethodGet,
+			Path:   "/pets/{petId}",
+			Parameters: []Parameter{
+				{Name: "X-Request-ID", In: "header", Schema: Schema{Type: "string"}},
+				{Name: "petId", In: "path", Required: true, Schema: Schema{Type: "integer"}},
+			},
+		}, d.Operation("getPet"))
+		assert.True(t, d.Operation("createPet").BodyRequired)
+		assert.Nil(t, d.Operation("updatePet"))
+	})
+
+	t.Run("JSON file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "dynamic")
+		assert.Nil(t, err)
+
+		defer os.RemoveAll(dir)
+
+		name := filepath.Join(dir, "openapi.json")
+		assert.Nil(t, ioutil.WriteFile(name, []byte(`{"openapi":"3.1.0","paths":{"/pets":{"get":{"operationId":"listPets"}}}}`), 0o600))
+
+		d, err := LoadFile(nil, name)
+		assert.Nil(t, err)
+		assert.Len(t, d.Operations(), 1)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			doc, err string
+		}{
+			{`swagger: "2.0"`, `unsupported OpenAPI version ""`},
+			{`{"openapi":"3.0.0","paths":{"/pets":{"get":{}}}}`, "GET /pets has no operationId"},
+			{`{"openapi":"3.0.0","paths":{"/a":{"get":{"operationId":"x"}},"/b":{"get":{"operationId":"x"}}}}`, "duplicate operationId x"},
+			{`{"openapi":"3.0.0","paths":{"/a":{"get":{"operationId":"x","parameters":[{"$ref":"#/components/parameters/y"}]}}}}`, "x: unresolved parameter #/components/parameters/y"},
+		} {
+			_, err := Load(nil, []byte(tc.doc))
+			assert.EqualError(t, err, tc.err)
+		}
+
+		_, err := LoadFile(nil, "missing.yaml")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCall(t *testing.T) {
+	var req *http.Request
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+
+		w.Header().Set("Content-Type", httpclient.ContentTypeJSON)
+
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Path == "/v1/pets" {
+				_ = json.NewEncoder(w).Encode([]pet{{ID: 1, Name: "Rex"}})
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(pet{ID: 1, Name: "Rex"})
+		case http.MethodPost:
+			var p pet
+
+			_ = json.NewDecoder(r.Body).Decode(&p)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(p)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := httpclient.New(ts.URL, httpclient.WithBasePath("/v1"))
+	assert.Nil(t, err)
+
+	d, err := Load(c, []byte(testDocument))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("path and header", func(t *testing.T) {
+		var p pet
+
+		_, err := d.Call(ctx, "getPet", map[string]interface{}{"petId": 1, "X-Request-ID": "abc"}, nil, &p)
+		assert.Nil(t, err)
+		assert.Equal(t, pet{ID: 1, Name: "Rex"}, p)
+		assert.Equal(t, "/v1/pets/1", req.URL.Path)
+		assert.Equal(t, "abc", req.Header.Get("X-Request-ID"))
+	})
+
+	t.Run("query", func(t *testing.T) {
+		var pets []pet
+
+		_, err := d.Call(ctx, "listPets", map[string]interface{}{"limit": 10, "tags": []string{"a", "b"}, "status": "sold"}, nil, &pets)
+		assert.Nil(t, err)
+		assert.Len(t, pets, 1)
+		assert.Equal(t, "limit=10&status=sold&tags=a&tags=b", req.URL.RawQuery)
+	})
+
+	t.Run("body", func(t *testing.T) {
+		var p pet
+
+		resp, err := d.Call(ctx, "createPet", nil, pet{Name: "Tom"}, &p)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "Tom", p.Name)
+	})
+
+	t.Run("unknown operation", func(t *testing.T) {
+		_, err := d.Call(ctx, "updatePet", nil, nil, nil)
+		assert.True(t, errors.Is(err, ErrUnknownOperation))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		req = nil
+
+		_, err := d.Call(ctx, "listPets", map[string]interface{}{
+			"limit":  "10",
+			"tags":   []interface{}{"a", 1},
+			"status": "lost",
+			"color":  "red",
+		}, pet{}, nil)
+		assert.True(t, errors.Is(err, ErrInvalidParameters))
+		assert.EqualError(t, err, `listPets: invalid parameters: query parameter limit: string is not an integer
+query parameter status: lost is not one of [available sold]
+query parameter tags: item 1: int is not a string
+unknown parameter color
+operation has no request body`)
+		assert.Nil(t, req)
+
+		_, err = d.Call(ctx, "deletePet", nil, nil, nil)
+		assert.EqualError(t, err, "deletePet: invalid parameters: path parameter petId is required")
+
+		_, err = d.Call(ctx, "createPet", nil, nil, nil)
+		assert.EqualError(t, err, "createPet: invalid parameters: request body is required")
+	})
+}
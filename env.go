@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// NewFromEnv returns a new client configured with the environment variables with the prefix
+// (e.g. API for API_URL), so deployments can configure clients without code changes:
+//
+//	<prefix>_URL                   base URL (required)
+//	<prefix>_USERNAME              username and password for basic authentication
+//	<prefix>_PASSWORD
+//	<prefix>_TOKEN                 bearer token (see WithBearerToken), not with a username
+//	<prefix>_TIMEOUT               timeout of the requests (e.g. 10s, default 30s)
+//	<prefix>_PROXY                 proxy URL (default: HTTP_PROXY, HTTPS_PROXY and NO_PROXY)
+//	<prefix>_CA_FILE               PEM file with the CA certificates
+//	<prefix>_CERT_FILE             PEM files with the client certificate and key
+//	<prefix>_KEY_FILE
+//	<prefix>_INSECURE_SKIP_VERIFY  disables the verification of the server certificate
+//
+// The options opts are applied after the settings of the environment variables (see
+// NewFromConfig).
+func NewFromEnv(prefix string, opts ...Opt) (*Client, error) {
+	env := func(name string) string {
+		return os.Getenv(prefix + "_" + name)
+	}
+
+	baseURL := env("URL")
+	if baseURL == "" {
+		return nil, fmt.Errorf("%s_URL is not set", prefix)
+	}
+
+	cfg := Config{
+		BaseURL: baseURL,
+		Auth: AuthConfig{
+			Username: env("USERNAME"),
+			Password: env("PASSWORD"),
+			Token:    env("TOKEN"),
+		},
+		Proxy: env("PROXY"),
+		TLS: TLSConfig{
+			CAFile:   env("CA_FILE"),
+			CertFile: env("CERT_FILE"),
+			KeyFile:  env("KEY_FILE"),
+		},
+	}
+
+	if v := env("TIMEOUT"); v != "" {
+		if err := cfg.Timeout.UnmarshalText([]byte(v)); err != nil {
+			return nil, fmt.Errorf("%s_TIMEOUT: %w", prefix, err)
+		}
+	}
+
+	if v := env("INSECURE_SKIP_VERIFY"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s_INSECURE_SKIP_VERIFY: %w", prefix, err)
+		}
+
+		cfg.TLS.InsecureSkipVerify = b
+	}
+
+	c, err := NewFromConfig(cfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", prefix, err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Run("settings", func(t *testing.T) {
+		t.Setenv("API_URL", baseurl)
+		t.Setenv("API_USERNAME", username)
+		t.Setenv("API_PASSWORD", password)
+		t.Setenv("API_TIMEOUT", "5s")
+
+		c, err := NewFromEnv("API", WithContentType(ContentTypeYAML))
+		assert.Nil(t, err)
+		assert.Equal(t, baseurl, c.BaseURL.String())
+		assert.Equal(t, username, c.username)
+		assert.Equal(t, password, c.password)
+		assert.Equal(t, 5*time.Second, c.client.Timeout)
+		assert.Equal(t, ContentTypeYAML, c.ContentType)
+	})
+
+	t.Run("token", func(t *testing.T) {
+		t.Setenv("API_URL", baseurl)
+		t.Setenv("API_TOKEN", "token")
+
+		c, err := NewFromEnv("API")
+		assert.Nil(t, err)
+		assert.Equal(t, "token", c.bearerToken)
+
+		t.Setenv("API_USERNAME", username)
+
+		_, err = NewFromEnv("API")
+		assert.EqualError(t, err, "API: token cannot be combined with a username")
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("API_URL", baseurl)
+
+		c, err := NewFromEnv("API")
+		assert.Nil(t, err)
+		assert.Equal(t, 30*time.Second, c.client.Timeout)
+		assert.Nil(t, c.client.Transport.(*http.Transport).TLSClientConfig.RootCAs)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			name, value, err string
+		}{
+			{"URL", "", "API_URL is not set"},
+			{"TIMEOUT", "5", `API_TIMEOUT: time: missing unit in duration "5"`},
+			{"INSECURE_SKIP_VERIFY", "maybe", `API_INSECURE_SKIP_VERIFY: strconv.ParseBool: parsing "maybe": invalid syntax`},
+			{"PROXY", ":", `API: invalid proxy: parse ":": missing protocol scheme`},
+			{"USERNAME", username, "API: username requires a password"},
+			{"CERT_FILE", "cert.pem", "API: client certificate requires a certificate and a key file"},
+			{"CA_FILE", "missing.pem", "API: could not read CA file: open missing.pem: no such file or directory"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				t.Setenv("API_URL", baseurl)
+				t.Setenv("API_"+tc.name, tc.value)
+
+				_, err := NewFromEnv("API")
+				assert.EqualError(t, err, tc.err)
+			})
+		}
+	})
+
+	t.Run("CA file", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		dir, err := ioutil.TempDir("", "env")
+		assert.Nil(t, err)
+
+		defer os.RemoveAll(dir)
+
+		ca := filepath.Join(dir, "ca.pem")
+		assert.Nil(t, ioutil.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0o600))
+
+		t.Setenv("API_URL", ts.URL)
+		t.Setenv("API_CA_FILE", ca)
+
+		c, err := NewFromEnv("API")
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+		assert.Nil(t, err)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		proxied := ""
+
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+		}))
+		defer proxy.Close()
+
+		t.Setenv("API_URL", baseurl)
+		t.Setenv("API_PROXY", proxy.URL)
+
+		c, err := NewFromEnv("API")
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "http://hostname.domain/nodes", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "http://hostname.domain/nodes", proxied)
+	})
+}
@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrPreconditionFailed is returned (with the error of the ResponseCallback) for responses with
+// status 412 Precondition Failed, e.g. to requests with an If-Match header if the resource was
+// modified since it was read.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// WithOptimisticConcurrency is a client option for the optimistic concurrency control of read-
+// modify-write cycles: the ETag of the responses to GET requests is remembered per URL (without
+// query) and sent as If-Match header with the next PUT, PATCH or DELETE request to the URL,
+// unless the request has an If-Match header already. If the resource was modified in between,
+// the request fails with ErrPreconditionFailed and the remembered ETag is dropped, so the
+// resource has to be read again:
+//
+//	for {
+//		// GET, modify, PUT
+//		if err := update(ctx); !errors.Is(err, httpclient.ErrPreconditionFailed) {
+//			return err
+//		}
+//	}
+func WithOptimisticConcurrency() Opt {
+	return func(c *Client) error {
+		c.etags = &etagStore{etags: map[string]string{}}
+		return nil
+	}
+}
+
+// IfMatch is a request option for setting the If-Match header to the ETag etag (e.g. of an
+// earlier response), the request fails with ErrPreconditionFailed if the resource has another
+// ETag.
+func IfMatch(etag string) RequestOpt {
+	return SetHeader("If-Match", etag)
+}
+
+// etagStore are the ETags of the resources read by URL.
+type etagStore struct {
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+// etagKey returns the key of the resource of the request r.
+func etagKey(r *http.Request) string {
+	u := *r.URL
+	u.RawQuery, u.Fragment = "", ""
+
+	return u.String()
+}
+
+// ifMatch returns the request r with the If-Match header of the remembered ETag, if r is a PUT,
+// PATCH or DELETE request without If-Match header.
+func (s *etagStore) ifMatch(r *http.Request) *http.Request {
+	switch r.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return r
+	}
+
+	if r.Header.Get("If-Match") != "" {
+		return r
+	}
+
+	s.mu.Lock()
+	etag, ok := s.etags[etagKey(r)]
+	s.mu.Unlock()
+
+	if !ok {
+		return r
+	}
+
+	r = r.Clone(r.Context())
+	r.Header.Set("If-Match", etag)
+
+	return r
+}
+
+// update remembers the ETag of the response resp to the request r, err is the error of the
+// ResponseCallback.
+func (s *etagStore) update(r *http.Request, resp *http.Response, err error) {
+	key := etagKey(r)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case resp == nil:
+	case resp.StatusCode == http.StatusPreconditionFailed, err == nil && r.Method == http.MethodDelete:
+		delete(s.etags, key)
+	case err != nil:
+	case r.Method == http.MethodGet || r.Method == http.MethodPut || r.Method == http.MethodPatch:
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			s.etags[key] = etag
+		} else if r.Method != http.MethodGet {
+			delete(s.etags, key)
+		}
+	}
+}
+
+// preconditionFailed returns err with ErrPreconditionFailed for 412 responses resp.
+func preconditionFailed(resp *http.Response, err error) error {
+	if err == nil || resp == nil || resp.StatusCode != http.StatusPreconditionFailed {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", ErrPreconditionFailed, err)
+}
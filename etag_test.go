@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimisticConcurrency(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		version = 1
+		text    = "v1"
+		ifMatch []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		etag := fmt.Sprintf(`"%d"`, version)
+
+		if r.Method != http.MethodGet {
+			ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+
+			if m := r.Header.Get("If-Match"); m != "" && m != etag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			var m message
+
+			_ = json.NewDecoder(r.Body).Decode(&m)
+			version++
+			text = m.Text
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_ = json.NewEncoder(w).Encode(message{Text: text})
+	}))
+	defer ts.Close()
+
+	// modify sets the text of the message behind the back of the client
+	modify := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		version++
+		text = s
+	}
+
+	ctx := context.Background()
+
+	c, err := New(ts.URL, WithOptimisticConcurrency())
+	assert.Nil(t, err)
+
+	get := func(path string) {
+		req, err := c.NewRequest(http.MethodGet, path, nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, &message{})
+		assert.Nil(t, err)
+	}
+
+	send := func(method, text string) error {
+		req, err := c.NewRequest(method, "/messages/1", message{Text: text})
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+
+		return err
+	}
+
+	t.Run("read-modify-write", func(t *testing.T) {
+		ifMatch = nil
+
+		get("/messages/1?fields=text")
+		assert.Nil(t, send(http.MethodPut, "v2"))
+		assert.Nil(t, send(http.MethodPut, "v3")) // ETag of the PUT response
+		assert.Equal(t, []string{`"1"`, `"2"`}, ifMatch)
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		ifMatch = nil
+
+		get("/messages/1")
+		modify("other")
+
+		err := send(http.MethodPut, "mine")
+		assert.True(t, errors.Is(err, ErrPreconditionFailed))
+		assert.Equal(t, http.StatusPreconditionFailed, statusCode(err))
+		assert.Equal(t, "precondition failed: 412 Precondition Failed", err.Error())
+
+		// the ETag is dropped
+		assert.Nil(t, send(http.MethodPut, "mine"))
+		assert.Equal(t, []string{`"3"`, ""}, ifMatch)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		ifMatch = nil
+
+		get("/messages/1")
+		assert.Nil(t, send(http.MethodDelete, ""))
+		assert.Nil(t, send(http.MethodDelete, ""))
+		assert.Equal(t, []string{`"5"`, ""}, ifMatch)
+	})
+
+	t.Run("explicit If-Match", func(t *testing.T) {
+		c, err := New(ts.URL)
+		assert.Nil(t, err)
+
+		req, err := c.NewRequestWithContext(ctx, http.MethodPut, "/messages/1", message{Text: "x"}, IfMatch(`"0"`))
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+		assert.True(t, errors.Is(err, ErrPreconditionFailed))
+	})
+}
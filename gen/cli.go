@@ -0,0 +1,304 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const cliTemplate = `
+// Code generated by httpclient-gen-go; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns a command line client with a subcommand for every service and a
+// subcommand for every service method. The parameters of the methods are flags, values
+// of structured types are passed as JSON. The results are printed as JSON.
+func NewCommand(opts ...httpclient.Opt) *cobra.Command {
+	var baseURL string
+
+	cmd := &cobra.Command{
+		Use:          "{{ .Package }}",
+		Short:        "Command line client for the {{ .Package }} API",
+		SilenceUsage: true,
+	}
+
+	cmd.PersistentFlags().StringVar(&baseURL, "base-url", "", "base URL of the API")
+
+	newClient := func() (*Client, error) {
+		return NewClient(baseURL, opts...)
+	}
+{{ range .Services }}
+{{- if .Commands }}
+	cmd.AddCommand(new{{ .FieldName }}Command(newClient))
+{{- end }}
+{{- end }}
+
+	return cmd
+}
+{{- range .Services }}
+{{- if .Commands }}
+{{- $svc := . }}
+
+func new{{ .FieldName }}Command(newClient func() (*Client, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "{{ kebab .FieldName }}",
+		Short: "Methods of {{ .InterfaceName }}",
+	}
+{{ range .Commands }}
+	cmd.AddCommand(new{{ $svc.FieldName }}{{ .Name }}Command(newClient))
+{{- end }}
+
+	return cmd
+}
+{{- range .Commands }}
+
+func new{{ $svc.FieldName }}{{ .Name }}Command(newClient func() (*Client, error)) *cobra.Command {
+{{- range .Flags }}
+	var {{ .Var }} {{ if .Func }}{{ .Type }}{{ else }}string{{ end }}
+{{- end }}
+{{- if .Flags }}
+{{ end }}
+	cmd := &cobra.Command{
+		Use:   "{{ kebab .Name }}",
+		Short: "Calls {{ $svc.InterfaceName }}.{{ .Name }}",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+{{- range .Flags }}
+{{- if not .Func }}
+
+			var {{ .Arg }} {{ .Type }}
+			if err := cliUnmarshal({{ .Var }}, &{{ .Arg }}); err != nil {
+				return err
+			}
+{{- end }}
+{{- end }}
+
+{{- if .Result }}
+
+			v, _, err := client.{{ $svc.FieldName }}.{{ .Name }}(cmd.Context(){{ range .Flags }}, {{ .Arg }}{{ end }})
+			if err != nil {
+				return err
+			}
+
+			return cliPrint(cmd.OutOrStdout(), v)
+{{- else }}
+
+			resp, err := client.{{ $svc.FieldName }}.{{ .Name }}(cmd.Context(){{ range .Flags }}, {{ .Arg }}{{ end }})
+			if err != nil {
+				return err
+			}
+
+			return cliPrint(cmd.OutOrStdout(), map[string]interface{}{"status": resp.Status})
+{{- end }}
+		},
+	}
+{{- range .Flags }}
+{{- if .Func }}
+
+	cmd.Flags().{{ .Func }}(&{{ .Var }}, "{{ .Name }}", {{ .Zero }}, "{{ .Name }} ({{ .Type }})")
+{{- else }}
+
+	cmd.Flags().StringVar(&{{ .Var }}, "{{ .Name }}", "", "{{ .Name }} ({{ .Type }} as JSON)")
+{{- end }}
+{{- end }}
+
+	return cmd
+}
+{{- end }}
+{{- end }}
+{{- end }}
+
+// cliUnmarshal decodes the JSON flag value s into v, empty values are ignored.
+func cliUnmarshal(s string, v interface{}) error {
+	if s == "" {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(s), v)
+}
+
+// cliPrint writes v as indented JSON to w.
+func cliPrint(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+`
+
+// Command contains all information to generate a CLI command for a service method.
+type Command struct {
+	Name   string
+	Flags  []CLIFlag
+	Result bool // the method returns a value
+}
+
+// CLIFlag is a flag of a CLI command for a method parameter.
+type CLIFlag struct {
+	Name string // flag name
+	Var  string // variable of the flag value
+	Arg  string // argument passed to the method
+	Type string // go type of the parameter
+	Func string // pflag function for basic types, empty for JSON values
+	Zero string // zero value for basic types
+}
+
+// flagFuncs are the pflag functions for the basic types.
+// nolint: gochecknoglobals
+var flagFuncs = map[string]string{
+	"string":        "StringVar",
+	"bool":          "BoolVar",
+	"int":           "IntVar",
+	"int32":         "Int32Var",
+	"int64":         "Int64Var",
+	"uint":          "UintVar",
+	"uint32":        "Uint32Var",
+	"uint64":        "Uint64Var",
+	"float32":       "Float32Var",
+	"float64":       "Float64Var",
+	"time.Duration": "DurationVar",
+	"[]string":      "StringSliceVar",
+	"[]int":         "IntSliceVar",
+}
+
+// newCommands returns the CLI commands for the methods of the interface type it.
+func newCommands(it *ast.InterfaceType, decls *declarations) ([]Command, error) {
+	fields, err := methodSet(it, decls, map[*ast.InterfaceType]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []Command{}
+
+	for _, f := range fields {
+		c, err := newCommand(f.Names[0].Name, f.Type.(*ast.FuncType))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Names[0].Name, err)
+		}
+
+		commands = append(commands, c)
+	}
+
+	return commands, nil
+}
+
+// newCommand returns the CLI command for the method name with function type ft.
+func newCommand(name string, ft *ast.FuncType) (Command, error) {
+	c := Command{Name: name}
+	params := []param{}
+
+	for i, f := range ft.Params.List {
+		if _, ok := f.Type.(*ast.Ellipsis); ok {
+			return c, errors.New("variadic parameters are not supported")
+		}
+
+		if len(f.Names) == 0 {
+			params = append(params, param{Name: fmt.Sprintf("arg%d", i), Type: types.ExprString(f.Type)})
+			continue
+		}
+
+		for _, n := range f.Names {
+			params = append(params, param{Name: n.Name, Type: types.ExprString(f.Type)})
+		}
+	}
+
+	if len(params) == 0 || params[0].Type != "context.Context" {
+		return c, errors.New("first parameter must be a context.Context")
+	}
+
+	for i, p := range params[1:] {
+		if p.Name == "_" {
+			p.Name = fmt.Sprintf("arg%d", i+1)
+		}
+
+		fl := CLIFlag{
+			Name: kebab(p.Name),
+			Var:  p.Name + "Flag",
+			Arg:  p.Name + "Arg",
+			Type: p.Type,
+			Func: flagFuncs[p.Type],
+		}
+
+		if fl.Func != "" {
+			fl.Arg = fl.Var
+			fl.Zero = zeroValue(ast.NewIdent(p.Type))
+
+			if p.Type == "time.Duration" {
+				fl.Zero = "0"
+			}
+		}
+
+		c.Flags = append(c.Flags, fl)
+	}
+
+	results := 0
+
+	if ft.Results != nil {
+		for _, f := range ft.Results.List {
+			results += len(f.Names)
+			if len(f.Names) == 0 {
+				results++
+			}
+		}
+	}
+
+	if results < 2 || results > 3 {
+		return c, errors.New("results must be (T, *http.Response, error) or (*http.Response, error)")
+	}
+
+	c.Result = results == 3
+
+	return c, nil
+}
+
+// kebab returns the kebab case of the camel case name s, e.g. newPost becomes new-post.
+func kebab(s string) string {
+	b := strings.Builder{}
+
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(rune(s[i-1])) {
+				b.WriteByte('-')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// generateCLI returns the formatted code of the command line client (-cli) for file.
+func generateCLI(data *Data, file string) ([]byte, error) {
+	t, err := template.New("CLI Template").Funcs(template.FuncMap{"kebab": kebab}).Parse(cliTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := t.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	return format(file, buf.Bytes(), data.opts.GoImports)
+}
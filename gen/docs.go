@@ -0,0 +1,289 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const docsTemplate = `# {{ .Package }} API
+
+Generated by httpclient-gen-go from the service interfaces.
+
+## Services
+{{ range .Services }}
+- [{{ .Field }}](#{{ anchor .Field }}) ({{ code .Interface }})
+{{- end }}
+{{- range .Services }}
+
+## {{ .Field }}
+{{- if .Doc }}
+
+{{ .Doc }}
+{{- end }}
+
+Interface {{ code .Interface }}
+{{- if .BasePath }}, base path {{ code .BasePath }}{{ end }}
+{{- if .Error }}, error responses [{{ .Error }}](#{{ anchor .Error }}){{ end }}.
+
+| Method | Route | Description |
+| ------ | ----- | ----------- |
+{{- range .Methods }}
+| [{{ .Name }}](#{{ anchor $.Package }}-{{ anchor .Service }}-{{ anchor .Name }}) | {{ if .Route }}{{ code .Route }}{{ end }} | {{ summary .Doc }} |
+{{- end }}
+{{- range .Methods }}
+
+### <a name="{{ anchor $.Package }}-{{ anchor .Service }}-{{ anchor .Name }}"></a>{{ .Service }}.{{ .Name }}
+
+` + "```go" + `
+{{ .Signature }}
+` + "```" + `
+{{- if .Route }}
+
+Route: {{ code .Route }}
+{{- if .Query }}, query options: [{{ .Query }}](#{{ anchor .Query }}){{ end }}
+{{- end }}
+{{- if .Doc }}
+
+{{ .Doc }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Types }}
+
+## Types
+{{- range .Types }}
+
+### {{ .Name }}
+{{- if .Doc }}
+
+{{ .Doc }}
+{{- end }}
+
+| Field | JSON | Type | Description |
+| ----- | ---- | ---- | ----------- |
+{{- range .Fields }}
+| {{ .Name }} | {{ if .JSON }}{{ code .JSON }}{{ end }} | {{ code .Type }} | {{ summary .Doc }} |
+{{- end }}
+{{- end }}
+{{- end }}
+`
+
+// serviceDoc is the documentation of a service.
+type serviceDoc struct {
+	Field     string
+	Interface string
+	Doc       string
+	BasePath  string
+	Error     string
+	Methods   []methodDoc
+}
+
+// methodDoc is the documentation of a service method.
+type methodDoc struct {
+	Service   string
+	Name      string
+	Signature string
+	Route     string
+	Query     string
+	Doc       string
+}
+
+// typeDoc is the documentation of a struct type used by a service.
+type typeDoc struct {
+	Name   string
+	Doc    string
+	Fields []fieldDoc
+}
+
+// fieldDoc is the documentation of a struct field.
+type fieldDoc struct {
+	Name string
+	JSON string
+	Type string
+	Doc  string
+}
+
+// newServiceDoc returns the documentation of the service s with the interface type ts and the
+// struct types used by its methods.
+func newServiceDoc(s *Service, ts *ast.TypeSpec, doc *ast.CommentGroup, decls *declarations) (*serviceDoc, []typeDoc, error) {
+	d := &serviceDoc{
+		Field:     s.FieldName,
+		Interface: s.InterfaceName + s.TypeParams,
+		Doc:       strings.TrimSpace(doc.Text()),
+		BasePath:  s.BasePath,
+		Error:     s.Error,
+	}
+
+	fields, err := methodSet(ts.Type.(*ast.InterfaceType), decls, map[*ast.InterfaceType]bool{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	used := map[string]bool{}
+
+	if s.Error != "" {
+		usedTypes(ast.NewIdent(s.Error), decls, used)
+	}
+
+	for _, f := range fields {
+		m := methodDoc{
+			Service:   s.FieldName,
+			Name:      f.Names[0].Name,
+			Signature: f.Names[0].Name + strings.TrimPrefix(types.ExprString(f.Type), "func"),
+			Doc:       strings.TrimSpace(f.Doc.Text()),
+			Query:     directives(f.Doc)["query"],
+		}
+
+		if route, ok := directives(f.Doc)["route"]; ok {
+			m.Route = route
+			if s.BasePath != "" {
+				m.Route = withBasePath(route, s.BasePath)
+			}
+		}
+
+		usedTypes(f.Type, decls, used)
+		d.Methods = append(d.Methods, m)
+	}
+
+	docs := []typeDoc{}
+
+	for name := range used {
+		docs = append(docs, newTypeDoc(name, decls))
+	}
+
+	return d, docs, nil
+}
+
+// usedTypes adds the names of the struct types of this package used in e (and their fields)
+// to used.
+func usedTypes(e ast.Node, decls *declarations, used map[string]bool) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || used[ident.Name] {
+			return true
+		}
+
+		if st, ok := decls.structs[ident.Name]; ok {
+			used[ident.Name] = true
+			usedTypes(st, decls, used)
+		}
+
+		return true
+	})
+}
+
+// newTypeDoc returns the documentation of the struct type name.
+func newTypeDoc(name string, decls *declarations) typeDoc {
+	d := typeDoc{
+		Name: name,
+		Doc:  strings.TrimSpace(decls.docs[name].Text()),
+	}
+
+	for _, f := range decls.structs[name].Fields.List {
+		fd := fieldDoc{
+			Type: types.ExprString(f.Type),
+			Doc:  strings.TrimSpace(f.Doc.Text()),
+		}
+
+		if fd.Doc == "" {
+			fd.Doc = strings.TrimSpace(f.Comment.Text())
+		}
+
+		if f.Tag != nil {
+			if tag, err := strconv.Unquote(f.Tag.Value); err == nil {
+				fd.JSON = strings.Split(reflect.StructTag(tag).Get("json"), ",")[0]
+			}
+		}
+
+		names := []string{}
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+
+		if len(names) == 0 {
+			names = append(names, types.ExprString(f.Type)) // embedded
+		}
+
+		for _, n := range names {
+			fd.Name = n
+			if fd.JSON == "-" || !ast.IsExported(strings.TrimPrefix(n, "*")) {
+				continue
+			}
+
+			d.Fields = append(d.Fields, fd)
+		}
+	}
+
+	return d
+}
+
+// generateDocs returns the Markdown documentation (-docs) of the services.
+func generateDocs(data *Data) ([]byte, error) {
+	services := []serviceDoc{}
+	typeDocs := map[string]typeDoc{}
+
+	for _, s := range data.Services {
+		if s.docs == nil {
+			continue
+		}
+
+		services = append(services, *s.docs)
+
+		for _, t := range s.typeDocs {
+			typeDocs[t.Name] = t
+		}
+	}
+
+	names := []string{}
+	for n := range typeDocs {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	all := []typeDoc{}
+	for _, n := range names {
+		all = append(all, typeDocs[n])
+	}
+
+	t, err := template.New("Docs Template").Funcs(template.FuncMap{
+		"anchor":  anchor,
+		"code":    func(s string) string { return "`" + s + "`" },
+		"summary": summary,
+	}).Parse(docsTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = t.Execute(buf, struct {
+		Package  string
+		Services []serviceDoc
+		Types    []typeDoc
+	}{data.Package, services, all})
+	if err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// anchor returns the Markdown anchor for the heading s.
+func anchor(s string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "-", ".", "").Replace(s))
+}
+
+// summary returns the first line of the doc comment s for a table cell.
+func summary(s string) string {
+	s = strings.SplitN(s, "\n", 2)[0]
+
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,75 @@
+package gen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// templateFuncs are the functions available in the (custom) templates.
+// nolint: gochecknoglobals
+var templateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"title":      title,
+	"kebab":      kebab,
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+	"join":       func(sep string, a []string) string { return strings.Join(a, sep) },
+	"quote":      strconv.Quote,
+	"default":    func(def, s string) string { return defaultString(s, def) },
+	"comment":    comment,
+	"env":        os.Getenv,
+	"include":    includeFile,
+}
+
+// title returns s with the first letter in upper case.
+func title(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// defaultString returns s or def if s is empty.
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+
+	return s
+}
+
+// comment returns the lines of s as line comments, e.g. for license blocks.
+func comment(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("// "+l, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// includeFile returns the content of file, e.g. a license header.
+func includeFile(file string) (string, error) {
+	b, err := ioutil.ReadFile(file) // nolint: gosec // G304: file inclusion is intended
+	if err != nil {
+		return "", fmt.Errorf("could not include file: %w", err)
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,389 @@
+// Package gen generates the clients of service interfaces for the httpclient package. It is the
+// library behind httpclient-gen-go (see its documentation for the generated code and the
+// directives), for build tools and other generators embedding the generator:
+//
+//	data, err := gen.Scan(gen.Options{Package: "api", Paths: []string{"./api"}, Out: "api/httpclient.go", Impl: true})
+//	if err != nil {
+//		return err
+//	}
+//
+//	files, err := gen.Render(data)
+//	if err != nil {
+//		return err
+//	}
+//
+//	return gen.Write(files)
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Options are the options of the generator, they correspond to the flags of httpclient-gen-go.
+type Options struct {
+	// Package is the package name of the generated code (default: main).
+	Package string
+	// Paths are the directories to scan for services (default: .), a trailing /... scans the
+	// subdirectories too.
+	Paths []string
+	// Recursive scans all subdirectories of Paths.
+	Recursive bool
+	// Out is the file name of the generated client (default: httpclient.go).
+	Out string
+	// Suffixes are the suffixes of the service interface names (default: Service).
+	Suffixes []string
+	// Match is a regular expression for the service interface names, the first capture
+	// group is the service name. It takes precedence over Suffixes.
+	Match string
+	// Include and Exclude are glob patterns for the file or type names of the services.
+	Include []string
+	Exclude []string
+	// Services are the settings of the services by interface type name.
+	Services map[string]ServiceOptions
+
+	// Impl generates the Impl types of services with route annotations.
+	Impl bool
+	// Instrument wraps the services with decorators calling the client Instrumentation.
+	Instrument bool
+	// Validate type checks the packages and validates the Impl types.
+	Validate bool
+	// Timestamp adds the generation time to the generated code.
+	Timestamp bool
+	// Split renders the code of every service into a separate file (e.g. node_httpclient.go).
+	Split bool
+	// CLI, Docs and Plugins collect the commands, the documentation and the methods of the
+	// services, they are required for CLI, Docs and RunPlugin.
+	CLI     bool
+	Docs    bool
+	Plugins bool
+
+	// Template is a template file used instead of the embedded template and TemplateDir a
+	// directory with additional templates (*.tmpl).
+	Template    string
+	TemplateDir string
+	// Data are the key/value pairs passed to the templates as .Data.
+	Data map[string]string
+	// GoImports is the path to an external goimports tool (default: format in-process).
+	GoImports string
+	// ProtoProtocol is the RPC protocol (twirp or connect) of the services generated by Proto,
+	// which ignores the google.api.http annotations if it is set.
+	ProtoProtocol string
+}
+
+// ServiceOptions are the settings of a service. Directives in the source code take precedence.
+type ServiceOptions struct {
+	Field    string `yaml:"field"`
+	Error    string `yaml:"error"`
+	BasePath string `yaml:"basepath"`
+	Skip     bool   `yaml:"skip"`
+}
+
+// File is a file with generated code.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// withDefaults returns the options with the defaults of the empty settings.
+func (o Options) withDefaults() Options {
+	if o.Package == "" {
+		o.Package = "main"
+	}
+
+	if len(o.Paths) == 0 {
+		o.Paths = []string{"."}
+	}
+
+	if o.Out == "" {
+		o.Out = "httpclient.go"
+	}
+
+	if len(o.Suffixes) == 0 {
+		o.Suffixes = []string{"Service"}
+	}
+
+	return o
+}
+
+// suffix returns the first suffix, it is appended to the names of generated service interfaces.
+func (o Options) suffix() string {
+	return strings.TrimSpace(o.withDefaults().Suffixes[0])
+}
+
+// Dirs returns the directories scanned for services.
+func Dirs(opts Options) ([]string, error) {
+	opts = opts.withDefaults()
+	dirs := []string{}
+
+	for _, root := range opts.Paths {
+		root = strings.TrimSpace(root)
+
+		if !opts.Recursive && !strings.HasSuffix(root, "/...") {
+			dirs = append(dirs, root)
+			continue
+		}
+
+		d, err := packageDirs(strings.TrimSuffix(root, "/..."))
+		if err != nil {
+			return nil, err
+		}
+
+		dirs = append(dirs, d...)
+	}
+
+	return dirs, nil
+}
+
+// Scan returns the template data with the services declared in the directories of opts.
+func Scan(opts Options) (*Data, error) {
+	opts = opts.withDefaults()
+
+	data := &Data{
+		Path:    strings.Join(opts.Paths, ","),
+		Package: opts.Package,
+		Split:   opts.Split,
+		Data:    map[string]string{},
+		opts:    opts,
+	}
+
+	for k, v := range opts.Data {
+		data.Data[k] = v
+	}
+
+	matcher, err := newMatcher(strings.Join(opts.Suffixes, ","), opts.Match, strings.Join(opts.Include, ","), strings.Join(opts.Exclude, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err := Dirs(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	services := []Service{}
+
+	for _, dir := range dirs {
+		s, err := findServices(dir, matcher, &opts)
+		if err != nil {
+			return nil, err
+		}
+
+		services = append(services, s...)
+	}
+
+	// sort :-) - the order of the output and of the problems of validate must not depend on the
+	// order of the source files
+	sort.SliceStable(services, func(i, j int) bool {
+		if services[i].InterfaceName != services[j].InterfaceName {
+			return services[i].InterfaceName < services[j].InterfaceName
+		}
+
+		return services[i].TypeName < services[j].TypeName
+	})
+
+	fields := map[string]string{}
+
+	for _, s := range services {
+		if other, ok := fields[s.FieldName]; ok {
+			return nil, fmt.Errorf("services %s and %s have the same field name %s", other, s.InterfaceName, s.FieldName)
+		}
+
+		fields[s.FieldName] = s.InterfaceName
+	}
+
+	if opts.Validate {
+		if err := validate(services); err != nil {
+			return nil, err
+		}
+	}
+
+	data.Services = services
+	imports := map[string]bool{}
+
+	for _, s := range services {
+		data.Instrument = data.Instrument || s.Instrumented != ""
+		data.Decorate = data.Decorate || s.Instrumented != "" || s.Policies != ""
+
+		if s.ImportPath != "" && !imports[s.ImportPath] {
+			imports[s.ImportPath] = true
+			data.Imports = append(data.Imports, s.ImportPath)
+		}
+	}
+
+	sort.Strings(data.Imports)
+
+	if opts.Timestamp {
+		data.Timestamp = time.Now()
+	}
+
+	return data, nil
+}
+
+// Render returns the formatted generated code: the client and, with Split, a file per
+// service (e.g. node_httpclient.go for the output file httpclient.go).
+func Render(data *Data) ([]File, error) {
+	opts := data.opts.withDefaults()
+
+	t, err := loadTemplate(opts.Template, opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := t.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	out, err := format(opts.Out, buf.Bytes(), opts.GoImports)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []File{{opts.Out, out}}
+
+	if !data.Split {
+		return files, nil
+	}
+
+	st := t.Lookup("service file")
+	if st == nil {
+		return nil, errors.New(`split requires a template "service file"`)
+	}
+
+	for _, s := range data.Services {
+		if s.Error == "" && !s.Generate && len(s.Calls) == 0 {
+			continue // nothing to generate
+		}
+
+		buf.Reset()
+
+		err := st.Execute(buf, struct {
+			Timestamp time.Time
+			Package   string
+			Service   Service
+			Data      map[string]string
+		}{data.Timestamp, data.Package, s, data.Data})
+		if err != nil {
+			return nil, fmt.Errorf("could not render template: %w", err)
+		}
+
+		file := ServiceFile(opts.Out, s)
+
+		out, err := format(file, buf.Bytes(), opts.GoImports)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, File{file, out})
+	}
+
+	return files, nil
+}
+
+// ServiceFile returns the name of the file of service s with Split for the output file out.
+func ServiceFile(out string, s Service) string {
+	return filepath.Join(filepath.Dir(out), strings.ToLower(s.FieldName)+"_"+filepath.Base(out))
+}
+
+// Write writes the files.
+func Write(files []File) error {
+	for _, f := range files {
+		// nolint: gosec // generated code is not secret
+		if err := ioutil.WriteFile(f.Name, f.Content, 0o644); err != nil {
+			return fmt.Errorf("could not write output file %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Diff writes a unified diff between the content of the file f on disk and the generated
+// content to w and reports whether they differ. A missing file is treated as empty.
+func Diff(w io.Writer, f File) (bool, error) {
+	return diff(w, f.Name, f.Content)
+}
+
+// Tests returns a test file (e.g. node_client_test.go) with a test server and test skeletons
+// for every service with a generated Impl type in the directory of the output file. The
+// tests are meant to be edited, so do not overwrite existing files.
+func Tests(data *Data) ([]File, error) {
+	return generateTests(data, filepath.Dir(data.opts.withDefaults().Out))
+}
+
+// CLI returns the cobra command line client of the services (file is the output file).
+// It requires the option CLI.
+func CLI(data *Data, file string) (File, error) {
+	out, err := generateCLI(data, file)
+
+	return File{file, out}, err
+}
+
+// Server returns the fake HTTP server of the services with generated Impl types (file is the
+// output file).
+func Server(data *Data, file string) (File, error) {
+	out, err := generateServer(data, file)
+
+	return File{file, out}, err
+}
+
+// Docs returns the Markdown documentation of the services (file is the output file). It
+// requires the option Docs.
+func Docs(data *Data, file string) (File, error) {
+	out, err := generateDocs(data)
+
+	return File{file, out}, err
+}
+
+// Models returns the models with Validate methods generated from the JSON Schema files (or
+// directories with *.json files) in paths (file is the output file).
+func Models(paths []string, file string, opts Options) (File, error) {
+	opts = opts.withDefaults()
+	out, err := generateModels(paths, file, opts.Package, opts.GoImports)
+
+	return File{file, out}, err
+}
+
+// Proto returns the service interfaces (with route directives) and the messages generated
+// from the .proto files (or directories with *.proto files) in paths with google.api.http
+// annotations or for the RPC protocol of the options (file is the output file).
+func Proto(paths []string, file string, opts Options) (File, error) {
+	out, err := generateProto(paths, file, opts.withDefaults())
+
+	return File{file, out}, err
+}
+
+// Postman returns the service interfaces (with route directives) and the example structs
+// generated from the Postman collection (file is the output file).
+func Postman(collection, file string, opts Options) (File, error) {
+	out, err := generatePostman(collection, file, opts.withDefaults())
+
+	return File{file, out}, err
+}
+
+// Interfaces returns the service interfaces for the Impl types in the single directory of
+// opts.Paths (e.g. NodeService for NodeImpl with the first suffix) written to opts.Out.
+func Interfaces(opts Options) (File, error) {
+	opts = opts.withDefaults()
+
+	if len(opts.Paths) != 1 || strings.HasSuffix(opts.Paths[0], "/...") || opts.Recursive {
+		return File{}, errors.New("reverse requires a single directory as path")
+	}
+
+	suffix := opts.suffix()
+	if suffix == "" {
+		return File{}, errors.New("suffix cannot be empty")
+	}
+
+	out, err := reverse(opts.Paths[0], suffix, opts.Out, opts.GoImports)
+
+	return File{opts.Out, out}, err
+}
@@ -0,0 +1,374 @@
+package gen
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testService = `package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "api.go"), []byte(testService), 0o600))
+
+	opts := Options{
+		Package: "api",
+		Paths:   []string{dir},
+		Out:     filepath.Join(dir, "httpclient.go"),
+		Impl:    true,
+	}
+
+	t.Run("scan", func(t *testing.T) {
+		data, err := Scan(opts)
+		assert.Nil(t, err)
+		assert.Equal(t, "api", data.Package)
+		assert.Len(t, data.Services, 1)
+		assert.Equal(t, "Node", data.Services[0].FieldName)
+		assert.True(t, data.Services[0].Generate)
+	})
+
+	t.Run("render and write", func(t *testing.T) {
+		data, err := Scan(opts)
+		assert.Nil(t, err)
+
+		files, err := Render(data)
+		assert.Nil(t, err)
+		assert.Len(t, files, 1)
+		assert.Equal(t, opts.Out, files[0].Name)
+		assert.True(t, strings.Contains(string(files[0].Content), "type NodeImpl struct"))
+		assert.True(t, strings.Contains(string(files[0].Content), `s.client.NewRequest(http.MethodGet, "nodes/"+url.PathEscape(id), nil)`))
+
+		assert.Nil(t, Write(files))
+
+		b, err := ioutil.ReadFile(opts.Out)
+		assert.Nil(t, err)
+		assert.Equal(t, files[0].Content, b)
+
+		changed, err := Diff(ioutil.Discard, files[0])
+		assert.Nil(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("split", func(t *testing.T) {
+		o := opts
+		o.Split = true
+
+		data, err := Scan(o)
+		assert.Nil(t, err)
+
+		files, err := Render(data)
+		assert.Nil(t, err)
+		assert.Len(t, files, 2)
+		assert.Equal(t, filepath.Join(dir, "node_httpclient.go"), files[1].Name)
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		o := opts
+		o.Timestamp = true
+
+		data, err := Scan(o)
+		assert.Nil(t, err)
+		assert.False(t, data.Timestamp.IsZero())
+
+		files, err := Render(data)
+		assert.Nil(t, err)
+		assert.Contains(t, string(files[0].Content), "// This file was generated by robots at\n// "+data.Timestamp.String())
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		o := Options{}.withDefaults()
+		assert.Equal(t, "main", o.Package)
+		assert.Equal(t, []string{"."}, o.Paths)
+		assert.Equal(t, "httpclient.go", o.Out)
+		assert.Equal(t, "Service", o.suffix())
+	})
+
+	t.Run("duplicate field", func(t *testing.T) {
+		_, err := Scan(Options{
+			Package:  "api",
+			Paths:    []string{"testdata/duplicate"},
+			Out:      "testdata/duplicate/httpclient.go",
+			Suffixes: []string{"Service", "API"},
+		})
+		assert.EqualError(t, err, "services NodeAPI and NodeService have the same field name Node")
+	})
+
+	t.Run("package name of another directory", func(t *testing.T) {
+		_, err := Scan(Options{Package: "api", Paths: []string{"testdata/recursive/..."}, Out: filepath.Join(dir, "httpclient.go")})
+		assert.EqualError(t, err, "testdata/recursive: package api is not in the directory of "+filepath.Join(dir, "httpclient.go")+" and cannot be imported by package api")
+	})
+
+	t.Run("invalid match", func(t *testing.T) {
+		o := opts
+		o.Match = "("
+
+		_, err := Scan(o)
+		assert.NotNil(t, err)
+	})
+}
+
+// update rewrites the golden files with the generated code: go test ./gen -update
+// nolint: gochecknoglobals
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// render scans the services of opts and renders the client.
+func render(opts Options) ([]File, error) {
+	data, err := Scan(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return Render(data)
+}
+
+// generate scans the services of opts and generates the file name with fn in the directory
+// of the output file.
+func generate(name string, fn func(*Data, string) (File, error)) func(Options) ([]File, error) {
+	return func(opts Options) ([]File, error) {
+		data, err := Scan(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := fn(data, filepath.Join(filepath.Dir(opts.Out), name))
+
+		return []File{f}, err
+	}
+}
+
+// proto generates the services from the .proto files in the directory of the output file.
+func proto(opts Options) ([]File, error) {
+	f, err := Proto([]string{filepath.Dir(opts.Out)}, filepath.Join(filepath.Dir(opts.Out), "proto.go"), opts)
+
+	return []File{f}, err
+}
+
+// postman generates the services of the Postman collection in the directory of the output file.
+func postman(opts Options) ([]File, error) {
+	dir := filepath.Dir(opts.Out)
+	f, err := Postman(filepath.Join(dir, "collection.json"), filepath.Join(dir, "postman.go"), opts)
+
+	return []File{f}, err
+}
+
+func TestGolden(t *testing.T) {
+	tt := []struct {
+		name string // directory in testdata with the sources and the golden files
+		opts Options
+		gen  func(Options) ([]File, error)
+	}{
+		{"recursive", Options{Recursive: true, Impl: true, Validate: true}, render},
+		{"suffixes", Options{Suffixes: []string{"Service", "API"}, Impl: true}, render},
+		{"match", Options{Match: `^(?:(\w+)Endpoint|Admin)$`, Impl: true}, render},
+		{"order", Options{Impl: true}, render},
+		{"format", Options{Template: "testdata/format/client.tmpl"}, render},
+		{"impl", Options{Impl: true}, render},
+		{"routes", Options{Impl: true}, render},
+		{"paginate", Options{Impl: true}, render},
+		{"error", Options{Impl: true}, render},
+		{"basepath", Options{Impl: true}, render},
+		{"embedded", Options{Impl: true}, render},
+		{"generic", Options{Impl: true}, render},
+		{"filter", Options{Include: []string{"Node*", "Mock*"}, Exclude: []string{"*_test.go", "Mock*"}, Impl: true}, render},
+		{"services", Options{Impl: true, Services: map[string]ServiceOptions{
+			"NodeService":   {Field: "Nodes", Error: "APIError", BasePath: "/v2"},
+			"PostService":   {BasePath: "/v1"},
+			"LegacyService": {Skip: true},
+		}}, render},
+		{"reverse", Options{}, func(o Options) ([]File, error) {
+			f, err := Interfaces(o)
+			return []File{f}, err
+		}},
+		{"cli", Options{CLI: true}, generate("cli.go", CLI)},
+		{"instrument", Options{Impl: true, Instrument: true}, render},
+		{"policies", Options{Impl: true}, render},
+		{"scaffold", Options{Impl: true}, func(o Options) ([]File, error) {
+			data, err := Scan(o)
+			if err != nil {
+				return nil, err
+			}
+
+			return Tests(data)
+		}},
+		{"server", Options{Impl: true}, generate("server.go", Server)},
+		{"docs", Options{Impl: true, Docs: true}, generate("API.md", Docs)},
+		{"split", Options{Impl: true, Split: true}, render},
+		{"funcs", Options{Template: "testdata/funcs/client.tmpl", Data: map[string]string{"version": "1.2.3", "scopes": "read,write"}}, render},
+		{"schema", Options{}, func(o Options) ([]File, error) {
+			f, err := Models([]string{filepath.Dir(o.Out)}, filepath.Join(filepath.Dir(o.Out), "models.go"), o)
+			return []File{f}, err
+		}},
+		{"proto", Options{}, proto},
+		{"twirp", Options{ProtoProtocol: "twirp"}, proto},
+		{"postman", Options{}, postman},
+		{"template", Options{Template: "testdata/template/client.tmpl"}, render},
+		{"template-dir", Options{TemplateDir: "testdata/template-dir/templates"}, render},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", tc.name)
+
+			opts := tc.opts
+			opts.Package = "api"
+
+			if len(opts.Paths) == 0 {
+				opts.Paths = []string{dir}
+			}
+
+			opts.Out = filepath.Join(dir, "httpclient.go")
+
+			files, err := tc.gen(opts)
+			assert.Nil(t, err)
+
+			// the output must not depend on the run
+			again, err := tc.gen(opts)
+			assert.Nil(t, err)
+			assert.Equal(t, files, again)
+
+			for _, f := range files {
+				assertGolden(t, f.Name+".golden", f.Content)
+			}
+		})
+	}
+
+	t.Run("diff", func(t *testing.T) {
+		files, err := render(Options{
+			Package:  "api",
+			Paths:    []string{"testdata/format"},
+			Out:      "testdata/format/httpclient.go",
+			Template: "testdata/format/client.tmpl",
+		})
+		assert.Nil(t, err)
+
+		buf := new(bytes.Buffer)
+
+		changed, err := Diff(buf, File{"testdata/diff/httpclient.go", files[0].Content})
+		assert.Nil(t, err)
+		assert.True(t, changed)
+
+		assertGolden(t, "testdata/diff/httpclient.go.diff.golden", buf.Bytes())
+	})
+}
+
+// assertGolden compares content with the golden file or, with -update, writes it.
+func assertGolden(t *testing.T, golden string, content []byte) {
+	t.Helper()
+
+	if *update {
+		assert.Nil(t, ioutil.WriteFile(golden, content, 0o600))
+		return
+	}
+
+	b, err := ioutil.ReadFile(golden)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), string(content), golden)
+}
+
+const testProto = `syntax = "proto3";
+
+package example.library;
+
+service Library {
+  rpc GetBook(GetBookRequest) returns (Book) {
+    option (google.api.http) = { get: "/v1/books/{id}" };
+  }
+  rpc DeleteBook(GetBookRequest) returns (google.protobuf.Empty);
+}
+
+message GetBookRequest {
+  int64 id = 1;
+}
+
+message Book {
+  int64 id = 1;
+  string title = 2;
+}
+`
+
+func TestProto(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	proto := filepath.Join(dir, "library.proto")
+	assert.Nil(t, ioutil.WriteFile(proto, []byte(testProto), 0o600))
+
+	opts := Options{Package: "api"}
+
+	t.Run("google.api.http", func(t *testing.T) {
+		f, err := Proto([]string{proto}, filepath.Join(dir, "proto.go"), opts)
+		assert.Nil(t, err)
+
+		code := string(f.Content)
+		assert.Contains(t, code, "//httpclient:route GET /v1/books/{id}\n\tGetBook(ctx context.Context, id int64) (*Book, *http.Response, error)")
+		assert.NotContains(t, code, "DeleteBook")
+		assert.NotContains(t, code, "//httpclient:error")
+	})
+
+	t.Run("twirp", func(t *testing.T) {
+		o := opts
+		o.ProtoProtocol = "twirp"
+
+		f, err := Proto([]string{proto}, filepath.Join(dir, "proto.go"), o)
+		assert.Nil(t, err)
+
+		code := string(f.Content)
+		assert.Contains(t, code, "//httpclient:basepath /twirp\n//httpclient:error TwirpStatus\ntype LibraryService interface")
+		assert.Contains(t, code, "//httpclient:route POST /example.library.Library/GetBook\n\tGetBook(ctx context.Context, req *GetBookRequest) (*Book, *http.Response, error)")
+		assert.Contains(t, code, "//httpclient:route POST /example.library.Library/DeleteBook\n\tDeleteBook(ctx context.Context, req *GetBookRequest) (*http.Response, error)")
+		assert.Contains(t, code, "type TwirpStatus struct")
+	})
+
+	t.Run("connect", func(t *testing.T) {
+		o := opts
+		o.ProtoProtocol = "connect"
+
+		f, err := Proto([]string{proto}, filepath.Join(dir, "proto.go"), o)
+		assert.Nil(t, err)
+
+		code := string(f.Content)
+		assert.NotContains(t, code, "//httpclient:basepath")
+		assert.Contains(t, code, "//httpclient:error ConnectStatus\ntype LibraryService interface")
+		assert.Contains(t, code, "//httpclient:route POST /example.library.Library/GetBook\n")
+		assert.Contains(t, code, "type ConnectStatus struct")
+	})
+
+	t.Run("unknown protocol", func(t *testing.T) {
+		o := opts
+		o.ProtoProtocol = "grpc"
+
+		_, err := Proto([]string{proto}, filepath.Join(dir, "proto.go"), o)
+		assert.EqualError(t, err, "unknown protocol grpc")
+	})
+}
@@ -0,0 +1,535 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// directivePrefix is the prefix of comment directives for the generator.
+const directivePrefix = "//httpclient:"
+
+// nolint: gochecknoglobals
+var (
+	// placeholder matches path placeholders like {id}.
+	placeholder = regexp.MustCompile(`{(\w+)}`)
+
+	// reserved are the names of variables and packages used in generated methods.
+	reserved = map[string]bool{
+		"s": true, "u": true, "v": true, "req": true, "resp": true, "err": true,
+		"fmt": true, "url": true, "http": true, "httpclient": true, "context": true,
+	}
+)
+
+// Method contains all information to generate a service method.
+type Method struct {
+	Name      string
+	Signature string // e.g. Get(ctx context.Context, id int) (*Post, *http.Response, error)
+	Context   string // name of the context parameter
+	Verb      string // http method expression, e.g. http.MethodGet
+	Route     string // route as annotated, e.g. GET /posts/{id}
+	Path      string // path expression
+	Query     string // query options expression, empty if there are none
+	Body      string // body expression
+	Result    string // type of the decoded value, empty if there is none
+	Value     string // declaration of the variable v the response is decoded into
+	ValueArg  string // v or &v
+	Zero      string // zero value of Result
+
+	// Paginate is set for methods annotated with a paginate directive.
+	Paginate *Pagination
+
+	params []param
+}
+
+// Pagination contains all information to generate an iterator over the pages of a method.
+type Pagination struct {
+	Iterator   string // type name of the iterator, e.g. NodeListIterator
+	Field      string // field name of the service in the Client type
+	Impl       string // type name of the service implementation
+	Params     string // parameters of the iterator constructor (without context)
+	Item       string // type of the items
+	Link       bool   // next page URL in the Link header
+	TokenField string // field of the page with the token for the next page
+	TokenParam string // query parameter for the token
+	ItemsField string // field of the page with the items
+
+	// Instrumented and Policies are the type names of the decorators of the service.
+	Instrumented string
+	Policies     string
+}
+
+// param is a parameter of a service method.
+type param struct {
+	Name string
+	Type string
+}
+
+// directives returns the generator directives (//httpclient:<name> <value>) in doc.
+func directives(doc *ast.CommentGroup) map[string]string {
+	d := map[string]string{}
+
+	if doc == nil {
+		return d
+	}
+
+	for _, c := range doc.List {
+		if !strings.HasPrefix(c.Text, directivePrefix) {
+			continue
+		}
+
+		kv := strings.SplitN(strings.TrimPrefix(c.Text, directivePrefix), " ", 2)
+		if len(kv) == 1 {
+			kv = append(kv, "")
+		}
+
+		d[kv[0]] = strings.TrimSpace(kv[1])
+	}
+
+	return d
+}
+
+// directiveList returns the values of all generator directives name in doc.
+func directiveList(doc *ast.CommentGroup, name string) []string {
+	values := []string{}
+
+	if doc == nil {
+		return values
+	}
+
+	for _, c := range doc.List {
+		if v := strings.TrimPrefix(c.Text, directivePrefix+name); v != c.Text && (v == "" || v[0] == ' ') {
+			values = append(values, strings.TrimSpace(v))
+		}
+	}
+
+	return values
+}
+
+// addMethods adds the methods of the interface type it to the service. The Impl type is only
+// generated if the methods are annotated with routes and pkg is the local package.
+func (s *Service) addMethods(pkg string, local bool, it *ast.InterfaceType, decls *declarations) error {
+	methods := []Method{}
+	missing := []string{}
+
+	fields, err := methodSet(it, decls, map[*ast.InterfaceType]bool{})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		ft := f.Type.(*ast.FuncType)
+
+		route, ok := directives(f.Doc)["route"]
+		if !ok {
+			missing = append(missing, f.Names[0].Name)
+			continue
+		}
+
+		if s.BasePath != "" {
+			route = withBasePath(route, s.BasePath)
+		}
+
+		m, err := newMethod(f.Names[0].Name, ft, route, directives(f.Doc)["query"])
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Names[0].Name, err)
+		}
+
+		if p, ok := directives(f.Doc)["paginate"]; ok {
+			m.Paginate, err = newPagination(s.FieldName+m.Name+"Iterator", p, ft, decls.structs)
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.Names[0].Name, err)
+			}
+
+			if m.Verb != "http.MethodGet" || m.Body != "nil" {
+				return fmt.Errorf("%s: only GET requests without body can be paginated", f.Names[0].Name)
+			}
+
+			m.Paginate.Params = paramList(m.params[1:])
+			m.Paginate.Field = s.FieldName
+			m.Paginate.Impl = s.TypeName
+		}
+
+		if s.typeParams[m.Result] {
+			m.Zero = fmt.Sprintf("*new(%s)", m.Result) // type parameter
+		}
+
+		methods = append(methods, m)
+	}
+
+	if len(methods) == 0 {
+		return nil
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing route annotation for %s", strings.Join(missing, ", "))
+	}
+
+	if !local {
+		return fmt.Errorf("Impl types can only be generated in package %s", pkg)
+	}
+
+	s.Generate = true
+	s.Methods = methods
+
+	return nil
+}
+
+// methodSet returns the methods of the interface type it including the methods of embedded
+// interfaces declared in the same package. Methods embedded more than once are only
+// returned once.
+func methodSet(it *ast.InterfaceType, decls *declarations, seen map[*ast.InterfaceType]bool) ([]*ast.Field, error) {
+	if seen[it] {
+		return nil, nil
+	}
+
+	seen[it] = true
+	fields := []*ast.Field{}
+	names := map[string]bool{}
+
+	add := func(f *ast.Field) {
+		if !names[f.Names[0].Name] {
+			names[f.Names[0].Name] = true
+			fields = append(fields, f)
+		}
+	}
+
+	for _, f := range it.Methods.List {
+		if _, ok := f.Type.(*ast.FuncType); ok && len(f.Names) > 0 {
+			add(f)
+			continue
+		}
+
+		ident, ok := f.Type.(*ast.Ident)
+		if !ok || decls.ifaces[ident.Name] == nil {
+			return nil, fmt.Errorf("embedded interface %s must be declared in the same package", types.ExprString(f.Type))
+		}
+
+		embedded, err := methodSet(decls.ifaces[ident.Name], decls, seen)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ident.Name, err)
+		}
+
+		for _, e := range embedded {
+			add(e)
+		}
+	}
+
+	return fields, nil
+}
+
+// newMethod returns the method for the function type ft annotated with route (e.g. GET /posts/{id})
+// and the type of the query options (if any).
+// nolint: funlen, gocyclo, gocognit
+func newMethod(name string, ft *ast.FuncType, route, query string) (Method, error) {
+	m := Method{
+		Name:  name,
+		Route: route,
+	}
+
+	fields := strings.Fields(route)
+	if len(fields) != 2 {
+		return m, fmt.Errorf("invalid route %q", route)
+	}
+
+	verb, path := strings.ToUpper(fields[0]), fields[1]
+	m.Verb = verbExpr(verb)
+
+	params := []param{}
+	unnamed := map[int]bool{}
+
+	for i, f := range ft.Params.List {
+		typ := types.ExprString(f.Type)
+
+		if len(f.Names) == 0 {
+			name := fmt.Sprintf("arg%d", i)
+			if i == 0 {
+				name = "ctx"
+			}
+
+			unnamed[len(params)] = true
+
+			params = append(params, param{Name: name, Type: typ})
+
+			continue
+		}
+
+		for _, n := range f.Names {
+			params = append(params, param{Name: n.Name, Type: typ})
+		}
+	}
+
+	if len(params) == 0 || params[0].Type != "context.Context" {
+		return m, errors.New("first parameter must be a context.Context")
+	}
+
+	if params[0].Name == "_" {
+		params[0].Name = "ctx"
+	}
+
+	// path parameters: placeholders are bound to the parameter with the same name or, for unnamed
+	// parameters, in order of appearance
+	bound := map[string]int{}
+	used := map[int]bool{}
+
+	for _, n := range placeholder.FindAllStringSubmatch(path, -1) {
+		for i := 1; i < len(params); i++ {
+			if params[i].Name == n[1] && !unnamed[i] {
+				bound[n[1]] = i
+			}
+		}
+
+		for i := 1; i < len(params) && bound[n[1]] == 0; i++ {
+			if unnamed[i] {
+				params[i].Name = n[1]
+				unnamed[i] = false
+				bound[n[1]] = i
+			}
+		}
+
+		if bound[n[1]] == 0 {
+			return m, fmt.Errorf("no parameter for path placeholder %s", n[0])
+		}
+
+		used[bound[n[1]]] = true
+	}
+
+	// parameters must not shadow the variables and packages used by the generated code
+	for i := range params {
+		if reserved[params[i].Name] {
+			params[i].Name += "Param"
+		}
+	}
+
+	m.Context = params[0].Name
+	m.Path = pathExpr(relativePath(path), params, bound)
+
+	// query options
+	if query != "" {
+		for i, p := range params[1:] {
+			if !used[i+1] && (p.Type == query || p.Type == "*"+query) {
+				m.Query = p.Name
+				used[i+1] = true
+
+				break
+			}
+		}
+
+		if m.Query == "" {
+			return m, fmt.Errorf("no parameter of type %s for query options", query)
+		}
+	}
+
+	// body
+	m.Body = "nil"
+
+	for i, p := range params[1:] {
+		if used[i+1] {
+			continue
+		}
+
+		if m.Body != "nil" {
+			return m, fmt.Errorf("parameter %s is neither a path parameter nor the body", p.Name)
+		}
+
+		m.Body = p.Name
+	}
+
+	if m.Body != "nil" && (verb == http.MethodGet || verb == http.MethodHead) {
+		return m, fmt.Errorf("%s requests have no body, parameter %s is not used", verb, m.Body)
+	}
+
+	// results
+	results := []string{}
+
+	if ft.Results != nil {
+		for _, f := range ft.Results.List {
+			for i := 0; i < len(f.Names) || i == 0; i++ {
+				results = append(results, types.ExprString(f.Type))
+			}
+		}
+	}
+
+	n := len(results)
+	if n < 2 || n > 3 || results[n-1] != "error" || results[n-2] != "*http.Response" {
+		return m, errors.New("results must be (T, *http.Response, error) or (*http.Response, error)")
+	}
+
+	if n == 3 {
+		m.Result = results[0]
+		m.Zero = zeroValue(ft.Results.List[0].Type)
+		m.Value = fmt.Sprintf("var v %s", m.Result)
+		m.ValueArg = "&v"
+
+		if star, ok := ft.Results.List[0].Type.(*ast.StarExpr); ok {
+			m.Value = fmt.Sprintf("v := new(%s)", types.ExprString(star.X))
+			m.ValueArg = "v"
+		}
+	}
+
+	m.params = params
+	m.Signature = fmt.Sprintf("%s(%s) (%s)", name, paramList(params), strings.Join(results, ", "))
+
+	return m, nil
+}
+
+// newPagination returns the pagination of a method with function type ft for the paginate directive
+// value mode, which is either
+//
+//	link							next page in the Link header, the method must return a slice
+//	token <field> <param> <items>	the page (struct) returned by the method contains the token for
+//									the next page in <field>, which is sent as query parameter
+//									<param>, and the items in <items>
+func newPagination(iterator, mode string, ft *ast.FuncType, structs map[string]*ast.StructType) (*Pagination, error) {
+	p := &Pagination{
+		Iterator: iterator,
+	}
+
+	if ft.Results == nil || len(ft.Results.List) != 3 {
+		return nil, errors.New("paginated methods must return (T, *http.Response, error)")
+	}
+
+	result := ft.Results.List[0].Type
+	args := strings.Fields(mode)
+
+	switch {
+	case len(args) == 1 && args[0] == "link":
+		slice, ok := result.(*ast.ArrayType)
+		if !ok || slice.Len != nil {
+			return nil, errors.New("paginated methods with link mode must return a slice")
+		}
+
+		p.Link = true
+		p.Item = types.ExprString(slice.Elt)
+	case len(args) == 4 && args[0] == "token":
+		p.TokenField, p.TokenParam, p.ItemsField = args[1], args[2], args[3]
+
+		if star, ok := result.(*ast.StarExpr); ok {
+			result = star.X
+		}
+
+		st, ok := structs[types.ExprString(result)]
+		if !ok {
+			return nil, fmt.Errorf("page type %s is not a struct of this package", types.ExprString(result))
+		}
+
+		for _, f := range st.Fields.List {
+			for _, n := range f.Names {
+				if slice, ok := f.Type.(*ast.ArrayType); ok && n.Name == p.ItemsField {
+					p.Item = types.ExprString(slice.Elt)
+				}
+			}
+		}
+
+		if p.Item == "" {
+			return nil, fmt.Errorf("page type %s has no slice field %s", types.ExprString(result), p.ItemsField)
+		}
+	default:
+		return nil, fmt.Errorf("invalid paginate mode %q", mode)
+	}
+
+	return p, nil
+}
+
+// withBasePath returns the route (e.g. GET /posts) with the path prefixed by base.
+func withBasePath(route, base string) string {
+	fields := strings.Fields(route)
+	if len(fields) != 2 {
+		return route // reported by newMethod
+	}
+
+	return fields[0] + " " + strings.TrimRight(base, "/") + "/" + strings.TrimLeft(fields[1], "/")
+}
+
+// relativePath returns the path without its leading slash, so it is resolved relative to the
+// BaseURL of the client (see httpclient.Client.NewRequest). A first segment with a placeholder or
+// a colon is prefixed with ./ to not be parsed as scheme.
+func relativePath(path string) string {
+	path = strings.TrimPrefix(path, "/")
+
+	if first := strings.SplitN(path, "/", 2)[0]; strings.ContainsAny(first, "{:") {
+		path = "./" + path
+	}
+
+	return path
+}
+
+// pathExpr returns the go expression for path with its placeholders replaced by the escaped
+// bound parameters, e.g. "posts/" + url.PathEscape(fmt.Sprint(id)).
+func pathExpr(path string, params []param, bound map[string]int) string {
+	parts := []string{}
+	last := 0
+
+	for _, loc := range placeholder.FindAllStringSubmatchIndex(path, -1) {
+		if loc[0] > last {
+			parts = append(parts, strconv.Quote(path[last:loc[0]]))
+		}
+
+		p := params[bound[path[loc[2]:loc[3]]]]
+		if p.Type == "string" {
+			parts = append(parts, fmt.Sprintf("url.PathEscape(%s)", p.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("url.PathEscape(fmt.Sprint(%s))", p.Name))
+		}
+
+		last = loc[1]
+	}
+
+	if last < len(path) || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(path[last:]))
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+// paramList returns the parameter list for a function declaration.
+func paramList(params []param) string {
+	l := make([]string, 0, len(params))
+	for _, p := range params {
+		l = append(l, p.Name+" "+p.Type)
+	}
+
+	return strings.Join(l, ", ")
+}
+
+// verbExpr returns the net/http constant for the http method verb if there is one.
+func verbExpr(verb string) string {
+	switch verb {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return "http.Method" + verb[:1] + strings.ToLower(verb[1:])
+	default:
+		return strconv.Quote(verb)
+	}
+}
+
+// zeroValue returns the zero value for the type expression e.
+func zeroValue(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		if a, ok := t.(*ast.ArrayType); ok && a.Len != nil {
+			return types.ExprString(e) + "{}"
+		}
+
+		return "nil"
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return `""`
+		case "bool":
+			return "false"
+		case "error", "any":
+			return "nil"
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+			"uintptr", "float32", "float64", "complex64", "complex128", "byte", "rune":
+			return "0"
+		}
+	}
+
+	return types.ExprString(e) + "{}"
+}
@@ -0,0 +1,188 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+	"time"
+)
+
+// Call contains all information to generate a method of a decorated (instrumented or
+// with policies) service.
+type Call struct {
+	Name      string
+	Signature string // e.g. Get(ctx context.Context, id int) (*Post, *http.Response, error)
+	Context   string // name of the context parameter
+	Args      string // arguments passed to the method, e.g. ctx, id
+	Result    string // type of the returned value, empty if there is none
+
+	// Timeout (duration expression, e.g. 5 * time.Second, and text, e.g. 5s) and Retry are the
+	// policies of the method (//httpclient:timeout and //httpclient:retry directives).
+	Timeout     string
+	TimeoutText string
+	Retry       bool
+}
+
+// decoratorReserved are the names of the variables used in the generated decorator methods.
+// nolint: gochecknoglobals
+var decoratorReserved = map[string]bool{
+	"s": true, "v": true, "resp": true, "err": true, "end": true, "cancel": true,
+	"http": true, "httpclient": true, "context": true, "time": true,
+}
+
+// newCalls returns the calls for the methods of the interface type it.
+func newCalls(it *ast.InterfaceType, decls *declarations) ([]Call, error) {
+	fields, err := methodSet(it, decls, map[*ast.InterfaceType]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	calls := []Call{}
+
+	for _, f := range fields {
+		c, err := newCall(f.Names[0].Name, f.Type.(*ast.FuncType), directives(f.Doc))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Names[0].Name, err)
+		}
+
+		calls = append(calls, c)
+	}
+
+	return calls, nil
+}
+
+// hasPolicies reports whether a method of the interface type it is annotated with a
+// timeout or retry directive.
+func hasPolicies(it *ast.InterfaceType, decls *declarations) bool {
+	fields, err := methodSet(it, decls, map[*ast.InterfaceType]bool{})
+	if err != nil {
+		return false // reported later
+	}
+
+	for _, f := range fields {
+		d := directives(f.Doc)
+		if _, ok := d["timeout"]; ok {
+			return true
+		}
+
+		if _, ok := d["retry"]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newCall returns the call for the method name with function type ft and directives d.
+// nolint: funlen, gocyclo
+func newCall(name string, ft *ast.FuncType, d map[string]string) (Call, error) {
+	c := Call{Name: name}
+
+	if t, ok := d["timeout"]; ok {
+		timeout, err := time.ParseDuration(t)
+		if err != nil || timeout <= 0 {
+			return c, fmt.Errorf("invalid timeout %q", t)
+		}
+
+		c.Timeout = durationExpr(timeout)
+		c.TimeoutText = timeout.String()
+	}
+
+	if r, ok := d["retry"]; ok {
+		if r != "idempotent" {
+			return c, fmt.Errorf("invalid retry mode %q (only idempotent methods can be retried)", r)
+		}
+
+		c.Retry = true
+	}
+
+	params := []param{}
+	variadic := false
+
+	for i, f := range ft.Params.List {
+		typ := types.ExprString(f.Type)
+		_, variadic = f.Type.(*ast.Ellipsis)
+
+		if len(f.Names) == 0 {
+			params = append(params, param{Name: fmt.Sprintf("arg%d", i), Type: typ})
+			continue
+		}
+
+		for _, n := range f.Names {
+			params = append(params, param{Name: n.Name, Type: typ})
+		}
+	}
+
+	if len(params) == 0 || params[0].Type != "context.Context" {
+		return c, errors.New("first parameter must be a context.Context")
+	}
+
+	args := []string{}
+
+	for i := range params {
+		if i == 0 && (params[i].Name == "_" || params[i].Name == "arg0") {
+			params[i].Name = "ctx"
+		}
+
+		if params[i].Name == "_" {
+			params[i].Name = fmt.Sprintf("arg%d", i)
+		}
+
+		if decoratorReserved[params[i].Name] {
+			params[i].Name += "Param"
+		}
+
+		args = append(args, params[i].Name)
+	}
+
+	if variadic {
+		args[len(args)-1] += "..."
+	}
+
+	results := []string{}
+
+	if ft.Results != nil {
+		for _, f := range ft.Results.List {
+			for i := 0; i < len(f.Names) || i == 0; i++ {
+				results = append(results, types.ExprString(f.Type))
+			}
+		}
+	}
+
+	n := len(results)
+	if n < 2 || n > 3 || results[n-1] != "error" || results[n-2] != "*http.Response" {
+		return c, errors.New("results must be (T, *http.Response, error) or (*http.Response, error)")
+	}
+
+	if n == 3 {
+		c.Result = results[0]
+	}
+
+	c.Context = params[0].Name
+	c.Args = strings.Join(args, ", ")
+	c.Signature = fmt.Sprintf("%s(%s) (%s)", name, paramList(params), strings.Join(results, ", "))
+
+	return c, nil
+}
+
+// durationExpr returns the go expression for d, e.g. 5 * time.Second.
+func durationExpr(d time.Duration) string {
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "Hour"},
+		{time.Minute, "Minute"},
+		{time.Second, "Second"},
+		{time.Millisecond, "Millisecond"},
+		{time.Microsecond, "Microsecond"},
+	} {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * time.%s", d/u.unit, u.name)
+		}
+	}
+
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
@@ -0,0 +1,193 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PluginVersion is the version of the plugin protocol, it is incremented on incompatible
+// changes of PluginRequest and PluginResponse.
+const PluginVersion = 1
+
+// Plugin generates additional files from the services, e.g. mocks, Terraform definitions or
+// documentation.
+type Plugin interface {
+	Generate(req *PluginRequest) ([]File, error)
+}
+
+// PluginFunc is a function implementing Plugin.
+type PluginFunc func(req *PluginRequest) ([]File, error)
+
+// Generate implements Plugin.
+func (f PluginFunc) Generate(req *PluginRequest) ([]File, error) {
+	return f(req)
+}
+
+// PluginRequest is passed to plugins, external plugins read it as JSON from stdin.
+type PluginRequest struct {
+	Version   int               `json:"version"`
+	Parameter string            `json:"parameter,omitempty"` // e.g. mock:<parameter>
+	Package   string            `json:"package"`
+	Out       string            `json:"out"` // output file of the client
+	Data      map[string]string `json:"data,omitempty"`
+	Services  []PluginService   `json:"services"`
+}
+
+// PluginService is a service passed to plugins.
+type PluginService struct {
+	Name       string         `json:"name"`      // field name in the Client type, e.g. Node
+	Interface  string         `json:"interface"` // e.g. NodeService or api.NodeService
+	TypeParams string         `json:"typeParams,omitempty"`
+	Impl       string         `json:"impl"`      // e.g. NodeImpl
+	Generated  bool           `json:"generated"` // the Impl type is generated
+	Error      string         `json:"error,omitempty"`
+	BasePath   string         `json:"basePath,omitempty"`
+	Doc        string         `json:"doc,omitempty"`
+	Methods    []PluginMethod `json:"methods"`
+}
+
+// PluginMethod is a method of a service passed to plugins.
+type PluginMethod struct {
+	Name       string            `json:"name"`
+	Signature  string            `json:"signature"` // e.g. Get(ctx context.Context, id int) (*Node, *http.Response, error)
+	Doc        string            `json:"doc,omitempty"`
+	Directives map[string]string `json:"directives,omitempty"` // e.g. route: GET /nodes/{id}
+}
+
+// PluginResponse is returned by plugins, external plugins write it as JSON to stdout. The
+// names of the files are relative to the directory of the output file of the client.
+type PluginResponse struct {
+	Files []PluginFile `json:"files"`
+	Error string       `json:"error,omitempty"`
+}
+
+// PluginFile is a file generated by a plugin.
+type PluginFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// ExecPlugin returns the plugin running the external program command (like the plugins of
+// protoc): the PluginRequest is written as JSON to its stdin and the PluginResponse read
+// from its stdout. Errors are reported in the response or with a non-zero exit code and a
+// message on stderr.
+func ExecPlugin(command string) Plugin {
+	return PluginFunc(func(req *PluginRequest) ([]File, error) {
+		in, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+
+		cmd := exec.Command(command) // nolint: gosec // G204: Subprocess launched with variable
+		cmd.Stdin = bytes.NewReader(in)
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("plugin %s failed: %s: %w", command, msg, err)
+			}
+
+			return nil, fmt.Errorf("plugin %s failed: %w", command, err)
+		}
+
+		resp := PluginResponse{}
+		if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+			return nil, fmt.Errorf("invalid response of plugin %s: %w", command, err)
+		}
+
+		if resp.Error != "" {
+			return nil, fmt.Errorf("plugin %s: %s", command, resp.Error)
+		}
+
+		files := []File{}
+		for _, f := range resp.Files {
+			files = append(files, File{Name: f.Name, Content: []byte(f.Content)})
+		}
+
+		return files, nil
+	})
+}
+
+// RunPlugin runs the plugin p with the services of data and returns the generated files. The
+// file names are resolved relative to the directory of the output file and go files are
+// formatted. It requires the option Plugins.
+func RunPlugin(data *Data, p Plugin, parameter string) ([]File, error) {
+	opts := data.opts.withDefaults()
+
+	req := &PluginRequest{
+		Version:   PluginVersion,
+		Parameter: parameter,
+		Package:   data.Package,
+		Out:       opts.Out,
+		Data:      data.Data,
+		Services:  []PluginService{},
+	}
+
+	for _, s := range data.Services {
+		req.Services = append(req.Services, PluginService{
+			Name:       s.FieldName,
+			Interface:  s.InterfaceName,
+			TypeParams: s.TypeParams,
+			Impl:       s.TypeName,
+			Generated:  s.Generate,
+			Error:      s.Error,
+			BasePath:   s.BasePath,
+			Doc:        s.doc,
+			Methods:    s.pluginMethods,
+		})
+	}
+
+	files, err := p.Generate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(opts.Out)
+
+	for i, f := range files {
+		name := filepath.Clean(f.Name)
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("plugin file %s is not in the output directory", f.Name)
+		}
+
+		files[i].Name = filepath.Join(dir, name)
+
+		if filepath.Ext(name) == ".go" {
+			if files[i].Content, err = format(files[i].Name, f.Content, opts.GoImports); err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// newPluginMethods returns the methods of the interface type it passed to plugins.
+func newPluginMethods(it *ast.InterfaceType, decls *declarations) ([]PluginMethod, error) {
+	fields, err := methodSet(it, decls, map[*ast.InterfaceType]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	methods := []PluginMethod{}
+
+	for _, f := range fields {
+		methods = append(methods, PluginMethod{
+			Name:       f.Names[0].Name,
+			Signature:  f.Names[0].Name + strings.TrimPrefix(types.ExprString(f.Type), "func"),
+			Doc:        strings.TrimSpace(f.Doc.Text()),
+			Directives: directives(f.Doc),
+		})
+	}
+
+	return methods, nil
+}
@@ -0,0 +1,65 @@
+package gen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "api.go"), []byte(testService), 0o600))
+
+	data, err := Scan(Options{
+		Package: "api",
+		Paths:   []string{dir},
+		Out:     filepath.Join(dir, "httpclient.go"),
+		Plugins: true,
+	})
+	assert.Nil(t, err)
+
+	t.Run("request", func(t *testing.T) {
+		var req *PluginRequest
+
+		files, err := RunPlugin(data, PluginFunc(func(r *PluginRequest) ([]File, error) {
+			req = r
+			return []File{{Name: "mocks/node.go", Content: []byte("package mocks\nvar   x = 1\n")}}, nil
+		}), "pkg=mocks")
+		assert.Nil(t, err)
+
+		assert.Equal(t, PluginVersion, req.Version)
+		assert.Equal(t, "pkg=mocks", req.Parameter)
+		assert.Equal(t, "api", req.Package)
+		assert.Len(t, req.Services, 1)
+		assert.Equal(t, "Node", req.Services[0].Name)
+		assert.Equal(t, "NodeService manages nodes.", req.Services[0].Doc)
+		assert.Equal(t, []PluginMethod{{
+			Name:       "Get",
+			Signature:  "Get(ctx context.Context, id string) (*Node, *http.Response, error)",
+			Directives: map[string]string{"route": "GET /nodes/{id}"},
+		}}, req.Services[0].Methods)
+
+		assert.Len(t, files, 1)
+		assert.Equal(t, filepath.Join(dir, "mocks", "node.go"), files[0].Name)
+		assert.Equal(t, "package mocks\n\nvar x = 1\n", string(files[0].Content))
+	})
+
+	t.Run("file outside of the output directory", func(t *testing.T) {
+		_, err := RunPlugin(data, PluginFunc(func(r *PluginRequest) ([]File, error) {
+			return []File{{Name: "../node.txt"}}, nil
+		}), "")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("missing program", func(t *testing.T) {
+		_, err := RunPlugin(data, ExecPlugin(filepath.Join(dir, "missing")), "")
+		assert.NotNil(t, err)
+	})
+}
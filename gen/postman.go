@@ -0,0 +1,504 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const postmanTemplate = `
+// Code generated by httpclient-gen-go from a Postman collection; DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"net/http"
+)
+{{- range .Services }}
+
+{{ .Doc }}
+type {{ .Name }} interface {
+{{- range .Methods }}
+	{{ .Doc }}
+	//httpclient:route {{ .Verb }} {{ .Path }}
+{{- if .Query }}
+	//httpclient:query {{ .Query }}
+{{- end }}
+	{{ .Name }}(ctx context.Context{{ range .Params }}, {{ .Name }} {{ .Type }}{{ end }}) ({{ if .Result }}{{ .Result }}, {{ end }}*http.Response, error)
+{{- end }}
+}
+{{- end }}
+{{- range .Structs }}
+
+{{ .Doc }}
+type {{ .Name }} struct {
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} ` + "`{{ .Tag }}:\"{{ .Key }},omitempty\"`" + `
+{{- end }}
+}
+{{- end }}
+`
+
+// postmanCollection is a Postman collection (format v2.0 and v2.1).
+type postmanCollection struct {
+	Info struct {
+		Name        string          `json:"name"`
+		Description json.RawMessage `json:"description"`
+	} `json:"info"`
+	Item []postmanItem `json:"item"`
+}
+
+// postmanItem is a folder (with items) or a request of a collection.
+type postmanItem struct {
+	Name        string          `json:"name"`
+	Description json.RawMessage `json:"description"`
+	Item        []postmanItem   `json:"item"`
+	Request     *struct {
+		Method      string          `json:"method"`
+		URL         json.RawMessage `json:"url"`
+		Description json.RawMessage `json:"description"`
+		Body        *struct {
+			Mode string `json:"mode"`
+			Raw  string `json:"raw"`
+		} `json:"body"`
+	} `json:"request"`
+	Response []struct {
+		Code int    `json:"code"`
+		Body string `json:"body"`
+	} `json:"response"`
+}
+
+// postmanURL is the URL of a request.
+type postmanURL struct {
+	Raw   string   `json:"raw"`
+	Path  []string `json:"path"`
+	Query []struct {
+		Key      string `json:"key"`
+		Disabled bool   `json:"disabled"`
+	} `json:"query"`
+}
+
+// postmanService is a service generated from a folder (or the requests at the top level) of a
+// collection.
+type postmanService struct {
+	Name    string
+	Doc     string
+	Methods []*postmanMethod
+}
+
+// postmanMethod is a method generated from a request.
+type postmanMethod struct {
+	Name   string
+	Doc    string
+	Verb   string
+	Path   string
+	Query  string
+	Params []param
+	Result string
+}
+
+// postmanStruct is a struct type inferred from example data (request and response bodies) or
+// the query parameters of a request.
+type postmanStruct struct {
+	Name   string
+	Doc    string
+	Fields []postmanField
+}
+
+// postmanField is a field of a struct inferred from example data.
+type postmanField struct {
+	Name string
+	Type string
+	Tag  string // json or url
+	Key  string
+}
+
+// postmanImporter converts a collection into services and structs.
+type postmanImporter struct {
+	services []*postmanService
+	structs  []*postmanStruct
+	names    map[string]bool // used type names
+	suffix   string          // appended to the service names
+}
+
+// generatePostman returns the formatted code of the service interfaces (with route directives)
+// and the example structs of the Postman collection (-postman) for file.
+func generatePostman(collection, file string, opts Options) ([]byte, error) {
+	b, err := ioutil.ReadFile(collection) // nolint: gosec // G304: file inclusion is intended
+	if err != nil {
+		return nil, fmt.Errorf("could not read Postman collection: %w", err)
+	}
+
+	c := postmanCollection{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("could not parse Postman collection %s: %w", collection, err)
+	}
+
+	imp := &postmanImporter{names: map[string]bool{}, suffix: opts.suffix()}
+
+	top := []postmanItem{}
+
+	for _, item := range c.Item {
+		if item.Request == nil {
+			if err := imp.service(item.Name, postmanText(item.Description), item.Item); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		top = append(top, item)
+	}
+
+	if len(top) > 0 {
+		if err := imp.service(c.Info.Name, postmanText(c.Info.Description), top); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(imp.structs, func(i, j int) bool { return imp.structs[i].Name < imp.structs[j].Name })
+
+	t, err := template.New("Postman Template").Parse(postmanTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = t.Execute(buf, struct {
+		Package  string
+		Services []*postmanService
+		Structs  []*postmanStruct
+	}{opts.Package, imp.services, imp.structs})
+	if err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	return format(file, buf.Bytes(), opts.GoImports)
+}
+
+// service adds the service name with the requests in items (and in nested folders).
+func (imp *postmanImporter) service(name, doc string, items []postmanItem) error {
+	iface := goName(name)
+	if !strings.HasSuffix(iface, imp.suffix) {
+		iface += imp.suffix
+	}
+
+	if imp.names[iface] {
+		return fmt.Errorf("duplicate service %s", iface)
+	}
+
+	imp.names[iface] = true
+	s := &postmanService{Name: iface, Doc: generatedDoc(iface, "a Postman collection", doc)}
+	methods := map[string]bool{}
+
+	var add func(items []postmanItem) error
+
+	add = func(items []postmanItem) error {
+		for _, item := range items {
+			if item.Request == nil {
+				if err := add(item.Item); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			m, err := imp.method(item, methods)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", name, item.Name, err)
+			}
+
+			s.Methods = append(s.Methods, m)
+		}
+
+		return nil
+	}
+
+	if err := add(items); err != nil {
+		return err
+	}
+
+	imp.services = append(imp.services, s)
+
+	return nil
+}
+
+// method returns the method of the request item, methods are the method names of the service.
+// nolint: funlen, gocyclo
+func (imp *postmanImporter) method(item postmanItem, methods map[string]bool) (*postmanMethod, error) {
+	r := item.Request
+	m := &postmanMethod{Name: goName(item.Name), Verb: strings.ToUpper(r.Method)}
+
+	if m.Verb == "" {
+		m.Verb = "GET"
+	}
+
+	for i := 2; methods[m.Name]; i++ {
+		m.Name = fmt.Sprintf("%s%d", goName(item.Name), i)
+	}
+
+	methods[m.Name] = true
+
+	m.Doc = comment(m.Name + " sends the request " + item.Name + ".")
+	if d := postmanText(r.Description); d != "" {
+		m.Doc += "\n//\n" + comment(d)
+	}
+
+	u := postmanURL{}
+	if err := json.Unmarshal(r.URL, &u.Raw); err != nil {
+		if err := json.Unmarshal(r.URL, &u); err != nil {
+			return nil, fmt.Errorf("invalid url: %w", err)
+		}
+	}
+
+	if u.Path == nil {
+		u = parseRawURL(u.Raw)
+	}
+
+	// path variables: :name and {{name}}
+	segments := []string{}
+
+	for _, seg := range u.Path {
+		name := ""
+
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			name = seg[1:]
+		case strings.HasPrefix(seg, "{{") && strings.HasSuffix(seg, "}}"):
+			name = seg[2 : len(seg)-2]
+		}
+
+		if name == "" {
+			segments = append(segments, seg)
+			continue
+		}
+
+		p := paramName(name)
+		if reserved[p] || decoratorReserved[p] || p == "ctx" {
+			p += "Param"
+		}
+
+		segments = append(segments, "{"+p+"}")
+		m.Params = append(m.Params, param{Name: p, Type: "string"})
+	}
+
+	m.Path = "/" + strings.Join(segments, "/")
+
+	if len(u.Query) > 0 {
+		s := &postmanStruct{Name: imp.typeName(m.Name + "Query")}
+		s.Doc = fmt.Sprintf("// %s are the query parameters of %s.", s.Name, m.Name)
+		keys := map[string]bool{}
+
+		for _, q := range u.Query {
+			if q.Disabled || keys[q.Key] || strings.HasPrefix(q.Key, "{{") {
+				continue
+			}
+
+			keys[q.Key] = true
+			s.Fields = append(s.Fields, postmanField{Name: goName(q.Key), Type: "string", Tag: "url", Key: q.Key})
+		}
+
+		if len(s.Fields) > 0 {
+			imp.structs = append(imp.structs, s)
+			m.Query = s.Name
+			m.Params = append(m.Params, param{Name: "query", Type: "*" + s.Name})
+		}
+	}
+
+	if r.Body != nil && r.Body.Mode == "raw" && strings.TrimSpace(r.Body.Raw) != "" {
+		typ, err := imp.infer(m.Name+"Request", "the request body of "+m.Name, []byte(r.Body.Raw))
+		if err != nil {
+			return nil, fmt.Errorf("request body is not JSON: %w", err)
+		}
+
+		m.Params = append(m.Params, param{Name: "body", Type: typ})
+	}
+
+	for _, resp := range item.Response {
+		if resp.Code >= 300 || strings.TrimSpace(resp.Body) == "" {
+			continue
+		}
+
+		typ, err := imp.infer(m.Name+"Response", "the response body of "+m.Name, []byte(resp.Body))
+		if err != nil {
+			continue // e.g. HTML
+		}
+
+		m.Result = typ
+
+		break
+	}
+
+	return m, nil
+}
+
+// infer returns the go type of the JSON example data (a struct named name for objects).
+func (imp *postmanImporter) infer(name, what string, data []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	typ, err := imp.value(dec, name, what)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return "", errors.New("trailing data")
+	}
+
+	return typ, nil
+}
+
+// value returns the go type of the next JSON value of dec.
+// nolint: gocyclo
+func (imp *postmanImporter) value(dec *json.Decoder, name, what string) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '[' {
+			elem := "interface{}"
+
+			for i := 0; dec.More(); i++ {
+				if i > 0 {
+					// the first element is the example
+					if err := dec.Decode(new(json.RawMessage)); err != nil {
+						return "", err
+					}
+
+					continue
+				}
+
+				typ, err := imp.value(dec, name+"Item", what)
+				if err != nil {
+					return "", err
+				}
+
+				elem = typ
+			}
+
+			_, err := dec.Token()
+
+			return "[]" + strings.TrimPrefix(elem, "*"), err
+		}
+
+		s := &postmanStruct{Name: imp.typeName(name)}
+		s.Doc = fmt.Sprintf("// %s is %s (inferred from an example).", s.Name, what)
+		imp.structs = append(imp.structs, s)
+		keys := map[string]bool{}
+
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return "", err
+			}
+
+			key := tok.(string)
+			field := goName(key)
+
+			typ, err := imp.value(dec, s.Name+field, what)
+			if err != nil {
+				return "", err
+			}
+
+			if keys[key] {
+				continue
+			}
+
+			keys[key] = true
+			s.Fields = append(s.Fields, postmanField{Name: field, Type: typ, Tag: "json", Key: key})
+		}
+
+		_, err := dec.Token()
+
+		return "*" + s.Name, err
+	case string:
+		return "string", nil
+	case bool:
+		return "bool", nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "int", nil
+		}
+
+		return "float64", nil
+	default:
+		return "interface{}", nil // null
+	}
+}
+
+// typeName returns name or, if it is already used, name with a number.
+func (imp *postmanImporter) typeName(name string) string {
+	n := name
+	for i := 2; imp.names[n]; i++ {
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+
+	imp.names[n] = true
+
+	return n
+}
+
+// parseRawURL returns the path and query of the raw URL of a request, e.g.
+// {{baseUrl}}/users/:id?verbose=true.
+func parseRawURL(raw string) postmanURL {
+	u := postmanURL{Raw: raw}
+
+	path, query := raw, ""
+	if i := strings.Index(raw, "?"); i >= 0 {
+		path, query = raw[:i], raw[i+1:]
+	}
+
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+	}
+
+	// the first segment is the host (or a variable like {{baseUrl}})
+	segments := strings.Split(path, "/")[1:]
+	for _, s := range segments {
+		if s != "" {
+			u.Path = append(u.Path, s)
+		}
+	}
+
+	for _, kv := range strings.Split(query, "&") {
+		if kv == "" {
+			continue
+		}
+
+		k, _, _ := strings.Cut(kv, "=")
+		u.Query = append(u.Query, struct {
+			Key      string `json:"key"`
+			Disabled bool   `json:"disabled"`
+		}{Key: k})
+	}
+
+	return u
+}
+
+// postmanText returns the text of a description (a string or an object with content).
+func postmanText(raw json.RawMessage) string {
+	s := ""
+	if err := json.Unmarshal(raw, &s); err != nil {
+		d := struct {
+			Content string `json:"content"`
+		}{}
+
+		_ = json.Unmarshal(raw, &d)
+		s = d.Content
+	}
+
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,1046 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const protoTemplate = `
+// Code generated by httpclient-gen-go from protobuf; DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"net/http"
+)
+{{- range .Services }}
+
+{{ .Doc }}
+{{- if .BasePath }}
+//httpclient:basepath {{ .BasePath }}
+{{- end }}
+{{- if .Error }}
+//httpclient:error {{ .Error }}
+{{- end }}
+type {{ .Name }} interface {
+{{- range .Methods }}
+{{- if .Doc }}
+	{{ .Doc }}
+{{- end }}
+	//httpclient:route {{ .Verb }} {{ .Path }}
+{{- if .Query }}
+	//httpclient:query {{ .Query }}
+{{- end }}
+	{{ .Name }}(ctx context.Context{{ range .Params }}, {{ .Name }} {{ .Type }}{{ end }}) ({{ if .Result }}{{ .Result }}, {{ end }}*http.Response, error)
+{{- end }}
+}
+{{- end }}
+{{- if and .Services (eq .Protocol "twirp") }}
+
+// TwirpStatus is the error response of the Twirp protocol.
+type TwirpStatus struct {
+	Code string            ` + "`json:\"code\"`" + `
+	Msg  string            ` + "`json:\"msg\"`" + `
+	Meta map[string]string ` + "`json:\"meta,omitempty\"`" + `
+}
+{{- else if and .Services (eq .Protocol "connect") }}
+
+// ConnectStatus is the error response of the Connect protocol.
+type ConnectStatus struct {
+	Code    string            ` + "`json:\"code\"`" + `
+	Message string            ` + "`json:\"message,omitempty\"`" + `
+	Details []json.RawMessage ` + "`json:\"details,omitempty\"`" + `
+}
+{{- end }}
+{{- range .Messages }}
+
+{{ .Doc }}
+type {{ .Name }} struct {
+{{- range .Fields }}
+{{- if .Doc }}
+	{{ .Doc }}
+{{- end }}
+	{{ .Name }} {{ .Type }} ` + "`json:\"{{ .JSON }},omitempty{{ if .String }},string{{ end }}\" url:\"{{ .JSON }},omitempty\"`" + `
+{{- end }}
+}
+{{- end }}
+{{- range .Enums }}
+{{- $e := . }}
+
+{{ .Doc }}
+type {{ .Name }} string
+
+// Values of {{ .Name }}.
+const (
+{{- range .Values }}
+	{{ $e.Name }}{{ goName (lower .) }} {{ $e.Name }} = {{ printf "%q" . }}
+{{- end }}
+)
+{{- end }}
+`
+
+// protoFile is a parsed .proto file.
+type protoFile struct {
+	pkg      string
+	messages []*protoMessage
+	enums    []*protoEnum
+	services []*protoService
+}
+
+// protoMessage is a message of a .proto file.
+type protoMessage struct {
+	Name   string // go name, e.g. ShelfBook for the nested message Shelf.Book
+	Doc    string
+	Fields []*protoField
+
+	proto string // full name, e.g. library.Shelf.Book
+}
+
+// protoField is a field of a message.
+type protoField struct {
+	Name   string // go name
+	Type   string // go type
+	JSON   string // JSON name
+	String bool   // 64-bit integers are encoded as string
+	Doc    string
+
+	proto    string // field name
+	protoTyp string // field type
+	repeated bool
+	mapKey   string
+	scope    string // full name of the message
+}
+
+// protoEnum is an enum of a .proto file, the values are encoded as strings.
+type protoEnum struct {
+	Name   string
+	Doc    string
+	Values []string
+
+	proto string
+}
+
+// protoService is a service of a .proto file.
+type protoService struct {
+	Name     string
+	Doc      string
+	Methods  []*protoMethod
+	BasePath string // basepath directive
+	Error    string // error directive
+}
+
+// protoMethod is an rpc of a service with its google.api.http annotation or the route of the
+// RPC protocol.
+type protoMethod struct {
+	Name   string
+	Doc    string
+	Verb   string
+	Path   string
+	Query  string
+	Params []param
+	Result string
+
+	input  string
+	output string
+	body   string
+	scope  string // package of the service
+}
+
+// wellKnownTypes are the go types of the well-known protobuf types.
+// nolint: gochecknoglobals
+var wellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp":   "*time.Time",
+	"google.protobuf.Duration":    "string",
+	"google.protobuf.Struct":      "map[string]interface{}",
+	"google.protobuf.Value":       "interface{}",
+	"google.protobuf.ListValue":   "[]interface{}",
+	"google.protobuf.Any":         "json.RawMessage",
+	"google.protobuf.FieldMask":   "string",
+	"google.protobuf.StringValue": "*string",
+	"google.protobuf.BytesValue":  "*[]byte",
+	"google.protobuf.BoolValue":   "*bool",
+	"google.protobuf.Int32Value":  "*int32",
+	"google.protobuf.UInt32Value": "*uint32",
+	"google.protobuf.Int64Value":  "*int64",
+	"google.protobuf.UInt64Value": "*uint64",
+	"google.protobuf.FloatValue":  "*float32",
+	"google.protobuf.DoubleValue": "*float64",
+	"google.protobuf.Empty":       "",
+}
+
+// protoProtocols are the names of the error types of the RPC protocols, the empty protocol
+// uses the google.api.http annotations.
+// nolint: gochecknoglobals
+var protoProtocols = map[string]string{
+	"":        "",
+	"twirp":   "TwirpStatus",
+	"connect": "ConnectStatus",
+}
+
+// scalarTypes are the go types of the protobuf scalar types.
+// nolint: gochecknoglobals
+var scalarTypes = map[string]string{
+	"double": "float64", "float": "float32",
+	"int32": "int32", "sint32": "int32", "sfixed32": "int32",
+	"int64": "int64", "sint64": "int64", "sfixed64": "int64",
+	"uint32": "uint32", "fixed32": "uint32",
+	"uint64": "uint64", "fixed64": "uint64",
+	"bool": "bool", "string": "string", "bytes": "[]byte",
+}
+
+// generateProto returns the formatted code of the service interfaces (with route directives)
+// and messages of the .proto files (-proto) for file.
+// nolint: funlen, gocyclo
+func generateProto(paths []string, file string, opts Options) ([]byte, error) {
+	status, ok := protoProtocols[opts.ProtoProtocol]
+	if !ok {
+		return nil, fmt.Errorf("unknown protocol %s", opts.ProtoProtocol)
+	}
+
+	files := []*protoFile{}
+
+	for _, p := range paths {
+		names := []string{p}
+
+		if !strings.HasSuffix(p, ".proto") {
+			f, err := filepath.Glob(filepath.Join(p, "*.proto"))
+			if err != nil {
+				return nil, err
+			}
+
+			names = f
+		}
+
+		for _, name := range names {
+			b, err := ioutil.ReadFile(name) // nolint: gosec // G304: file inclusion is intended
+			if err != nil {
+				return nil, fmt.Errorf("could not read proto file: %w", err)
+			}
+
+			f, err := parseProto(string(b), opts.suffix(), opts.ProtoProtocol)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+
+			files = append(files, f)
+		}
+	}
+
+	messages := map[string]*protoMessage{}
+	enums := map[string]*protoEnum{}
+	names := map[string]string{}
+
+	if status != "" {
+		names[status] = opts.ProtoProtocol + " protocol"
+	}
+
+	for _, f := range files {
+		for _, m := range f.messages {
+			messages[m.proto] = m
+			if other, ok := names[m.Name]; ok {
+				return nil, fmt.Errorf("%s and %s have the same go name %s", other, m.proto, m.Name)
+			}
+
+			names[m.Name] = m.proto
+		}
+
+		for _, e := range f.enums {
+			enums[e.proto] = e
+			if other, ok := names[e.Name]; ok {
+				return nil, fmt.Errorf("%s and %s have the same go name %s", other, e.proto, e.Name)
+			}
+
+			names[e.Name] = e.proto
+		}
+	}
+
+	// resolve returns the full name of the message or enum typ used in scope
+	resolve := func(typ, scope string) string {
+		if strings.HasPrefix(typ, ".") {
+			return typ[1:]
+		}
+
+		for s := scope; ; s = s[:strings.LastIndex(s, ".")] {
+			n := typ
+			if s != "" {
+				n = s + "." + typ
+			}
+
+			if _, ok := messages[n]; ok {
+				return n
+			}
+
+			if _, ok := enums[n]; ok {
+				return n
+			}
+
+			if !strings.Contains(s, ".") {
+				break
+			}
+		}
+
+		return typ
+	}
+
+	goType := func(typ, scope string) (string, error) {
+		if t, ok := scalarTypes[typ]; ok {
+			return t, nil
+		}
+
+		full := resolve(typ, scope)
+
+		if t, ok := wellKnownTypes[full]; ok && t != "" {
+			return t, nil
+		}
+
+		if m, ok := messages[full]; ok {
+			return "*" + m.Name, nil
+		}
+
+		if e, ok := enums[full]; ok {
+			return e.Name, nil
+		}
+
+		return "", fmt.Errorf("unknown type %s", typ)
+	}
+
+	for _, m := range messages {
+		for _, f := range m.Fields {
+			t, err := goType(f.protoTyp, f.scope)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", m.proto, f.proto, err)
+			}
+
+			f.String = (t == "int64" || t == "uint64") && !f.repeated && f.mapKey == ""
+
+			switch {
+			case f.mapKey != "":
+				f.Type = "map[" + scalarTypes[f.mapKey] + "]" + t
+			case f.repeated:
+				f.Type = "[]" + t
+			default:
+				f.Type = t
+			}
+		}
+	}
+
+	services := []*protoService{}
+
+	for _, f := range files {
+		for _, s := range f.services {
+			for _, m := range s.Methods {
+				in, ok := messages[resolve(m.input, m.scope)]
+				if !ok {
+					return nil, fmt.Errorf("%s.%s: unknown request message %s", s.Name, m.Name, m.input)
+				}
+
+				if err := m.bind(in); err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", s.Name, m.Name, err)
+				}
+
+				out := resolve(m.output, m.scope)
+				if out != "google.protobuf.Empty" {
+					t, err := goType(m.output, m.scope)
+					if err != nil {
+						return nil, fmt.Errorf("%s.%s: %w", s.Name, m.Name, err)
+					}
+
+					m.Result = t
+				}
+			}
+
+			services = append(services, s)
+		}
+	}
+
+	sortedMessages := []*protoMessage{}
+	for _, m := range messages {
+		sortedMessages = append(sortedMessages, m)
+	}
+
+	sort.Slice(sortedMessages, func(i, j int) bool { return sortedMessages[i].Name < sortedMessages[j].Name })
+
+	sortedEnums := []*protoEnum{}
+	for _, e := range enums {
+		sortedEnums = append(sortedEnums, e)
+	}
+
+	sort.Slice(sortedEnums, func(i, j int) bool { return sortedEnums[i].Name < sortedEnums[j].Name })
+
+	t, err := template.New("Proto Template").Funcs(template.FuncMap{"goName": goName, "lower": strings.ToLower}).Parse(protoTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = t.Execute(buf, struct {
+		Package  string
+		Protocol string
+		Services []*protoService
+		Messages []*protoMessage
+		Enums    []*protoEnum
+	}{opts.Package, opts.ProtoProtocol, services, sortedMessages, sortedEnums})
+	if err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	return format(file, buf.Bytes(), opts.GoImports)
+}
+
+// bind sets the route and the parameters of the method m with the request message in.
+// nolint: gocyclo
+func (m *protoMethod) bind(in *protoMessage) error {
+	fields := map[string]*protoField{}
+	for _, f := range in.Fields {
+		fields[f.proto] = f
+	}
+
+	bound := map[string]bool{}
+	path := strings.Builder{}
+	rest := m.Path
+
+	for {
+		i := strings.Index(rest, "{")
+		if i < 0 {
+			path.WriteString(rest)
+			break
+		}
+
+		j := strings.Index(rest[i:], "}")
+		if j < 0 {
+			return fmt.Errorf("invalid path %s", m.Path)
+		}
+
+		path.WriteString(rest[:i])
+
+		name, pattern := rest[i+1:i+j], ""
+		if k := strings.Index(name, "="); k >= 0 {
+			name, pattern = name[:k], name[k+1:]
+		}
+
+		f, ok := fields[name]
+		if !ok || f.repeated || f.mapKey != "" || !isScalar(f.Type) {
+			return fmt.Errorf("path variable %s is not a scalar field of %s", name, in.Name)
+		}
+
+		// {name=shelves/*} becomes shelves/{name}, the parameter is the last segment
+		if pattern != "" && pattern != "*" {
+			if strings.Count(pattern, "*") != 1 || !strings.HasSuffix(pattern, "/*") {
+				return fmt.Errorf("unsupported path variable pattern %s", pattern)
+			}
+
+			path.WriteString(strings.TrimSuffix(pattern, "*"))
+		}
+
+		p := paramName(f.proto)
+		if decoratorReserved[p] || p == "req" || p == "ctx" {
+			p += "Param"
+		}
+
+		path.WriteString("{" + p + "}")
+		m.Params = append(m.Params, param{Name: p, Type: f.Type})
+		bound[name] = true
+		rest = rest[i+j+1:]
+	}
+
+	m.Path = path.String()
+
+	switch m.body {
+	case "*":
+		m.Params = append(m.Params, param{Name: "req", Type: "*" + in.Name})
+	case "":
+		for _, f := range in.Fields {
+			if !bound[f.proto] {
+				m.Query = in.Name
+				m.Params = append(m.Params, param{Name: "req", Type: "*" + in.Name})
+
+				break
+			}
+		}
+	default:
+		f, ok := fields[m.body]
+		if !ok {
+			return fmt.Errorf("body %s is not a field of %s", m.body, in.Name)
+		}
+
+		m.Params = append(m.Params, param{Name: paramName(f.proto), Type: f.Type})
+	}
+
+	return nil
+}
+
+// isScalar reports whether the go type typ is a scalar (path parameter) type.
+func isScalar(typ string) bool {
+	return typ == "string" || typ == "bool" || isNumber(typ) || typ == "uint32" || typ == "uint64"
+}
+
+// protoParser parses .proto files (the subset describing messages, enums and services with
+// http annotations).
+type protoParser struct {
+	tokens   []protoToken
+	pos      int
+	file     *protoFile
+	suffix   string // appended to the service names
+	protocol string // RPC protocol (see Options.ProtoProtocol)
+}
+
+// protoToken is a token of a .proto file with the comment preceding it.
+type protoToken struct {
+	text    string
+	comment string
+	line    int
+}
+
+// parseProto parses the content of a .proto file.
+func parseProto(src, suffix, protocol string) (*protoFile, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &protoParser{tokens: tokens, file: &protoFile{}, suffix: suffix, protocol: protocol}
+
+	for !p.eof() {
+		t := p.next()
+
+		switch t.text {
+		case "syntax", "import", "option", "edition":
+			p.skipStatement()
+		case "package":
+			p.file.pkg = p.next().text
+			p.skipStatement()
+		case "message":
+			if err := p.message("", t.comment); err != nil {
+				return nil, err
+			}
+		case "enum":
+			if err := p.enum("", t.comment); err != nil {
+				return nil, err
+			}
+		case "service":
+			if err := p.service(t.comment); err != nil {
+				return nil, err
+			}
+		case ";":
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %s", t.line, t.text)
+		}
+	}
+
+	return p.file, nil
+}
+
+func (p *protoParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *protoParser) next() protoToken {
+	if p.eof() {
+		return protoToken{line: -1}
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+
+	return t
+}
+
+func (p *protoParser) peek() string {
+	if p.eof() {
+		return ""
+	}
+
+	return p.tokens[p.pos].text
+}
+
+func (p *protoParser) expect(text string) error {
+	if t := p.next(); t.text != text {
+		return fmt.Errorf("line %d: expected %s instead of %q", t.line, text, t.text)
+	}
+
+	return nil
+}
+
+// skipStatement skips the tokens up to the next ; or the next (nested) block.
+func (p *protoParser) skipStatement() {
+	depth := 0
+
+	for !p.eof() {
+		switch p.next().text {
+		case "{", "[", "(":
+			depth++
+		case "}", "]", ")":
+			depth--
+			if depth == 0 && p.peek() != ";" {
+				return
+			}
+		case ";":
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// scope returns the full name of the type name in the message parent.
+func (p *protoParser) scope(parent, name string) string {
+	switch {
+	case parent != "":
+		return parent + "." + name
+	case p.file.pkg != "":
+		return p.file.pkg + "." + name
+	default:
+		return name
+	}
+}
+
+// goTypeName returns the go name of the message or enum with the full name full.
+func (p *protoParser) goTypeName(full string) string {
+	name := strings.TrimPrefix(full, p.file.pkg+".")
+
+	parts := strings.Split(name, ".")
+	for i := range parts {
+		parts[i] = title(parts[i])
+	}
+
+	return strings.Join(parts, "")
+}
+
+// nolint: funlen, gocyclo
+func (p *protoParser) message(parent, doc string) error {
+	name := p.next().text
+	full := p.scope(parent, name)
+	m := &protoMessage{Name: p.goTypeName(full), Doc: generatedDoc(p.goTypeName(full), "protobuf", doc), proto: full}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	p.file.messages = append(p.file.messages, m)
+
+	for {
+		t := p.next()
+
+		switch t.text {
+		case "}":
+			return nil
+		case "":
+			return fmt.Errorf("message %s is not closed", name)
+		case ";":
+		case "message":
+			if err := p.message(full, t.comment); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.enum(full, t.comment); err != nil {
+				return err
+			}
+		case "option", "reserved", "extensions":
+			p.skipStatement()
+		case "oneof":
+			p.next()
+
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+
+			for p.peek() != "}" && !p.eof() {
+				t := p.next()
+				if t.text == "option" {
+					p.skipStatement()
+					continue
+				}
+
+				f, err := p.field(t, full)
+				if err != nil {
+					return err
+				}
+
+				m.Fields = append(m.Fields, f)
+			}
+
+			p.next()
+		default:
+			f, err := p.field(t, full)
+			if err != nil {
+				return err
+			}
+
+			m.Fields = append(m.Fields, f)
+		}
+	}
+}
+
+// field parses a field starting with the token t of the message scope.
+// nolint: gocyclo
+func (p *protoParser) field(t protoToken, scope string) (*protoField, error) {
+	f := &protoField{scope: scope}
+
+	switch t.text {
+	case "repeated":
+		f.repeated = true
+		t = p.next()
+	case "optional", "required":
+		t = p.next()
+	}
+
+	if t.text == "map" {
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+
+		f.mapKey = p.next().text
+
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+
+		t = p.next()
+
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+	}
+
+	f.protoTyp = t.text
+	f.proto = p.next().text
+	f.Name = goName(f.proto)
+	f.JSON = lowerCamel(f.proto)
+
+	if t.comment != "" {
+		f.Doc = comment(t.comment)
+	}
+
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+
+	p.next() // number
+
+	if p.peek() == "[" {
+		p.next()
+
+		for p.peek() != "]" && !p.eof() {
+			if o := p.next(); o.text == "json_name" {
+				p.next()
+
+				if s, err := strconv.Unquote(p.next().text); err == nil {
+					f.JSON = s
+				}
+			}
+		}
+
+		p.next()
+	}
+
+	if err := p.expect(";"); err != nil {
+		return nil, fmt.Errorf("field %s: %w", f.proto, err)
+	}
+
+	return f, nil
+}
+
+func (p *protoParser) enum(parent, doc string) error {
+	name := p.next().text
+	full := p.scope(parent, name)
+	e := &protoEnum{Name: p.goTypeName(full), Doc: generatedDoc(p.goTypeName(full), "protobuf", doc), proto: full}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		t := p.next()
+
+		switch t.text {
+		case "}":
+			p.file.enums = append(p.file.enums, e)
+			return nil
+		case "":
+			return fmt.Errorf("enum %s is not closed", name)
+		case ";":
+		case "option", "reserved":
+			p.skipStatement()
+		default:
+			e.Values = append(e.Values, t.text)
+			p.skipStatement()
+		}
+	}
+}
+
+// nolint: funlen, gocyclo
+func (p *protoParser) service(doc string) error {
+	name := p.next().text
+	iface := name
+
+	if !strings.HasSuffix(iface, p.suffix) {
+		iface += p.suffix
+	}
+
+	s := &protoService{Name: iface, Doc: generatedDoc(iface, "protobuf", doc), Error: protoProtocols[p.protocol]}
+
+	if p.protocol == "twirp" {
+		s.BasePath = "/twirp"
+	}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		t := p.next()
+
+		switch t.text {
+		case "}":
+			p.file.services = append(p.file.services, s)
+			return nil
+		case "":
+			return fmt.Errorf("service %s is not closed", name)
+		case ";":
+		case "option":
+			p.skipStatement()
+		case "rpc":
+			m := &protoMethod{Name: p.next().text, scope: p.file.pkg}
+
+			if t.comment != "" {
+				m.Doc = comment(t.comment)
+			}
+
+			if err := p.expect("("); err != nil {
+				return err
+			}
+
+			if m.input = p.next().text; m.input == "stream" {
+				return fmt.Errorf("%s.%s: streaming is not supported", name, m.Name)
+			}
+
+			if err := p.expect(")"); err != nil {
+				return err
+			}
+
+			if err := p.expect("returns"); err != nil {
+				return err
+			}
+
+			if err := p.expect("("); err != nil {
+				return err
+			}
+
+			if m.output = p.next().text; m.output == "stream" {
+				return fmt.Errorf("%s.%s: streaming is not supported", name, m.Name)
+			}
+
+			if err := p.expect(")"); err != nil {
+				return err
+			}
+
+			if p.peek() == "{" {
+				if err := p.rpcOptions(m); err != nil {
+					return fmt.Errorf("%s.%s: %w", name, m.Name, err)
+				}
+			}
+
+			// the RPC protocols post the request message to /<package>.<service>/<method>
+			if p.protocol != "" {
+				m.Verb, m.Path, m.body = "POST", "/"+p.scope("", name)+"/"+m.Name, "*"
+			}
+
+			if m.Verb == "" {
+				continue // no http annotation
+			}
+
+			s.Methods = append(s.Methods, m)
+		default:
+			return fmt.Errorf("line %d: unexpected %s in service %s", t.line, t.text, name)
+		}
+	}
+}
+
+// rpcOptions parses the options of the rpc m and sets the google.api.http binding.
+func (p *protoParser) rpcOptions(m *protoMethod) error {
+	p.next() // {
+
+	for depth := 1; depth > 0 && !p.eof(); {
+		t := p.next()
+
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		case "additional_bindings":
+			p.skipStatement() // only the primary binding is used
+		case "get", "put", "post", "delete", "patch", ".get", ".put", ".post", ".delete", ".patch":
+			if m.Verb != "" || p.peek() != ":" && p.peek() != "=" {
+				continue
+			}
+
+			p.next()
+
+			path, err := strconv.Unquote(p.next().text)
+			if err != nil {
+				return fmt.Errorf("invalid path of %s", t.text)
+			}
+
+			m.Verb, m.Path = strings.ToUpper(strings.TrimPrefix(t.text, ".")), path
+		case "body", ".body":
+			if p.peek() != ":" && p.peek() != "=" {
+				continue
+			}
+
+			p.next()
+
+			body, err := strconv.Unquote(p.next().text)
+			if err != nil {
+				return errors.New("invalid body")
+			}
+
+			m.body = body
+		case "custom", ".custom":
+			return errors.New("custom http methods are not supported")
+		}
+	}
+
+	return nil
+}
+
+// tokenize splits the .proto source src into tokens and attaches the leading comments.
+// nolint: gocyclo
+func tokenize(src string) ([]protoToken, error) {
+	tokens := []protoToken{}
+	comments := []string{}
+	line := 1
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+
+			comments = append(comments, strings.TrimSpace(src[i+2:i+end]))
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: comment is not closed", line)
+			}
+
+			text := src[i+2 : i+end]
+			line += strings.Count(text, "\n")
+
+			for _, l := range strings.Split(text, "\n") {
+				comments = append(comments, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), "*")))
+			}
+
+			i += end + 2
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+
+				j++
+			}
+
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: string is not closed", line)
+			}
+
+			text := src[i : j+1]
+			if c == '\'' {
+				text = strconv.Quote(src[i+1 : j])
+			}
+
+			tokens = append(tokens, protoToken{text: text, line: line})
+			i = j + 1
+		case unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c == '_' || c == '.' || c == '-':
+			j := i
+			for j < len(src) && (unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j])) || strings.IndexByte("_.-+", src[j]) >= 0) {
+				j++
+			}
+
+			tokens = append(tokens, protoToken{text: src[i:j], comment: strings.TrimSpace(strings.Join(comments, "\n")), line: line})
+			comments = comments[:0]
+			i = j
+		default:
+			tokens = append(tokens, protoToken{text: string(c), line: line})
+			comments = comments[:0]
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// generatedDoc returns the doc comment of the type name generated from source with the
+// description c.
+func generatedDoc(name, source, c string) string {
+	if c == "" {
+		return fmt.Sprintf("// %s is generated from %s.", name, source)
+	}
+
+	if strings.HasPrefix(c, name+" ") {
+		return comment(c)
+	}
+
+	return fmt.Sprintf("// %s is generated from %s.\n//\n%s", name, source, comment(c))
+}
+
+// paramName returns the go parameter name of the protobuf field name s, e.g. shelfID for
+// shelf_id and typeParam for the keyword type.
+func paramName(s string) string {
+	name := goName(s)
+	if first := goName(strings.SplitN(s, "_", 2)[0]); initialisms[first] {
+		name = strings.ToLower(first) + strings.TrimPrefix(name, first)
+	} else {
+		name = strings.ToLower(name[:1]) + name[1:]
+	}
+
+	if token.IsKeyword(name) {
+		name += "Param"
+	}
+
+	return name
+}
+
+// lowerCamel returns the lower camel case of the protobuf field name s, e.g. shelfId for
+// shelf_id (like the JSON name of protobuf).
+func lowerCamel(s string) string {
+	b := strings.Builder{}
+	upper := false
+
+	for i, r := range s {
+		switch {
+		case r == '_':
+			upper = i > 0
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,571 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"golang.org/x/tools/imports"
+)
+
+const codeTemplate = `
+// Code generated by httpclient-gen-go; DO NOT EDIT.
+{{- if not .Timestamp.IsZero }}
+// This file was generated by robots at
+// {{ .Timestamp }}
+{{- end }}
+
+package {{.Package}}
+
+import (
+	"net/http"
+	"net/url"
+
+{{- range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+
+// Client is a generated wrapper for a http client and detected services.
+type Client struct {
+	*httpclient.Client
+
+	// Services used for communicating with the API
+{{- range .Services }}
+	{{ printf "%s %s" .FieldName .InterfaceName }}
+{{- end }}
+}
+
+// NewClient returns a new API client.
+func NewClient(baseURL string, opts ...httpclient.Opt) (*Client, error) {
+
+	client, err := httpclient.New(baseURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// services
+{{- range .Services }}
+	{{ .VarName }}Client, err := client.ServiceClient("{{ .FieldName }}")
+	if err != nil {
+		return nil, err
+	}
+{{- if .Error }}
+
+	{{ .VarName }}Client = {{ .VarName }}Client.Clone()
+	{{ .VarName }}Client.ResponseCallback = {{ .ErrorType }}Callback({{ .VarName }}Client, {{ .VarName }}Client.ResponseCallback)
+{{- end }}
+
+{{- if .Constructor }}
+
+	{{ .VarName }} := {{ .Constructor }}({{ .VarName }}Client)
+{{- else }}
+
+	{{ .VarName }} := &{{ .TypeName }}{client: {{ .VarName }}Client}
+{{- end }}
+{{ end }}
+
+{{- if .Decorate }}
+
+	c := &Client{
+		client,
+{{- range .Services }}
+{{ printf "%s," .VarName }}
+{{- end }}
+	}
+{{ range .Services }}
+{{- if .Policies }}
+	c.{{ .FieldName }} = &{{ .Policies }}{next: c.{{ .FieldName }}, client: {{ .VarName }}Client}
+{{- end }}
+{{- end }}
+{{- if .Instrument }}
+
+	if client.Instrumentation != nil {
+{{- range .Services }}
+{{- if .Instrumented }}
+		c.{{ .FieldName }} = &{{ .Instrumented }}{next: c.{{ .FieldName }}, instrumentation: client.Instrumentation}
+{{- end }}
+{{- end }}
+	}
+{{- end }}
+
+	return c, nil
+{{- else }}
+
+	return &Client{
+		client,
+{{- range .Services }}
+{{ printf "%s," .VarName }}
+{{- end }}
+	}, nil
+{{- end }}
+}
+{{- range .Services }}
+
+// With{{ .FieldName }}Options is a client option for setting options (e.g. httpclient.WithBaseURL,
+// httpclient.WithBasePath, httpclient.WithContentType or httpclient.WithHeader) which only apply to
+// the {{ .FieldName }} service.
+func With{{ .FieldName }}Options(opts ...httpclient.Opt) httpclient.Opt {
+	return httpclient.WithServiceOptions("{{ .FieldName }}", opts...)
+}
+{{- end }}
+{{- if not .Split }}
+{{- range .Services }}
+{{- template "service" . }}
+{{- end }}
+{{- end }}
+{{- define "service" }}
+{{- if .Error }}
+
+// {{ .ErrorType }} is the error returned by {{ .InterfaceName }} if the API responds with an error.
+type {{ .ErrorType }} struct {
+	Response *http.Response
+	{{ .Error }}
+	err error
+}
+
+// Error implements the error interface.
+func (e *{{ .ErrorType }}) Error() string {
+	return fmt.Sprintf("%s: %+v", e.Response.Status, e.{{ .Error }})
+}
+
+// Unwrap returns the error of the next ResponseCallbackFunc (e.g. *httpclient.HTTPError).
+func (e *{{ .ErrorType }}) Unwrap() error {
+	return e.err
+}
+
+// {{ .ErrorType }}Callback returns a ResponseCallbackFunc which decodes the body of error responses
+// (see next) into a *{{ .ErrorType }}.
+func {{ .ErrorType }}Callback(c *httpclient.Client, next httpclient.ResponseCallbackFunc) httpclient.ResponseCallbackFunc {
+	return func(r *http.Response) (*http.Response, error) {
+		r, err := next(r)
+		if err == nil || r == nil || r.Body == nil {
+			return r, err
+		}
+
+		e := &{{ .ErrorType }}{Response: r, err: err}
+		if c.Unmarshal(r, &e.{{ .Error }}) != nil {
+			return r, err
+		}
+
+		return r, e
+	}
+}
+{{- end }}
+{{- if .Generate }}
+{{- $svc := . }}
+
+// {{ .ImplName }} implements {{ .InterfaceName }}{{ if .TypeParams }} and its other instantiations{{ end }}.
+type {{ .ImplName }}{{ .TypeParams }} struct {
+	client *httpclient.Client
+}
+
+var _ {{ .InterfaceName }} = &{{ .TypeName }}{}
+{{- range .Methods }}
+
+// {{ .Name }} sends {{ .Route }}.
+func (s *{{ $svc.ImplName }}{{ $svc.TypeArgs }}) {{ .Signature }} {
+{{- if .Query }}
+	u, err := httpclient.QueryOptions({{ .Path }}, {{ .Query }})
+	if err != nil {
+		return {{ if .Result }}{{ .Zero }}, {{ end }}nil, err
+	}
+
+	req, err := s.client.NewRequest({{ .Verb }}, u, {{ .Body }})
+{{- else }}
+	req, err := s.client.NewRequest({{ .Verb }}, {{ .Path }}, {{ .Body }})
+{{- end }}
+	if err != nil {
+		return {{ if .Result }}{{ .Zero }}, {{ end }}nil, err
+	}
+{{- if .Result }}
+
+	return httpclient.DoTyped[{{ .Result }}]({{ .Context }}, s.client, req)
+{{- else }}
+
+	return s.client.Do({{ .Context }}, req, nil)
+{{- end }}
+}
+{{- if .Paginate }}
+{{ template "iterator" . }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if and .Calls .PoliciesName }}
+{{- $svc := . }}
+
+// {{ .PoliciesName }} decorates {{ .Decorated }} with the timeouts and retries of its methods.
+type {{ .PoliciesName }}{{ .TypeParams }} struct {
+	next   {{ .Decorated }}
+	client *httpclient.Client
+}
+{{- if not .TypeParams }}
+
+var _ {{ .Decorated }} = &{{ .PoliciesName }}{}
+{{- end }}
+{{- range .Calls }}
+{{- if or .Timeout .Retry }}
+
+// {{ .Name }} calls {{ .Name }} of the decorated service
+{{- if .Timeout }} with a timeout of {{ .TimeoutText }}{{ end }}
+{{- if .Retry }}{{ if .Timeout }} and{{ end }} with retries (see httpclient.Client.Retry){{ end }}.
+func (s *{{ $svc.PoliciesName }}{{ $svc.TypeArgs }}) {{ .Signature }} {
+{{- if .Timeout }}
+	{{ .Context }}, cancel := context.WithTimeout({{ .Context }}, {{ .Timeout }})
+	defer cancel()
+{{ end }}
+{{- if and .Retry .Result }}
+	var v {{ .Result }}
+
+	resp, err := s.client.Retry({{ .Context }}, func({{ .Context }} context.Context) (resp *http.Response, err error) {
+		v, resp, err = s.next.{{ .Name }}({{ .Args }})
+		return resp, err
+	})
+
+	return v, resp, err
+{{- else if .Retry }}
+	return s.client.Retry({{ .Context }}, func({{ .Context }} context.Context) (*http.Response, error) {
+		return s.next.{{ .Name }}({{ .Args }})
+	})
+{{- else }}
+	return s.next.{{ .Name }}({{ .Args }})
+{{- end }}
+}
+{{- else }}
+
+// {{ .Name }} calls {{ .Name }} of the decorated service.
+func (s *{{ $svc.PoliciesName }}{{ $svc.TypeArgs }}) {{ .Signature }} {
+	return s.next.{{ .Name }}({{ .Args }})
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if and .Calls .InstrumentedName }}
+{{- $svc := . }}
+
+// {{ .InstrumentedName }} decorates {{ .Decorated }} with the Instrumentation of the client.
+type {{ .InstrumentedName }}{{ .TypeParams }} struct {
+	next            {{ .Decorated }}
+	instrumentation httpclient.Instrumentation
+}
+{{- if not .TypeParams }}
+
+var _ {{ .Decorated }} = &{{ .InstrumentedName }}{}
+{{- end }}
+{{- range .Calls }}
+
+// {{ .Name }} calls {{ .Name }} of the decorated service.
+func (s *{{ $svc.InstrumentedName }}{{ $svc.TypeArgs }}) {{ .Signature }} {
+	{{ .Context }}, end := s.instrumentation.Start({{ .Context }}, "{{ $svc.Decorated }}", "{{ .Name }}")
+{{- if .Result }}
+
+	v, resp, err := s.next.{{ .Name }}({{ .Args }})
+	end(resp, err)
+
+	return v, resp, err
+{{- else }}
+
+	resp, err := s.next.{{ .Name }}({{ .Args }})
+	end(resp, err)
+
+	return resp, err
+{{- end }}
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- define "service file" }}
+// Code generated by httpclient-gen-go; DO NOT EDIT.
+{{- if not .Timestamp.IsZero }}
+// This file was generated by robots at
+// {{ .Timestamp }}
+{{- end }}
+
+package {{ .Package }}
+
+import (
+	"net/http"
+	"net/url"
+)
+{{ template "service" .Service }}
+{{- end }}
+{{- define "iterator" }}
+{{- $p := .Paginate }}
+// {{ $p.Iterator }} iterates over the items of all pages returned by {{ .Name }} ({{ .Route }}).
+type {{ $p.Iterator }} struct {
+	client *httpclient.Client
+{{- if not $p.Link }}
+	first  string
+{{- end }}
+	next   string
+	items  []{{ $p.Item }}
+	cur    {{ $p.Item }}
+	resp   *http.Response
+	err    error
+}
+
+// New{{ $p.Iterator }} returns an iterator over the items of all pages returned by {{ .Name }}.
+func (c *Client) New{{ $p.Iterator }}({{ $p.Params }}) *{{ $p.Iterator }} {
+	it := &{{ $p.Iterator }}{client: c.Client, next: {{ .Path }}}
+
+{{- if or $p.Instrumented $p.Policies }}
+	svc := c.{{ $p.Field }}
+{{- if $p.Instrumented }}
+
+	if d, ok := svc.(*{{ $p.Instrumented }}); ok {
+		svc = d.next
+	}
+{{- end }}
+{{- if $p.Policies }}
+
+	if d, ok := svc.(*{{ $p.Policies }}); ok {
+		svc = d.next
+	}
+{{- end }}
+
+	if s, ok := svc.(*{{ $p.Impl }}); ok {
+		it.client = s.client
+	}
+{{- else }}
+	if s, ok := c.{{ $p.Field }}.(*{{ $p.Impl }}); ok {
+		it.client = s.client
+	}
+{{- end }}
+{{- if .Query }}
+	it.next, it.err = httpclient.QueryOptions(it.next, {{ .Query }})
+{{- end }}
+{{- if not $p.Link }}
+	it.first = it.next
+{{- end }}
+
+	return it
+}
+
+// Next advances the iterator to the next item and fetches the next page if required. It returns
+// false if there are no more items or an error occurred (see Err).
+func (it *{{ $p.Iterator }}) Next(ctx context.Context) bool {
+	for len(it.items) == 0 {
+		if it.err != nil || it.next == "" {
+			return false
+		}
+
+		it.fetch(ctx)
+	}
+
+	it.cur, it.items = it.items[0], it.items[1:]
+
+	return true
+}
+
+// Value returns the current item.
+func (it *{{ $p.Iterator }}) Value() {{ $p.Item }} {
+	return it.cur
+}
+
+// Err returns the first error that occurred while fetching the pages.
+func (it *{{ $p.Iterator }}) Err() error {
+	return it.err
+}
+
+// Response returns the response of the last fetched page.
+func (it *{{ $p.Iterator }}) Response() *http.Response {
+	return it.resp
+}
+
+// All returns all items as iterator for range-over-func loops. An error is yielded as last
+// element.
+func (it *{{ $p.Iterator }}) All(ctx context.Context) iter.Seq2[{{ $p.Item }}, error] {
+	return func(yield func({{ $p.Item }}, error) bool) {
+		for it.Next(ctx) {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			var zero {{ $p.Item }}
+
+			yield(zero, err)
+		}
+	}
+}
+
+func (it *{{ $p.Iterator }}) fetch(ctx context.Context) {
+	req, err := it.client.NewRequest(http.MethodGet, it.next, nil)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	{{ .Value }}
+
+	it.resp, it.err = it.client.Do(ctx, req, {{ .ValueArg }})
+	if it.err != nil {
+		return
+	}
+{{- if $p.Link }}
+
+	it.items = v
+	it.next = httpclient.LinkURL(it.resp.Header, "next")
+
+	// a relative link is resolved against the URL of the response, not the base URL
+	if it.next != "" && it.resp.Request != nil {
+		u, err := it.resp.Request.URL.Parse(it.next)
+		if err != nil {
+			it.err = err
+			return
+		}
+
+		it.next = u.String()
+	}
+{{- else }}
+
+	it.items = v.{{ $p.ItemsField }}
+	it.next = ""
+
+	if v.{{ $p.TokenField }} == "" {
+		return
+	}
+
+	u, err := url.Parse(it.first)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	q := u.Query()
+	q.Set({{ printf "%q" $p.TokenParam }}, v.{{ $p.TokenField }})
+	u.RawQuery = q.Encode()
+	it.next = u.String()
+{{- end }}
+}
+{{- end }}
+`
+
+// mainTemplate is the name of the main template in a template directory.
+const mainTemplate = "client.tmpl"
+
+// format formats (and fixes the imports of) the generated code src for file with the
+// external tool goImports or, if it is empty, in-process.
+func format(file string, src []byte, goImports string) ([]byte, error) {
+	if goImports == "" {
+		out, err := imports.Process(file, src, nil)
+		if err != nil {
+			return out, fmt.Errorf("could not format generated code: %w", err)
+		}
+
+		return out, nil
+	}
+
+	// the external tool needs a file in the target directory to resolve the imports
+	tmp, err := ioutil.TempFile(filepath.Dir(file), ".httpclient-gen-*.go")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(src)
+	_ = tmp.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint: gosec // G204: Subprocess launched with variable
+	if out, err := exec.Command(goImports, "-w", tmp.Name()).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s: %w", string(out), err)
+	}
+
+	return ioutil.ReadFile(tmp.Name())
+}
+
+// diff writes a unified diff between the content of file and src to w and
+// reports whether they differ. A missing file is treated as empty.
+func diff(w io.Writer, file string, src []byte) (bool, error) {
+	cur, err := ioutil.ReadFile(file) // nolint: gosec // G304: file inclusion is intended
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if bytes.Equal(cur, src) {
+		return false, nil
+	}
+
+	err = difflib.WriteUnifiedDiff(w, difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(cur)),
+		B:        difflib.SplitLines(string(src)),
+		FromFile: file,
+		ToFile:   file + " (generated)",
+		Context:  3,
+	})
+
+	return true, err
+}
+
+// Data is passed to the (custom) template, it is returned by Scan.
+type Data struct {
+	Timestamp  time.Time
+	Path       string
+	Package    string
+	Imports    []string // import paths of the services declared in other packages
+	Instrument bool     // at least one service is instrumented
+	Decorate   bool     // at least one service is decorated (instrumented or with policies)
+	Split      bool     // the services are rendered into separate files (-split)
+	Services   []Service
+	Data       map[string]string // key/value pairs of -data and the configuration file
+
+	opts Options
+}
+
+// loadTemplate returns the embedded template or, if file or dir are set,
+// the custom template(s).
+func loadTemplate(file, dir string) (*template.Template, error) {
+	if file == "" && dir == "" {
+		return template.New("Client Type Template").Funcs(templateFuncs).Parse(codeTemplate)
+	}
+
+	// missing keys of .Data are empty, e.g. for {{ .Data.version | default "dev" }}
+	name := mainTemplate
+	t := template.New(name).Funcs(templateFuncs).Option("missingkey=zero")
+
+	if file != "" {
+		name = filepath.Base(file)
+		t = template.New(name).Funcs(templateFuncs).Option("missingkey=zero")
+
+		b, err := ioutil.ReadFile(file) // nolint: gosec // G304: file inclusion is intended
+		if err != nil {
+			return nil, fmt.Errorf("could not read template %s: %w", file, err)
+		}
+
+		if _, err := t.Parse(string(b)); err != nil {
+			return nil, fmt.Errorf("could not parse template %s: %w", file, err)
+		}
+	}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(files) > 0 {
+			if _, err := t.ParseFiles(files...); err != nil {
+				return nil, fmt.Errorf("could not parse templates in %s: %w", dir, err)
+			}
+		}
+	}
+
+	if t = t.Lookup(name); t == nil {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,237 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const reverseTemplate = `
+// Service interfaces extracted from the Impl types by httpclient-gen-go -reverse.
+
+package {{ .Package }}
+
+import (
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
+)
+{{- range .Interfaces }}
+
+// {{ .Name }} is the interface implemented by {{ .Impl }}.
+type {{ .Name }}{{ .TypeParams }} interface {
+{{- range .Methods }}
+{{- range .Doc }}
+	{{ . }}
+{{- end }}
+	{{ .Signature }}
+{{- end }}
+}
+{{- end }}
+`
+
+// reverseData is passed to the reverse template.
+type reverseData struct {
+	Package    string
+	Imports    []string
+	Interfaces []reverseInterface
+}
+
+// reverseInterface is a service interface extracted from an Impl type.
+type reverseInterface struct {
+	Name       string
+	Impl       string
+	TypeParams string
+	Methods    []reverseMethod
+}
+
+// reverseMethod is a method of an extracted service interface.
+type reverseMethod struct {
+	Doc       []string
+	Signature string
+}
+
+// reverse returns the formatted code of the service interfaces for the Impl types
+// (e.g. NodeImpl) declared in dir. The interfaces are named with the first suffix
+// (e.g. NodeService), Impl types with an existing interface are skipped. The code is
+// formatted for file.
+// nolint: funlen, gocyclo
+func reverse(dir, suffix, file, goImports string) ([]byte, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected exactly one package in %s, found %d", dir, len(pkgs))
+	}
+
+	data := reverseData{}
+	imports := map[string]bool{}
+	impls := map[string]*reverseInterface{}
+	names := []string{}
+
+	for name, p := range pkgs {
+		data.Package = name
+		decls := declaredTypes(p)
+
+		for _, f := range p.Files {
+			for _, d := range f.Decls {
+				t, ok := d.(*ast.GenDecl)
+				if !ok || t.Tok != token.TYPE {
+					continue
+				}
+
+				for _, s := range t.Specs {
+					ts, ok := s.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+
+					if _, ok := ts.Type.(*ast.StructType); !ok {
+						continue
+					}
+
+					svc := strings.TrimSuffix(ts.Name.Name, "Impl")
+					if svc == "" || svc == ts.Name.Name {
+						continue
+					}
+
+					if _, ok := decls.ifaces[svc+suffix]; ok {
+						continue
+					}
+
+					params := []string{}
+
+					for _, f := range typeParams(ts) {
+						for _, n := range f.Names {
+							params = append(params, n.Name+" "+types.ExprString(f.Type))
+						}
+					}
+
+					it := &reverseInterface{Name: svc + suffix, Impl: ts.Name.Name}
+					if len(params) > 0 {
+						it.TypeParams = "[" + strings.Join(params, ", ") + "]"
+					}
+
+					impls[ts.Name.Name] = it
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+
+		files := []string{}
+		for file := range p.Files {
+			files = append(files, file)
+		}
+
+		sort.Strings(files)
+
+		// methods are collected in a second pass, they may be declared before their type
+		for _, file := range files {
+			f := p.Files[file]
+			used := false
+
+			for _, d := range f.Decls {
+				fd, ok := d.(*ast.FuncDecl)
+				if !ok || fd.Recv == nil || len(fd.Recv.List) != 1 || !fd.Name.IsExported() {
+					continue
+				}
+
+				it, ok := impls[receiverType(fd.Recv.List[0].Type)]
+				if !ok {
+					continue
+				}
+
+				m := reverseMethod{
+					Signature: fd.Name.Name + strings.TrimPrefix(types.ExprString(fd.Type), "func"),
+				}
+
+				if fd.Doc != nil {
+					for _, c := range fd.Doc.List {
+						m.Doc = append(m.Doc, c.Text)
+					}
+				}
+
+				it.Methods = append(it.Methods, m)
+				used = true
+			}
+
+			// unused imports are removed when the code is formatted
+			if used {
+				for _, i := range f.Imports {
+					imports[importSpec(i)] = true
+				}
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, n := range names {
+		if len(impls[n].Methods) == 0 {
+			continue
+		}
+
+		data.Interfaces = append(data.Interfaces, *impls[n])
+	}
+
+	if len(data.Interfaces) == 0 {
+		return nil, fmt.Errorf("no Impl types without service interface found in %s", dir)
+	}
+
+	for i := range imports {
+		data.Imports = append(data.Imports, i)
+	}
+
+	sort.Strings(data.Imports)
+
+	t, err := template.New("Reverse Template").Parse(reverseTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := t.Execute(buf, data); err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	return format(file, buf.Bytes(), goImports)
+}
+
+// receiverType returns the name of the receiver type expression e (e.g. *NodeImpl or CRUDImpl[T]).
+func receiverType(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+
+	return ""
+}
+
+// importSpec returns the import spec i as it is written in the source.
+func importSpec(i *ast.ImportSpec) string {
+	if i.Name != nil {
+		return i.Name.Name + " " + i.Path.Value
+	}
+
+	return i.Path.Value
+}
@@ -0,0 +1,186 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const testTemplate = `
+// Test scaffolding generated by httpclient-gen-go -tests, add your test cases.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+{{- $svc := .Service }}
+{{- range .Tests }}
+
+func Test{{ $svc.FieldName }}{{ .Method }}(t *testing.T) {
+	tests := []struct {
+		name    string
+{{- range .Fields }}
+		{{ .Name }} {{ .Type }}
+{{- end }}
+		status  int
+{{- if .Result }}
+		resp    {{ .Result }}
+{{- end }}
+		wantErr bool
+	}{
+		{name: "ok", status: http.StatusOK{{ if .Result }}, resp: {{ .OK }}{{ end }}},
+		{name: "error", status: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc({{ printf "%q" .Pattern }}, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", httpclient.ContentTypeJSON)
+				w.WriteHeader(tt.status)
+{{- if .Result }}
+
+				if tt.status < 300 {
+					_ = json.NewEncoder(w).Encode(tt.resp)
+				}
+{{- end }}
+			})
+
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			c, err := NewClient(srv.URL + "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+{{- if .Result }}
+
+			got, _, err := c.{{ $svc.FieldName }}.{{ .Method }}(context.Background(){{ range .Fields }}, tt.{{ .Name }}{{ end }})
+{{- else }}
+
+			_, err = c.{{ $svc.FieldName }}.{{ .Method }}(context.Background(){{ range .Fields }}, tt.{{ .Name }}{{ end }})
+{{- end }}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("{{ .Method }}() error = %v, wantErr %v", err, tt.wantErr)
+			}
+{{- if .Result }}
+
+			if err == nil && !reflect.DeepEqual(got, tt.resp) {
+				t.Errorf("{{ .Method }}() = %v, want %v", got, tt.resp)
+			}
+{{- end }}
+		})
+	}
+}
+{{- end }}
+`
+
+// testCase contains all information to generate the test of a service method.
+type testCase struct {
+	Method  string
+	Pattern string  // http.ServeMux pattern, e.g. GET /posts/{id}
+	Fields  []param // method parameters (without context)
+	Result  string  // type of the returned value, empty if there is none
+	OK      string  // non-nil value of Result
+}
+
+// testReserved are the names of the fields of the generated test cases.
+// nolint: gochecknoglobals
+var testReserved = map[string]bool{"name": true, "status": true, "resp": true, "wantErr": true}
+
+// generateTests returns a test file (e.g. node_client_test.go) with a test server and test
+// skeletons for every service with a generated Impl type in dir.
+func generateTests(data *Data, dir string) ([]File, error) {
+	t, err := template.New("Test Template").Parse(testTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []File{}
+
+	for _, s := range data.Services {
+		if !s.Generate || s.TypeParams != "" {
+			continue
+		}
+
+		file := filepath.Join(dir, strings.ToLower(s.FieldName)+"_client_test.go")
+		tests := []testCase{}
+
+		for _, m := range s.Methods {
+			tests = append(tests, newTestCase(m))
+		}
+
+		buf := new(bytes.Buffer)
+
+		err := t.Execute(buf, struct {
+			Package string
+			Service Service
+			Tests   []testCase
+		}{data.Package, s, tests})
+		if err != nil {
+			return nil, fmt.Errorf("could not render template: %w", err)
+		}
+
+		out, err := format(file, buf.Bytes(), data.opts.GoImports)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, File{Name: file, Content: out})
+	}
+
+	return files, nil
+}
+
+// newTestCase returns the test case for method m.
+func newTestCase(m Method) testCase {
+	tc := testCase{
+		Method:  m.Name,
+		Pattern: muxPattern(m.Route),
+		Result:  m.Result,
+	}
+
+	for _, p := range m.params[1:] {
+		if testReserved[p.Name] {
+			p.Name += "Arg"
+		}
+
+		tc.Fields = append(tc.Fields, p)
+	}
+
+	if m.Result != "" {
+		tc.OK = nonNilValue(m.Result)
+	}
+
+	return tc
+}
+
+// nonNilValue returns a non-nil value of type typ, which survives a JSON round trip,
+// e.g. new(Post) for *Post.
+func nonNilValue(typ string) string {
+	e, err := parser.ParseExpr(typ)
+	if err != nil {
+		return typ + "{}"
+	}
+
+	switch e.(type) {
+	case *ast.StarExpr:
+		return "new(" + typ[1:] + ")"
+	case *ast.ArrayType, *ast.MapType:
+		return typ + "{}"
+	default:
+		return zeroValue(e)
+	}
+}
@@ -0,0 +1,619 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Service contains all names for the code generation of a service.
+type Service struct {
+	FieldName     string
+	VarName       string
+	TypeName      string
+	InterfaceName string
+
+	// ImportPath is the import path and Constructor the function creating the Impl type (e.g.
+	// posts.NewPostImpl) of services declared in other packages than the generated code.
+	ImportPath  string
+	Constructor string
+
+	// ImplName is the name of the (generic) Impl type, TypeParams and TypeArgs are the type
+	// parameters (e.g. [T any]) and arguments (e.g. [T]) of generic services.
+	ImplName   string
+	TypeParams string
+	TypeArgs   string
+	typeParams map[string]bool
+
+	// Error is the struct type of the error responses of the service and ErrorType the
+	// generated error type (//httpclient:error directive).
+	Error     string
+	ErrorType string
+
+	// BasePath is the prefix of all routes of the service (//httpclient:basepath directive).
+	BasePath string
+
+	// Generate is set if the Impl type is generated (-impl).
+	Generate bool
+	Methods  []Method
+
+	// Commands are the commands of the command line client (-cli).
+	Commands []Command
+
+	// Instrumented is the type of the decorator (-instrument) wrapping the service, e.g.
+	// NodeInstrumented or CRUDInstrumented[Post], InstrumentedName the declared type name and
+	// Decorated the decorated interface (e.g. CRUDService[T]). Calls are the decorated methods,
+	// they are only set for the first instantiation of generic services.
+	Instrumented     string
+	InstrumentedName string
+	Decorated        string
+	Calls            []Call
+
+	// Policies is the type of the decorator applying the timeouts and retries of the methods,
+	// e.g. NodePolicies or CRUDPolicies[Post], PoliciesName the declared type name.
+	Policies     string
+	PoliciesName string
+
+	// docs is the documentation (-docs) of the service and typeDocs the documentation of the
+	// struct types used by its methods, they are only set for the first instantiation.
+	docs     *serviceDoc
+	typeDocs []typeDoc
+
+	// doc is the doc comment and pluginMethods are the methods of the service passed to
+	// plugins.
+	doc           string
+	pluginMethods []PluginMethod
+
+	// dir, pkg, iface and impl are the directory, the package and the (unqualified) names
+	// of the interface and Impl type, used to validate existing Impl types.
+	dir   string
+	pkg   string
+	iface string
+	impl  string
+}
+
+// packageDirs returns root and all its subdirectories. Directories ignored by
+// the go tool (vendor, testdata and names starting with . or _) are skipped.
+func packageDirs(root string) ([]string, error) {
+	dirs := []string{}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if name := info.Name(); path != root && ignoreDir(name) {
+			return filepath.SkipDir
+		}
+
+		dirs = append(dirs, path)
+
+		return nil
+	})
+
+	return dirs, err
+}
+
+// sameDir reports whether the paths a and b are the same directory.
+func sameDir(a, b string) (bool, error) {
+	a, err := filepath.Abs(a)
+	if err != nil {
+		return false, err
+	}
+
+	b, err = filepath.Abs(b)
+
+	return a == b, err
+}
+
+// importPath returns the import path of the package in dir with the module path of the nearest
+// go.mod file or, if there is none, an empty string (the imports are fixed when formatting).
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		b, err := ioutil.ReadFile(filepath.Join(root, "go.mod")) // nolint: gosec // G304: file inclusion is intended
+		if err == nil {
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+
+			return path.Join(modfile.ModulePath(b), filepath.ToSlash(rel)), nil
+		}
+
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if filepath.Dir(root) == root {
+			return "", nil
+		}
+	}
+}
+
+// ignoreDir reports whether the go tool ignores a directory with this name.
+func ignoreDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// matcher selects service interfaces by their type name and the name of
+// the file they are declared in.
+type matcher struct {
+	suffixes []string
+	re       *regexp.Regexp
+	include  []string
+	exclude  []string
+}
+
+// newMatcher returns a matcher for the comma separated suffixes or, if
+// expr is not empty, for the regular expression expr. include and exclude
+// are comma separated glob patterns for file and type names.
+func newMatcher(suffixes, expr, include, exclude string) (*matcher, error) {
+	m := &matcher{}
+
+	var err error
+
+	if m.include, err = patterns(include); err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+
+	if m.exclude, err = patterns(exclude); err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	if expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match expression: %w", err)
+		}
+
+		m.re = re
+
+		return m, nil
+	}
+
+	for _, s := range strings.Split(suffixes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			m.suffixes = append(m.suffixes, s)
+		}
+	}
+
+	if len(m.suffixes) == 0 {
+		return nil, errors.New("suffix cannot be empty")
+	}
+
+	return m, nil
+}
+
+// patterns splits the comma separated glob patterns in list.
+func patterns(list string) ([]string, error) {
+	p := []string{}
+
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		if _, err := filepath.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", s, err)
+		}
+
+		p = append(p, s)
+	}
+
+	return p, nil
+}
+
+// selects reports whether the interface type typeName declared in file passes
+// the include and exclude patterns. A pattern matches either the base name of
+// the file (e.g. *_test.go) or the type name (e.g. Mock*).
+func (m *matcher) selects(file, typeName string) bool {
+	match := func(patterns []string) bool {
+		for _, p := range patterns {
+			if ok, _ := filepath.Match(p, filepath.Base(file)); ok {
+				return true
+			}
+
+			if ok, _ := filepath.Match(p, typeName); ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(m.include) > 0 && !match(m.include) {
+		return false
+	}
+
+	return !match(m.exclude)
+}
+
+// serviceName returns the service name for the interface type name and
+// whether the type is a service at all.
+func (m *matcher) serviceName(typeName string) (string, bool) {
+	if m.re != nil {
+		match := m.re.FindStringSubmatch(typeName)
+		if match == nil {
+			return "", false
+		}
+
+		if len(match) > 1 && match[1] != "" {
+			return match[1], true
+		}
+
+		return typeName, true
+	}
+
+	for _, s := range m.suffixes {
+		if strings.HasSuffix(typeName, s) && typeName != s {
+			return strings.TrimSuffix(typeName, s), true
+		}
+	}
+
+	return "", false
+}
+
+// findServices returns all services declared in the go files of dir.
+func findServices(dir string, m *matcher, opts *Options) ([]Service, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// the services of the package in the directory of the output file are local, the others
+	// are imported
+	out, err := sameDir(dir, filepath.Dir(opts.Out))
+	if err != nil {
+		return nil, err
+	}
+
+	services := []Service{}
+
+	for _, p := range pkgs {
+		decls := declaredTypes(p)
+		local := out && p.Name == opts.Package
+
+		if !out && p.Name == opts.Package {
+			return nil, fmt.Errorf("%s: package %s is not in the directory of %s and cannot be imported by package %s", dir, p.Name, opts.Out, opts.Package)
+		}
+
+		imp := ""
+		if !out {
+			if imp, err = importPath(dir); err != nil {
+				return nil, err
+			}
+		}
+
+		for file, f := range p.Files {
+			for _, d := range f.Decls {
+				t, ok := d.(*ast.GenDecl)
+				if !ok || t.Tok != token.TYPE {
+					continue
+				}
+
+				for _, s := range t.Specs {
+					ts, ok := s.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+
+					if _, ok := ts.Type.(*ast.InterfaceType); !ok {
+						continue
+					}
+
+					if !m.selects(file, ts.Name.String()) || opts.Services[ts.Name.String()].Skip {
+						continue
+					}
+
+					name, ok := m.serviceName(ts.Name.String())
+					if !ok {
+						continue
+					}
+
+					doc := ts.Doc
+					if doc == nil && len(t.Specs) == 1 {
+						doc = t.Doc
+					}
+
+					svc, err := newService(p.Name, local, name, ts, doc, decls, opts)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", ts.Name.String(), err)
+					}
+
+					for i := range svc {
+						svc[i].dir = dir
+						svc[i].ImportPath = imp
+					}
+
+					services = append(services, svc...)
+				}
+			}
+		}
+	}
+
+	return services, nil
+}
+
+// newService returns the service(s) for the interface type ts in package pkg, which is local if
+// it is the package of the generated code. Generic interfaces return a service for every
+// instantiation (//httpclient:instantiate directive).
+// nolint: funlen, gocyclo
+func newService(pkg string, local bool, name string, ts *ast.TypeSpec, doc *ast.CommentGroup, decls *declarations, opts *Options) ([]Service, error) {
+	iface := ts.Name.String()
+	typeName := fmt.Sprintf("%s.%sImpl", pkg, name)   // {name}Impl
+	interfaceName := fmt.Sprintf("%s.%s", pkg, iface) // {name}Service
+	constructor := fmt.Sprintf("%s.New%sImpl", pkg, name)
+
+	if local {
+		typeName = fmt.Sprintf("%sImpl", name)
+		interfaceName = iface
+		constructor = ""
+	}
+
+	impl := name + "Impl"
+
+	cfg := opts.Services[iface]
+	if cfg.Field != "" {
+		name = cfg.Field
+	}
+
+	d := directives(doc)
+	if _, ok := d["error"]; !ok && cfg.Error != "" {
+		d["error"] = cfg.Error
+	}
+
+	if _, ok := d["basepath"]; !ok && cfg.BasePath != "" {
+		d["basepath"] = cfg.BasePath
+	}
+
+	svc := Service{
+		FieldName:     name,
+		VarName:       strings.ToLower(name),
+		TypeName:      typeName,
+		InterfaceName: interfaceName,
+		Constructor:   constructor,
+		ImplName:      typeName,
+		pkg:           pkg,
+		iface:         iface,
+		impl:          impl,
+	}
+
+	if e, ok := d["error"]; ok {
+		if !local {
+			return nil, fmt.Errorf("error types can only be generated in package %s", pkg)
+		}
+
+		if _, ok := decls.structs[e]; !ok {
+			return nil, fmt.Errorf("error schema %s is not a struct of this package", e)
+		}
+
+		svc.Error = e
+		svc.ErrorType = name + "Error"
+	}
+
+	svc.BasePath = d["basepath"]
+
+	// generic interface
+	params, args := []string{}, []string{}
+	svc.typeParams = map[string]bool{}
+
+	for _, f := range typeParams(ts) {
+		for _, n := range f.Names {
+			params = append(params, n.Name+" "+types.ExprString(f.Type))
+			args = append(args, n.Name)
+			svc.typeParams[n.Name] = true
+		}
+	}
+
+	if len(args) > 0 && !local {
+		return nil, fmt.Errorf("generic services can only be used in package %s", pkg)
+	}
+
+	if len(args) > 0 {
+		svc.TypeParams = "[" + strings.Join(params, ", ") + "]"
+		svc.TypeArgs = "[" + strings.Join(args, ", ") + "]"
+	}
+
+	if opts.Impl {
+		if err := svc.addMethods(pkg, local, ts.Type.(*ast.InterfaceType), decls); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.CLI && svc.TypeParams == "" {
+		commands, err := newCommands(ts.Type.(*ast.InterfaceType), decls)
+		if err != nil {
+			return nil, err
+		}
+
+		svc.Commands = commands
+	}
+
+	policies := hasPolicies(ts.Type.(*ast.InterfaceType), decls)
+	if policies && !local {
+		return nil, fmt.Errorf("timeouts and retries can only be generated in package %s", pkg)
+	}
+
+	if (opts.Instrument || policies) && local {
+		calls, err := newCalls(ts.Type.(*ast.InterfaceType), decls)
+		if err != nil {
+			return nil, err
+		}
+
+		svc.Calls = calls
+		svc.Decorated = iface + svc.TypeArgs
+
+		if opts.Instrument {
+			svc.InstrumentedName = strings.TrimSuffix(impl, "Impl") + "Instrumented"
+			svc.Instrumented = svc.InstrumentedName
+		}
+
+		if policies {
+			svc.PoliciesName = strings.TrimSuffix(impl, "Impl") + "Policies"
+			svc.Policies = svc.PoliciesName
+		}
+
+		for _, m := range svc.Methods {
+			if m.Paginate != nil {
+				m.Paginate.Instrumented = svc.InstrumentedName
+				m.Paginate.Policies = svc.PoliciesName
+			}
+		}
+	}
+
+	if svc.BasePath != "" && !svc.Generate {
+		return nil, errors.New("basepath requires a generated Impl type (-impl)")
+	}
+
+	if opts.Docs {
+		sd, td, err := newServiceDoc(&svc, ts, doc, decls)
+		if err != nil {
+			return nil, err
+		}
+
+		svc.docs, svc.typeDocs = sd, td
+	}
+
+	if opts.Plugins {
+		methods, err := newPluginMethods(ts.Type.(*ast.InterfaceType), decls)
+		if err != nil {
+			return nil, err
+		}
+
+		svc.doc, svc.pluginMethods = strings.TrimSpace(doc.Text()), methods
+	}
+
+	if svc.TypeParams == "" {
+		return []Service{svc}, nil
+	}
+
+	for _, m := range svc.Methods {
+		if m.Paginate != nil {
+			return nil, fmt.Errorf("%s: generic services cannot be paginated", m.Name)
+		}
+	}
+
+	instances := directiveList(doc, "instantiate")
+	if len(instances) == 0 {
+		return nil, errors.New("generic services need an instantiate directive (//httpclient:instantiate <type arguments> [<field name>])")
+	}
+
+	services := []Service{}
+
+	for i, inst := range instances {
+		fields := strings.Fields(inst)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, fmt.Errorf("invalid instantiate directive %q", inst)
+		}
+
+		s := svc
+		s.Generate = svc.Generate && i == 0
+		s.InterfaceName = fmt.Sprintf("%s[%s]", svc.InterfaceName, fields[0])
+		s.TypeName = fmt.Sprintf("%s[%s]", svc.TypeName, fields[0])
+
+		switch {
+		case len(fields) == 2:
+			s.FieldName = fields[1]
+		case len(instances) > 1:
+			return nil, fmt.Errorf("instantiate directive %q needs a field name", inst)
+		}
+
+		s.VarName = strings.ToLower(s.FieldName)
+
+		if s.Instrumented != "" {
+			s.Instrumented = fmt.Sprintf("%s[%s]", svc.InstrumentedName, fields[0])
+		}
+
+		if s.Policies != "" {
+			s.Policies = fmt.Sprintf("%s[%s]", svc.PoliciesName, fields[0])
+		}
+
+		if i > 0 {
+			s.Calls = nil
+			s.docs, s.typeDocs = nil, nil
+		}
+
+		if s.Error != "" {
+			s.ErrorType = s.FieldName + "Error"
+		}
+
+		services = append(services, s)
+	}
+
+	return services, nil
+}
+
+// typeParams returns the type parameters of ts.
+func typeParams(ts *ast.TypeSpec) []*ast.Field {
+	if ts.TypeParams == nil {
+		return nil
+	}
+
+	return ts.TypeParams.List
+}
+
+// declarations contains the struct and interface types declared in a package.
+type declarations struct {
+	structs map[string]*ast.StructType
+	ifaces  map[string]*ast.InterfaceType
+	docs    map[string]*ast.CommentGroup
+}
+
+// declaredTypes returns all struct and interface types declared in package p.
+func declaredTypes(p *ast.Package) *declarations {
+	decls := &declarations{
+		structs: map[string]*ast.StructType{},
+		ifaces:  map[string]*ast.InterfaceType{},
+		docs:    map[string]*ast.CommentGroup{},
+	}
+
+	for _, f := range p.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if gd, ok := n.(*ast.GenDecl); ok {
+				for _, spec := range gd.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						decls.docs[ts.Name.Name] = ts.Doc
+						if ts.Doc == nil && len(gd.Specs) == 1 {
+							decls.docs[ts.Name.Name] = gd.Doc
+						}
+					}
+				}
+			}
+
+			if ts, ok := n.(*ast.TypeSpec); ok {
+				switch t := ts.Type.(type) {
+				case *ast.StructType:
+					decls.structs[ts.Name.Name] = t
+				case *ast.InterfaceType:
+					decls.ifaces[ts.Name.Name] = t
+				}
+			}
+
+			return true
+		})
+	}
+
+	return decls
+}
@@ -0,0 +1,825 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const modelsTemplate = `
+// Code generated by httpclient-gen-go from JSON Schema; DO NOT EDIT.
+
+package {{ .Package }}
+{{- if .Patterns }}
+
+// nolint: gochecknoglobals
+var (
+{{- range .Patterns }}
+	{{ .Name }} = regexp.MustCompile({{ printf "%q" .Expr }})
+{{- end }}
+)
+{{- end }}
+{{- range .Models }}
+{{- $m := . }}
+
+{{ .Doc }}
+{{- if .Fields }}
+type {{ .Name }} struct {
+{{- range .Fields }}
+{{- if .Doc }}
+	{{ .Doc }}
+{{- end }}
+	{{ if .Name }}{{ .Name }} {{ end }}{{ .Type }}{{ if .Tag }} ` + "`json:\"{{ .Tag }}\"`" + `{{ end }}
+{{- end }}
+}
+
+// Validate reports the first value of v, which violates the schema.
+func (v *{{ .Name }}) Validate() error {
+{{- range .Checks }}
+	{{ . }}
+{{ end }}
+	return nil
+}
+{{- else if .Enum }}
+type {{ .Name }} {{ .Type }}
+
+// Values of {{ .Name }}.
+const (
+{{- range .Enum }}
+	{{ .Name }} {{ $m.Name }} = {{ .Value }}
+{{- end }}
+)
+
+// Validate returns an error if v is not a value of {{ .Name }}.
+func (v {{ .Name }}) Validate() error {
+	switch v {
+	case {{ range $i, $e := .Enum }}{{ if $i }}, {{ end }}{{ $e.Name }}{{ end }}:
+		return nil
+	default:
+		return fmt.Errorf("invalid {{ .Name }} %v", v)
+	}
+}
+{{- else }}
+type {{ .Name }} {{ .Type }}
+{{- end }}
+{{- end }}
+`
+
+// schema is a JSON Schema (the subset used to generate the models).
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	Type                 schemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Properties           properties         `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Enum                 []interface{}      `json:"enum"`
+	Definitions          map[string]*schema `json:"definitions"`
+	Defs                 map[string]*schema `json:"$defs"`
+	AllOf                []*schema          `json:"allOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+	OneOf                []*schema          `json:"oneOf"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+}
+
+// schemaType is the type of a schema, a single type or a list of types (e.g. ["string", "null"]).
+type schemaType []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *schemaType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = schemaType{s}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(t))
+}
+
+// properties are the properties of an object schema in the order of the schema file.
+type properties struct {
+	names   []string
+	schemas map[string]*schema
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *properties) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	p.schemas = map[string]*schema{}
+
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		name, _ := t.(string)
+		s := &schema{}
+
+		if err := dec.Decode(s); err != nil {
+			return err
+		}
+
+		p.names = append(p.names, name)
+		p.schemas[name] = s
+	}
+
+	return nil
+}
+
+// model is a generated type.
+type model struct {
+	Name   string
+	Doc    string // doc comment
+	Type   string // type of non-struct models, e.g. string or []Node
+	Fields []modelField
+	Enum   []enumConst
+	Checks []string // validation code of structs
+}
+
+// modelField is a field of a generated struct.
+type modelField struct {
+	Name string
+	Type string
+	Tag  string // json tag
+	Doc  string
+
+	json     string
+	required bool
+	optional bool // not required or nullable
+	schema   *schema
+}
+
+// enumConst is a constant of an enum type.
+type enumConst struct {
+	Name  string
+	Value string
+}
+
+// pattern is a compiled pattern of the validation.
+type pattern struct {
+	Name string
+	Expr string
+}
+
+// schemaFile is a loaded schema file.
+type schemaFile struct {
+	name string // type name of the root schema
+}
+
+// modelGenerator generates the models of JSON Schema files.
+type modelGenerator struct {
+	files    map[string]*schemaFile
+	models   map[string]*model
+	defined  map[*schema]string
+	refs     map[string]string // referenced type names with the reference
+	patterns []pattern
+}
+
+// generateModels returns the formatted code of the models (-models) in package pkg for file
+// of the JSON Schema files (-schema).
+func generateModels(paths []string, file, pkg, goImports string) ([]byte, error) {
+	g := &modelGenerator{
+		files:   map[string]*schemaFile{},
+		models:  map[string]*model{},
+		defined: map[*schema]string{},
+		refs:    map[string]string{},
+	}
+
+	files := []string{}
+
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+			f, err := filepath.Glob(filepath.Join(p, "*.json"))
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, f...)
+
+			continue
+		}
+
+		files = append(files, p)
+	}
+
+	for _, file := range files {
+		if _, err := g.file(file); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, ref := range g.refs {
+		if _, ok := g.models[name]; !ok {
+			return nil, fmt.Errorf("unresolved reference %s", ref)
+		}
+	}
+
+	names := []string{}
+	for n := range g.models {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	models := []*model{}
+
+	for _, n := range names {
+		m := g.models[n]
+
+		for i, f := range m.Fields {
+			if f.optional && (g.isStruct(f.Type) || f.Type == "time.Time" || nullable(f.schema)) {
+				m.Fields[i].Type = "*" + f.Type
+			}
+		}
+
+		models = append(models, m)
+	}
+
+	for _, m := range models {
+		for _, f := range m.Fields {
+			m.Checks = append(m.Checks, g.checks(m.Name, f)...)
+		}
+	}
+
+	t, err := template.New("Models Template").Parse(modelsTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = t.Execute(buf, struct {
+		Package  string
+		Patterns []pattern
+		Models   []*model
+	}{pkg, g.patterns, models})
+	if err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	return format(file, buf.Bytes(), goImports)
+}
+
+// file loads the schema file (once) and generates the models of its root schema and
+// definitions.
+func (g *modelGenerator) file(name string) (*schemaFile, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := g.files[abs]; ok {
+		return f, nil
+	}
+
+	b, err := ioutil.ReadFile(abs) // nolint: gosec // G304: file inclusion is intended
+	if err != nil {
+		return nil, fmt.Errorf("could not read schema: %w", err)
+	}
+
+	s := &schema{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("could not parse schema %s: %w", name, err)
+	}
+
+	f := &schemaFile{name: s.Title}
+	if f.name == "" {
+		f.name = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		f.name = strings.TrimSuffix(f.name, ".schema")
+	}
+
+	f.name = goName(f.name)
+	g.files[abs] = f
+
+	defs := s.Definitions
+	if len(defs) == 0 {
+		defs = s.Defs
+	}
+
+	keys := []string{}
+	for k := range defs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := g.named(goName(k), defs[k], abs); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", name, k, err)
+		}
+	}
+
+	if len(s.Properties.names) > 0 || len(s.Enum) > 0 || len(s.AllOf) > 0 || len(s.Type) > 0 {
+		if _, err := g.named(f.name, s, abs); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return f, nil
+}
+
+// ref returns the type name of the reference ref in the schema file.
+func (g *modelGenerator) ref(ref, file string) (string, error) {
+	target, fragment := ref, ""
+	if i := strings.Index(ref, "#"); i >= 0 {
+		target, fragment = ref[:i], ref[i+1:]
+	}
+
+	if target != "" {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(file), target)
+		}
+
+		f, err := g.file(target)
+		if err != nil {
+			return "", err
+		}
+
+		if fragment == "" || fragment == "/" {
+			g.refs[f.name] = ref
+			return f.name, nil
+		}
+
+		file = target
+	}
+
+	if fragment == "" || fragment == "/" {
+		f, err := g.file(file)
+		if err != nil {
+			return "", err
+		}
+
+		g.refs[f.name] = ref
+
+		return f.name, nil
+	}
+
+	for _, prefix := range []string{"/definitions/", "/$defs/"} {
+		if strings.HasPrefix(fragment, prefix) && !strings.Contains(fragment[len(prefix):], "/") {
+			name := goName(fragment[len(prefix):])
+			g.refs[name] = ref
+
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported reference %s", ref)
+}
+
+// named generates the model name for the schema s in file.
+func (g *modelGenerator) named(name string, s *schema, file string) (string, error) {
+	if n, ok := g.defined[s]; ok {
+		return n, nil
+	}
+
+	if _, ok := g.models[name]; ok {
+		return "", fmt.Errorf("duplicate type %s", name)
+	}
+
+	g.defined[s] = name
+	m := &model{Name: name, Doc: doc(name, s)}
+	g.models[name] = m
+
+	if s.Ref != "" {
+		t, err := g.ref(s.Ref, file)
+		if err != nil {
+			return "", err
+		}
+
+		m.Type = t
+
+		return name, nil
+	}
+
+	if len(s.Enum) > 0 {
+		return name, g.enum(m, s)
+	}
+
+	props, required := s.Properties, s.Required
+
+	for _, sub := range s.AllOf {
+		if sub.Ref != "" {
+			t, err := g.ref(sub.Ref, file)
+			if err != nil {
+				return "", err
+			}
+
+			m.Fields = append(m.Fields, modelField{Type: t}) // embedded
+
+			continue
+		}
+
+		props.names = append(props.names, sub.Properties.names...)
+		if props.schemas == nil {
+			props.schemas = map[string]*schema{}
+		}
+
+		for k, v := range sub.Properties.schemas {
+			props.schemas[k] = v
+		}
+
+		required = append(required, sub.Required...)
+	}
+
+	if len(props.names) == 0 && len(m.Fields) == 0 {
+		t, err := g.goType(name, s, file)
+		if err != nil {
+			return "", err
+		}
+
+		m.Type = t
+
+		return name, nil
+	}
+
+	for _, p := range props.names {
+		ps := props.schemas[p]
+		f := modelField{
+			Name:     goName(p),
+			Tag:      p,
+			json:     p,
+			required: contains(required, p),
+			optional: !contains(required, p) || nullable(ps),
+			schema:   ps,
+		}
+
+		if ps.Description != "" {
+			f.Doc = comment(strings.TrimSpace(ps.Description))
+		}
+
+		if f.optional {
+			f.Tag += ",omitempty"
+		}
+
+		t, err := g.goType(name+f.Name, ps, file)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", p, err)
+		}
+
+		f.Type = t
+
+		m.Fields = append(m.Fields, f)
+	}
+
+	return name, nil
+}
+
+// enum generates the enum type m of the schema s.
+func (g *modelGenerator) enum(m *model, s *schema) error {
+	m.Type = "string"
+
+	for _, v := range s.Enum {
+		c := enumConst{}
+
+		switch v := v.(type) {
+		case string:
+			c.Name, c.Value = m.Name+goName(v), strconv.Quote(v)
+		case float64:
+			if v != float64(int64(v)) {
+				return fmt.Errorf("%s: only string and integer enums are supported", m.Name)
+			}
+
+			m.Type = "int"
+			c.Name, c.Value = fmt.Sprintf("%s%d", m.Name, int64(v)), strconv.FormatInt(int64(v), 10)
+		default:
+			return fmt.Errorf("%s: only string and integer enums are supported", m.Name)
+		}
+
+		m.Enum = append(m.Enum, c)
+	}
+
+	for _, c := range m.Enum {
+		if (m.Type == "int") != (c.Value[0] != '"') {
+			return fmt.Errorf("%s: enum values must have the same type", m.Name)
+		}
+	}
+
+	return nil
+}
+
+// goType returns the go type of the schema s in file, name is the name of a generated type
+// if required (e.g. inline objects).
+// nolint: gocyclo
+func (g *modelGenerator) goType(name string, s *schema, file string) (string, error) {
+	if s.Ref != "" {
+		return g.ref(s.Ref, file)
+	}
+
+	if len(s.Enum) > 0 || len(s.Properties.names) > 0 || len(s.AllOf) > 1 {
+		return g.named(name, s, file)
+	}
+
+	if len(s.AllOf) == 1 {
+		return g.goType(name, s.AllOf[0], file)
+	}
+
+	if alt := append(append([]*schema{}, s.AnyOf...), s.OneOf...); len(alt) > 0 {
+		nonNull := []*schema{}
+
+		for _, a := range alt {
+			if len(a.Type) != 1 || a.Type[0] != "null" {
+				nonNull = append(nonNull, a)
+			}
+		}
+
+		if len(nonNull) == 1 {
+			return g.goType(name, nonNull[0], file)
+		}
+
+		return "json.RawMessage", nil
+	}
+
+	types := []string{}
+
+	for _, t := range s.Type {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+
+	if len(types) != 1 {
+		return "interface{}", nil
+	}
+
+	switch types[0] {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "time.Time", nil
+		case "byte":
+			return "[]byte", nil
+		}
+
+		return "string", nil
+	case "integer":
+		if s.Format == "int32" || s.Format == "int64" {
+			return s.Format, nil
+		}
+
+		return "int", nil
+	case "number":
+		if s.Format == "float" {
+			return "float32", nil
+		}
+
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "[]interface{}", nil
+		}
+
+		t, err := g.goType(name+"Item", s.Items, file)
+
+		return "[]" + t, err
+	case "object":
+		ap := &schema{}
+		if len(s.AdditionalProperties) == 0 || json.Unmarshal(s.AdditionalProperties, ap) != nil {
+			return "map[string]interface{}", nil
+		}
+
+		t, err := g.goType(name+"Value", ap, file)
+
+		return "map[string]" + t, err
+	}
+
+	return "", fmt.Errorf("unsupported type %s", types[0])
+}
+
+// checks returns the validation code of the field f of the struct type name.
+// nolint: funlen, gocyclo
+func (g *modelGenerator) checks(name string, f modelField) []string {
+	if f.schema == nil { // embedded
+		if g.validatable(f.Type) {
+			return []string{fmt.Sprintf("if err := v.%s.Validate(); err != nil {\n\t\treturn err\n\t}", f.Type)}
+		}
+
+		return nil
+	}
+
+	s := f.schema
+	field := "v." + f.Name
+	ptr := strings.HasPrefix(f.Type, "*")
+	typ := strings.TrimPrefix(f.Type, "*")
+	checks := []string{}
+	value := field
+
+	if ptr {
+		value = "*" + field
+	}
+
+	fail := func(format string, args ...interface{}) string {
+		return fmt.Sprintf("return errors.New(%q)", f.json+": "+fmt.Sprintf(format, args...))
+	}
+
+	nilable := ptr || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") ||
+		typ == "interface{}" || typ == "json.RawMessage"
+
+	if f.required && nilable && !nullable(s) {
+		checks = append(checks, fmt.Sprintf("if %s == nil {\n\t\t%s\n\t}", field, fail("is required")))
+	}
+
+	cond := []string{}
+	add := func(c, msg string) {
+		cond = append(cond, fmt.Sprintf("if %s {\n\t\t%s\n\t}", c, msg))
+	}
+
+	if typ == "string" {
+		if s.MinLength != nil {
+			add(fmt.Sprintf("utf8.RuneCountInString(%s) < %d", value, *s.MinLength), fail("must be at least %d characters long", *s.MinLength))
+		}
+
+		if s.MaxLength != nil {
+			add(fmt.Sprintf("utf8.RuneCountInString(%s) > %d", value, *s.MaxLength), fail("must be at most %d characters long", *s.MaxLength))
+		}
+
+		if s.Pattern != "" {
+			p := pattern{Name: fmt.Sprintf("pattern%s%s", name, f.Name), Expr: s.Pattern}
+			g.patterns = append(g.patterns, p)
+			add(fmt.Sprintf("!%s.MatchString(%s)", p.Name, value), fail("must match %s", s.Pattern))
+		}
+	}
+
+	if isNumber(typ) {
+		if s.Minimum != nil {
+			add(fmt.Sprintf("%s < %v", value, *s.Minimum), fail("must be at least %v", *s.Minimum))
+		}
+
+		if s.Maximum != nil {
+			add(fmt.Sprintf("%s > %v", value, *s.Maximum), fail("must be at most %v", *s.Maximum))
+		}
+	}
+
+	if strings.HasPrefix(typ, "[]") {
+		if s.MinItems != nil {
+			add(fmt.Sprintf("len(%s) < %d", value, *s.MinItems), fail("must have at least %d items", *s.MinItems))
+		}
+
+		if s.MaxItems != nil {
+			add(fmt.Sprintf("len(%s) > %d", value, *s.MaxItems), fail("must have at most %d items", *s.MaxItems))
+		}
+
+		if g.validatable(strings.TrimPrefix(typ, "[]")) {
+			elems := value
+			if ptr {
+				elems = "(" + value + ")"
+			}
+
+			cond = append(cond, fmt.Sprintf("for i := range %s {\n\t\tif err := %s[i].Validate(); err != nil {\n\t\t\treturn fmt.Errorf(\"%s[%%d]: %%w\", i, err)\n\t\t}\n\t}",
+				value, elems, f.json))
+		}
+	}
+
+	if g.validatable(typ) {
+		cond = append(cond, fmt.Sprintf("if err := %s.Validate(); err != nil {\n\t\treturn fmt.Errorf(\"%s: %%w\", err)\n\t}", field, f.json))
+	}
+
+	if len(cond) == 0 {
+		return checks
+	}
+
+	// optional values are only checked if set
+	guard := ""
+
+	switch {
+	case ptr:
+		guard = field + " != nil"
+	case f.optional:
+		guard = g.nonZero(field, typ)
+	}
+
+	if guard == "" {
+		return append(checks, cond...)
+	}
+
+	return append(checks, fmt.Sprintf("if %s {\n\t\t%s\n\t}", guard, strings.ReplaceAll(strings.Join(cond, "\n\n"), "\n", "\n\t")))
+}
+
+// nonZero returns the condition that the value of type typ is not the zero value, empty if
+// there is none.
+func (g *modelGenerator) nonZero(value, typ string) string {
+	if m, ok := g.models[typ]; ok && len(m.Enum) > 0 {
+		typ = m.Type
+	}
+
+	switch {
+	case typ == "string":
+		return value + ` != ""`
+	case isNumber(typ):
+		return value + " != 0"
+	case strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["):
+		return "len(" + value + ") > 0"
+	}
+
+	return ""
+}
+
+// isStruct reports whether typ is a generated struct type.
+func (g *modelGenerator) isStruct(typ string) bool {
+	m, ok := g.models[typ]
+
+	return ok && len(m.Fields) > 0
+}
+
+// validatable reports whether typ is a generated type with a Validate method.
+func (g *modelGenerator) validatable(typ string) bool {
+	m, ok := g.models[typ]
+
+	return ok && (len(m.Fields) > 0 || len(m.Enum) > 0)
+}
+
+// doc returns the doc comment of the type name with the schema s.
+func doc(name string, s *schema) string {
+	text := strings.TrimSpace(s.Description)
+	if text == "" {
+		text = s.Title
+	}
+
+	c := fmt.Sprintf("// %s is generated from JSON Schema.", name)
+	if text == "" {
+		return c
+	}
+
+	return c + "\n//\n" + comment(text)
+}
+
+// nullable reports whether the schema s allows null.
+func nullable(s *schema) bool {
+	return contains(s.Type, "null")
+}
+
+// isNumber reports whether typ is a numeric go type.
+func isNumber(typ string) bool {
+	switch typ {
+	case "int", "int32", "int64", "float32", "float64":
+		return true
+	}
+
+	return false
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// initialisms are the words written in upper case in go names.
+// nolint: gochecknoglobals
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName returns the exported go name of the JSON name s, e.g. FirstName for first_name.
+func goName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	b := strings.Builder{}
+
+	for _, w := range words {
+		if initialisms[strings.ToUpper(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+
+		b.WriteString(title(w))
+	}
+
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+
+	return name
+}
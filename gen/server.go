@@ -0,0 +1,228 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const serverTemplate = `
+// Code generated by httpclient-gen-go; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FakeServer is a fake of the API serving the routes of the services with fixtures. The
+// fixtures are set with SetFixture, SetResponse or LoadFixtures, routes without fixture
+// respond with 200 OK and an empty value. It can be used with httptest.NewServer or run
+// on its own, e.g.:
+//
+//	srv := NewFakeServer()
+//	if err := srv.LoadFixtures("testdata/fixtures"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	log.Fatal(http.ListenAndServe(":8080", srv))
+type FakeServer struct {
+	mux *http.ServeMux
+
+	mu       sync.RWMutex
+	fixtures map[string]Fixture
+}
+
+// Fixture is the response of a route of the FakeServer.
+type Fixture struct {
+	Status int             ` + "`json:\"status\"`" + `
+	Header http.Header     ` + "`json:\"header,omitempty\"`" + `
+	Body   json.RawMessage ` + "`json:\"body,omitempty\"`" + `
+}
+
+// fakeRoutes are the routes (service.method) of the FakeServer with their patterns and
+// default values.
+var fakeRoutes = []struct {
+	route   string
+	pattern string
+	value   interface{}
+}{
+{{- range .Routes }}
+	{ {{ printf "%q" .Route }}, {{ printf "%q" .Pattern }}, {{ .Value }} },
+{{- end }}
+}
+
+// NewFakeServer returns a fake server for the routes of the services.
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		mux:      http.NewServeMux(),
+		fixtures: map[string]Fixture{},
+	}
+
+	for _, r := range fakeRoutes {
+		f := Fixture{Status: http.StatusOK}
+		if r.value != nil {
+			f.Body, _ = json.Marshal(r.value)
+		}
+
+		s.fixtures[r.route] = f
+		s.mux.HandleFunc(r.pattern, s.handler(r.route))
+	}
+
+	return s
+}
+
+// Routes returns the routes (e.g. Node.Get) of the fake server.
+func (s *FakeServer) Routes() []string {
+	routes := make([]string, 0, len(fakeRoutes))
+	for _, r := range fakeRoutes {
+		routes = append(routes, r.route)
+	}
+
+	sort.Strings(routes)
+
+	return routes
+}
+
+// SetFixture sets the response of route (e.g. Node.Get).
+func (s *FakeServer) SetFixture(route string, f Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[route] = f
+}
+
+// SetResponse sets the response of route (e.g. Node.Get) to status and v encoded as JSON.
+func (s *FakeServer) SetResponse(route string, status int, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.SetFixture(route, Fixture{Status: status, Body: b})
+
+	return nil
+}
+
+// LoadFixtures loads the fixtures of the routes from the JSON files <route>.json (e.g.
+// Node.Get.json) in dir. Routes without file keep their fixture.
+func (s *FakeServer) LoadFixtures(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		f := Fixture{Status: http.StatusOK}
+		if err := json.Unmarshal(b, &f); err != nil {
+			return &os.PathError{Op: "parse", Path: file, Err: err}
+		}
+
+		s.SetFixture(strings.TrimSuffix(filepath.Base(file), ".json"), f)
+	}
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *FakeServer) handler(route string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		f := s.fixtures[route]
+		s.mu.RUnlock()
+
+		for k, v := range f.Header {
+			w.Header()[k] = v
+		}
+
+		if len(f.Body) > 0 && w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", httpclient.ContentTypeJSON)
+		}
+
+		w.WriteHeader(f.Status)
+
+		_, _ = w.Write(f.Body)
+	}
+}
+`
+
+// fakeRoute is a route of the generated fake server.
+type fakeRoute struct {
+	Route   string // e.g. Node.Get
+	Pattern string // http.ServeMux pattern, e.g. GET /nodes/{id}
+	Value   string // default value, e.g. new(Node)
+}
+
+// generateServer returns the formatted code of the fake server (-server) for the services
+// with generated Impl types.
+func generateServer(data *Data, file string) ([]byte, error) {
+	routes := []fakeRoute{}
+	patterns := map[string]string{}
+
+	for _, s := range data.Services {
+		if !s.Generate || s.TypeParams != "" {
+			continue
+		}
+
+		for _, m := range s.Methods {
+			r := fakeRoute{
+				Route:   s.FieldName + "." + m.Name,
+				Pattern: muxPattern(m.Route),
+				Value:   "nil",
+			}
+
+			if m.Result != "" {
+				r.Value = nonNilValue(m.Result)
+			}
+
+			key := placeholder.ReplaceAllString(r.Pattern, "{}")
+			if other, ok := patterns[key]; ok {
+				return nil, fmt.Errorf("%s and %s have the same route %s", other, r.Route, r.Pattern)
+			}
+
+			patterns[key] = r.Route
+			routes = append(routes, r)
+		}
+	}
+
+	t, err := template.New("Server Template").Parse(serverTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = t.Execute(buf, struct {
+		Package string
+		Routes  []fakeRoute
+	}{data.Package, routes})
+	if err != nil {
+		return nil, fmt.Errorf("could not render template: %w", err)
+	}
+
+	return format(file, buf.Bytes(), data.opts.GoImports)
+}
+
+// muxPattern returns the http.ServeMux pattern for route, e.g. GET /posts/{id}.
+func muxPattern(route string) string {
+	fields := strings.Fields(route)
+
+	return fields[0] + " /" + strings.TrimLeft(fields[1], "/")
+}
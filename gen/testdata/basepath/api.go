@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages the nodes of the inventory.
+//
+//httpclient:basepath /v2/inventory
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+	//httpclient:route POST /nodes
+	Create(ctx context.Context, n *Node) (*Node, *http.Response, error)
+}
+
+// StatusService has no base path.
+type StatusService interface {
+	//httpclient:route GET /status
+	Get(ctx context.Context) (string, *http.Response, error)
+}
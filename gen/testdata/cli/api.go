@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Node is a node.
+type Node struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+	//httpclient:route GET /nodes
+	List(ctx context.Context, active bool, limit int, since time.Duration, tags []string) ([]Node, *http.Response, error)
+	//httpclient:route POST /nodes
+	NewNode(ctx context.Context, n *Node) (*Node, *http.Response, error)
+	//httpclient:route DELETE /nodes/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// CRUDService is generic and skipped.
+//
+//httpclient:instantiate Node Nodes
+type CRUDService[T any] interface {
+	//httpclient:route GET /crud/{id}
+	Get(ctx context.Context, id string) (*T, *http.Response, error)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// APIError is the body of the error responses.
+type APIError struct {
+	// Code identifies the error.
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// Node is a node of the inventory.
+type Node struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Labels  []Label   `json:"labels,omitempty"`
+	secret  string
+}
+
+// Label is a label of a node.
+type Label struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ListOptions are the query options of List.
+type ListOptions struct {
+	Page int `url:"page,omitempty"`
+}
+
+// NodeService manages the nodes of the inventory.
+//
+//httpclient:basepath /v2
+//httpclient:error APIError
+type NodeService interface {
+	// Get returns the node with the id.
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+	// List returns a page of nodes.
+	//httpclient:route GET /nodes
+	//httpclient:query ListOptions
+	List(ctx context.Context, opts *ListOptions) ([]Node, *http.Response, error)
+	// Delete deletes the node with the id.
+	//httpclient:route DELETE /nodes/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// StatusService reports the status, its Impl type is written by hand.
+type StatusService interface {
+	// Ping checks the API.
+	Ping(ctx context.Context) (*http.Response, error)
+}
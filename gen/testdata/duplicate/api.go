@@ -0,0 +1,18 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route DELETE /nodes/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// NodeAPI is the old API of the nodes.
+type NodeAPI interface {
+	//httpclient:route DELETE /v1/nodes/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
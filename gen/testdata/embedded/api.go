@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// Reader reads nodes.
+type Reader interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+}
+
+// Writer writes nodes.
+type Writer interface {
+	Reader
+	//httpclient:route DELETE /nodes/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// NodeService embeds Reader twice, directly and with Writer.
+type NodeService interface {
+	Reader
+	Writer
+	//httpclient:route POST /nodes
+	Create(ctx context.Context, n *Node) (*Node, *http.Response, error)
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// APIError is the body of the error responses.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages nodes.
+//
+//httpclient:error APIError
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+}
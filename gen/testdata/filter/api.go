@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+}
+
+// NodeGroupService manages groups of nodes.
+type NodeGroupService interface {
+	//httpclient:route GET /groups/{id}/nodes
+	List(ctx context.Context, id string) ([]Node, *http.Response, error)
+}
+
+// PostService is not included.
+type PostService interface {
+	//httpclient:route DELETE /posts/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// MockNodeService is excluded by its name.
+type MockNodeService interface {
+	NodeService
+}
@@ -0,0 +1,6 @@
+package api
+
+// NodeTestService is excluded by the file name.
+type NodeTestService interface {
+	NodeService
+}
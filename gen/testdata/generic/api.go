@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Post is a post.
+type Post struct {
+	ID int `json:"id"`
+}
+
+// Comment is a comment.
+type Comment struct {
+	ID int `json:"id"`
+}
+
+// CRUDService manages resources of type T.
+//
+//httpclient:instantiate Post Posts
+//httpclient:instantiate Comment Comments
+type CRUDService[T any] interface {
+	//httpclient:route GET /{kind}/{id}
+	Get(ctx context.Context, kind string, id int) (*T, *http.Response, error)
+	//httpclient:route POST /{kind}
+	Create(ctx context.Context, kind string, v *T) (*T, *http.Response, error)
+}
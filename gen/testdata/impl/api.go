@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes
+	List(ctx context.Context) ([]Node, *http.Response, error)
+	//httpclient:route POST /nodes
+	Create(ctx context.Context, n *Node) (*Node, *http.Response, error)
+	//httpclient:route PUT /nodes/{id}
+	Update(ctx context.Context, id string, n Node) (Node, *http.Response, error)
+	//httpclient:route DELETE /nodes/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// NodeAdminService has no routes, its Impl type is written by hand.
+type NodeAdminService interface {
+	Reset(ctx context.Context) (*http.Response, error)
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+	//httpclient:route DELETE /nodes/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// CRUDService manages resources of type T.
+//
+//httpclient:instantiate Node Nodes
+type CRUDService[T any] interface {
+	//httpclient:route GET /crud/{id}
+	Get(ctx context.Context, id string) (*T, *http.Response, error)
+}
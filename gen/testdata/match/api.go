@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeEndpoint manages nodes.
+type NodeEndpoint interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+}
+
+// Admin is a service without a capture group.
+type Admin interface {
+	//httpclient:route POST /admin/reset
+	Reset(ctx context.Context) (*http.Response, error)
+}
+
+// NodeService does not match.
+type NodeService interface {
+	//httpclient:route GET /nodes
+	List(ctx context.Context) ([]Node, *http.Response, error)
+}
@@ -0,0 +1,18 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// ZooService is declared first.
+type ZooService interface {
+	//httpclient:route GET /zoos
+	List(ctx context.Context) ([]string, *http.Response, error)
+}
+
+// MuleService is declared in the same file.
+type MuleService interface {
+	//httpclient:route GET /mules
+	List(ctx context.Context) ([]string, *http.Response, error)
+}
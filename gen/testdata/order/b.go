@@ -0,0 +1,12 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// AntService is declared last.
+type AntService interface {
+	//httpclient:route GET /ants
+	List(ctx context.Context) ([]string, *http.Response, error)
+}
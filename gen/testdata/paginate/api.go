@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodePage is a page of nodes.
+type NodePage struct {
+	Nodes []Node `json:"nodes"`
+	Next  string `json:"next"`
+}
+
+// ListOptions are the query options of the lists.
+type ListOptions struct {
+	Owner string `url:"owner,omitempty"`
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes
+	//httpclient:query ListOptions
+	//httpclient:paginate link
+	List(ctx context.Context, opts *ListOptions) ([]Node, *http.Response, error)
+	//httpclient:route GET /groups/{group}/nodes
+	//httpclient:paginate token Next cursor Nodes
+	ListGroup(ctx context.Context, group string) (*NodePage, *http.Response, error)
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	//httpclient:timeout 5s
+	//httpclient:retry idempotent
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+	//httpclient:route DELETE /nodes/{id}
+	//httpclient:retry idempotent
+	Delete(ctx context.Context, id string) (*http.Response, error)
+	//httpclient:route POST /nodes
+	//httpclient:timeout 1m30s
+	Create(ctx context.Context, n *Node) (*Node, *http.Response, error)
+	//httpclient:route GET /nodes
+	List(ctx context.Context) ([]Node, *http.Response, error)
+}
@@ -0,0 +1,44 @@
+package posts
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/postfinance/httpclient"
+)
+
+// Post is a post.
+type Post struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// PostService manages posts.
+type PostService interface {
+	Get(ctx context.Context, id int) (*Post, *http.Response, error)
+}
+
+// PostImpl implements PostService.
+type PostImpl struct {
+	client *httpclient.Client
+}
+
+// NewPostImpl returns the PostService of the client c.
+func NewPostImpl(c *httpclient.Client) *PostImpl {
+	return &PostImpl{client: c}
+}
+
+// Get returns the post with the ID id.
+func (s *PostImpl) Get(ctx context.Context, id int) (*Post, *http.Response, error) {
+	req, err := s.client.NewRequest(http.MethodGet, "posts/"+strconv.Itoa(id), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := new(Post)
+
+	resp, err := s.client.Do(ctx, req, v)
+
+	return v, resp, err
+}
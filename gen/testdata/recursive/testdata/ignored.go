@@ -0,0 +1,4 @@
+package api
+
+// IgnoredService is in a directory ignored by the go tool.
+type IgnoredService interface{}
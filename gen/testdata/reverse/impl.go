@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Node is a node.
+type Node struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+// NodeImpl implements the node endpoints.
+type NodeImpl struct {
+	client *http.Client
+}
+
+// Get returns the node with the id.
+func (s *NodeImpl) Get(ctx context.Context, id string) (*Node, *http.Response, error) {
+	return nil, nil, nil
+}
+
+// Since returns the nodes created since t.
+func (s NodeImpl) Since(ctx context.Context, t time.Time) ([]Node, *http.Response, error) {
+	return nil, nil, nil
+}
+
+// get is not exported.
+func (s *NodeImpl) get() {}
+
+// CRUDImpl implements generic endpoints.
+type CRUDImpl[T any] struct{}
+
+// Delete deletes the resource with the id.
+func (s *CRUDImpl[T]) Delete(ctx context.Context, id int) (*http.Response, error) {
+	return nil, nil
+}
+
+// PostImpl implements PostService, which exists already.
+type PostImpl struct{}
+
+// PostService is the existing interface.
+type PostService interface{}
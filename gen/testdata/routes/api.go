@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Comment is a comment.
+type Comment struct {
+	ID   int64  `json:"id"`
+	Text string `json:"text"`
+}
+
+// ListOptions are the query options of List.
+type ListOptions struct {
+	Page    int `url:"page,omitempty"`
+	PerPage int `url:"per_page,omitempty"`
+}
+
+// CommentService manages the comments of posts.
+type CommentService interface {
+	//httpclient:route GET /posts/{post}/comments/{id}
+	Get(ctx context.Context, post string, id int64) (*Comment, *http.Response, error)
+	//httpclient:route GET /posts/{post}/comments
+	//httpclient:query ListOptions
+	List(ctx context.Context, post string, opts *ListOptions) ([]Comment, *http.Response, error)
+	//httpclient:route POST /posts/{post}/comments
+	//httpclient:query ListOptions
+	Create(ctx context.Context, post string, opts ListOptions, c *Comment) (*Comment, *http.Response, error)
+	//httpclient:route DELETE /posts/{post}/comments/{id}
+	Delete(context.Context, string, int64) (*http.Response, error)
+}
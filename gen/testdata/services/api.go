@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// APIError is the body of the error responses.
+type APIError struct {
+	Message string `json:"message"`
+}
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages nodes, it is configured by the options.
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+}
+
+// PostService manages posts, the directive takes precedence over the options.
+//
+//httpclient:basepath /v3
+type PostService interface {
+	//httpclient:route DELETE /posts/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// LegacyService is skipped.
+type LegacyService interface {
+	Ping(ctx context.Context) (*http.Response, error)
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages nodes.
+type NodeService interface {
+	//httpclient:route GET /nodes/{id}
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+}
+
+// SearchAPI searches nodes.
+type SearchAPI interface {
+	//httpclient:route GET /search/{term}
+	Find(ctx context.Context, term string) ([]Node, *http.Response, error)
+}
+
+// Service is not a service, the name is only the suffix.
+type Service interface {
+	Close() error
+}
@@ -0,0 +1,8 @@
+package api
+
+import "context"
+
+// NodeService manages nodes.
+type NodeService interface {
+	Delete(ctx context.Context, id string) error
+}
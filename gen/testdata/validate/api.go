@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Node is a node.
+type Node struct {
+	ID string `json:"id"`
+}
+
+// NodeService manages nodes, NodeImpl is incomplete.
+type NodeService interface {
+	Get(ctx context.Context, id string) (*Node, *http.Response, error)
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// NodeImpl implements only a part of NodeService.
+type NodeImpl struct{}
+
+// Get returns a node by id.
+func (s *NodeImpl) Get(ctx context.Context, id int) (*Node, *http.Response, error) {
+	return nil, nil, nil
+}
+
+// PostService has no PostImpl.
+type PostService interface {
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// TagService is generated with -impl.
+type TagService interface {
+	//httpclient:route DELETE /tags/{id}
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
@@ -0,0 +1,19 @@
+package comments
+
+import (
+	"context"
+	"net/http"
+)
+
+// CommentService manages comments.
+type CommentService interface {
+	Delete(ctx context.Context, id string) (*http.Response, error)
+}
+
+// CommentImpl implements CommentService, but has no constructor NewCommentImpl.
+type CommentImpl struct{}
+
+// Delete deletes the comment with the ID id.
+func (s *CommentImpl) Delete(ctx context.Context, id string) (*http.Response, error) {
+	return nil, nil
+}
@@ -0,0 +1,181 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// validate type checks the packages of the services and reports Impl types, which are
+// not generated and do not exist or do not implement their service interface, and missing
+// constructors of the Impl types of other packages.
+func validate(services []Service) error {
+	generated := map[string]bool{}
+
+	for _, s := range services {
+		if s.Generate {
+			generated[s.dir+" "+s.impl] = true
+		}
+	}
+
+	dirs := []string{}
+	byDir := map[string][]Service{}
+
+	for _, s := range services {
+		key := s.dir + " " + s.impl
+		if generated[key] {
+			continue
+		}
+
+		// instances of generic services share the Impl type
+		generated[key] = true
+
+		if _, ok := byDir[s.dir]; !ok {
+			dirs = append(dirs, s.dir)
+		}
+
+		byDir[s.dir] = append(byDir[s.dir], s)
+	}
+
+	problems := []string{}
+
+	for _, dir := range dirs {
+		pkgs, err := typeCheck(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range byDir[dir] {
+			problems = append(problems, checkImpl(pkgs, s)...)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid Impl types (use -validate=false to skip this check):\n\t%s", strings.Join(problems, "\n\t"))
+	}
+
+	return nil
+}
+
+// typeCheck returns the type checked packages (including test files) in dir by name. The
+// imports are read from the export data built by go list.
+func typeCheck(dir string) (map[string]*types.Package, error) {
+	// nolint: gosec // G204: Subprocess launched with variable
+	cmd := exec.Command("go", "list", "-e", "-test", "-deps", "-export", "-f", "{{if .Export}}{{.ImportPath}}={{.Export}}{{end}}", ".")
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not list dependencies of %s: %w", dir, err)
+	}
+
+	exports := map[string]string{}
+
+	for _, l := range strings.Split(string(out), "\n") {
+		if kv := strings.SplitN(l, "=", 2); len(kv) == 2 {
+			exports[kv[0]] = kv[1]
+		}
+	}
+
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.AllErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]*types.Package{}
+
+	for name, p := range pkgs {
+		files := []*ast.File{}
+		for _, f := range p.Files {
+			files = append(files, f)
+		}
+
+		conf := types.Config{
+			Importer: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+				if f, ok := exports[path]; ok {
+					return os.Open(f) // nolint: gosec // G304: file inclusion is intended
+				}
+
+				return nil, fmt.Errorf("no export data for %s", path)
+			}),
+			// type errors are ignored: the generated code of a previous run may not compile
+			Error: func(error) {},
+		}
+
+		result[name], _ = conf.Check(name, fset, files, nil)
+	}
+
+	return result, nil
+}
+
+// checkImpl returns the problems of the Impl type of service s.
+func checkImpl(pkgs map[string]*types.Package, s Service) []string {
+	pkg := pkgs[s.pkg]
+	if pkg == nil {
+		return []string{s.impl + ": package could not be type checked"}
+	}
+
+	impl, ok := pkg.Scope().Lookup(s.impl).(*types.TypeName)
+	if !ok {
+		return []string{s.impl + " does not exist (implement it or generate it with -impl)"}
+	}
+
+	problems := []string{}
+
+	if s.Constructor != "" {
+		if _, ok := pkg.Scope().Lookup("New" + s.impl).(*types.Func); !ok {
+			problems = append(problems, "New"+s.impl+" does not exist (the Impl types of other packages are created with it)")
+		}
+	}
+
+	iface, ok := pkg.Scope().Lookup(s.iface).(*types.TypeName)
+	if !ok {
+		return []string{s.iface + ": interface could not be type checked"}
+	}
+
+	it, ok := iface.Type().Underlying().(*types.Interface)
+	if !ok {
+		return []string{s.iface + " is not an interface"}
+	}
+
+	generic := s.TypeParams != ""
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+
+		return p.Name()
+	}
+	signature := func(f *types.Func) string {
+		return f.Name() + strings.TrimPrefix(types.TypeString(f.Type(), qualifier), "func")
+	}
+
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(impl.Type()), false, m.Pkg(), m.Name())
+
+		f, ok := obj.(*types.Func)
+		if !ok {
+			problems = append(problems, s.impl+" is missing method "+signature(m))
+			continue
+		}
+
+		// the signatures of generic types refer to different type parameters
+		if !generic && !types.Identical(f.Type(), m.Type()) {
+			problems = append(problems, s.impl+" has wrong method "+signature(f)+", want "+signature(m))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,22 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	_, err := Scan(Options{
+		Package:  "api",
+		Paths:    []string{"testdata/validate/..."},
+		Out:      "testdata/validate/httpclient.go",
+		Impl:     true,
+		Validate: true,
+	})
+	assert.EqualError(t, err, `invalid Impl types (use -validate=false to skip this check):
+	NodeImpl is missing method Delete(ctx context.Context, id string) (*http.Response, error)
+	NodeImpl has wrong method Get(ctx context.Context, id int) (*Node, *http.Response, error), want Get(ctx context.Context, id string) (*Node, *http.Response, error)
+	PostImpl does not exist (implement it or generate it with -impl)
+	NewCommentImpl does not exist (the Impl types of other packages are created with it)`)
+}
@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLRequest is a GraphQL operation (see GraphQL).
+type GraphQLRequest struct {
+	Query         string                 `json:"query,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+
+	// Persisted sends the query as automatic persisted query: only its SHA-256 hash is sent
+	// and the query only if the server does not know it yet.
+	Persisted bool `json:"-"`
+}
+
+// GraphQLLocation is a location in a GraphQL query.
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError is an error of the errors array of a GraphQL response.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Error returns the message of the error.
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// GraphQLErrors are the errors of a GraphQL response.
+type GraphQLErrors []GraphQLError
+
+// Error returns the messages of the errors.
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// graphQLResponse is the response of a GraphQL request.
+type graphQLResponse[T any] struct {
+	Data   T             `json:"data"`
+	Errors GraphQLErrors `json:"errors"`
+}
+
+// GraphQL sends the GraphQL operation req as POST request to urlStr (see NewRequest) with the
+// client c and returns the data of the response decoded into a value of type T. Operations and
+// responses are always encoded as JSON, independent of the ContentType of the client. If the
+// response has errors, they are returned as GraphQLErrors with the (partial) data.
+func GraphQL[T any](ctx context.Context, c *Client, urlStr string, req GraphQLRequest, opts ...RequestOpt) (T, error) {
+	if !req.Persisted {
+		return graphQL[T](ctx, c, urlStr, req, opts)
+	}
+
+	hash := sha256.Sum256([]byte(req.Query))
+	persisted := req
+
+	persisted.Query = ""
+	persisted.Extensions = map[string]interface{}{}
+
+	for k, v := range req.Extensions {
+		persisted.Extensions[k] = v
+	}
+
+	persisted.Extensions["persistedQuery"] = map[string]interface{}{
+		"version":    1,
+		"sha256Hash": hex.EncodeToString(hash[:]),
+	}
+
+	v, err := graphQL[T](ctx, c, urlStr, persisted, opts)
+	if !persistedQueryNotFound(err) {
+		return v, err
+	}
+
+	persisted.Query = req.Query
+
+	return graphQL[T](ctx, c, urlStr, persisted, opts)
+}
+
+// graphQL sends the GraphQL operation req.
+func graphQL[T any](ctx context.Context, c *Client, urlStr string, req GraphQLRequest, opts []RequestOpt) (T, error) {
+	var zero T
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return zero, err
+	}
+
+	opts = append([]RequestOpt{RawBody(body, ContentTypeJSON), SetHeader("Accept", ContentTypeJSON)}, opts...)
+
+	r, err := c.NewRequestWithContext(ctx, http.MethodPost, urlStr, nil, opts...)
+	if err != nil {
+		return zero, err
+	}
+
+	buf := &bodyBuffer{budget: c.budget}
+	defer buf.release()
+
+	if _, err := c.Do(ctx, r, buf); err != nil {
+		return zero, err
+	}
+
+	var resp graphQLResponse[T]
+
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		return zero, fmt.Errorf("decode graphql response: %w", err)
+	}
+
+	if len(resp.Errors) > 0 {
+		return resp.Data, resp.Errors
+	}
+
+	return resp.Data, nil
+}
+
+// persistedQueryNotFound reports whether err is the error of an unknown persisted query.
+func persistedQueryNotFound(err error) bool {
+	errs, ok := err.(GraphQLErrors) // nolint: errorlint // returned unwrapped
+	if !ok {
+		return false
+	}
+
+	for _, e := range errs {
+		if e.Message == "PersistedQueryNotFound" || e.Extensions["code"] == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+
+	return false
+}
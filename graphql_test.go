@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQL(t *testing.T) {
+	const query = "query($id: ID!) { message(id: $id) { text } }"
+
+	type data struct {
+		Message *message `json:"message"`
+	}
+
+	known := map[string]bool{}
+	requests := []map[string]interface{}{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+
+		if ext, ok := req["extensions"].(map[string]interface{}); ok {
+			hash := ext["persistedQuery"].(map[string]interface{})["sha256Hash"].(string)
+
+			if req["query"] == nil && !known[hash] {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`))
+				return
+			}
+
+			known[hash] = true
+		}
+
+		if req["variables"].(map[string]interface{})["id"] == "2" {
+			_, _ = w.Write([]byte(`{"data":{"message":null},"errors":[{"message":"not found","path":["message"]},{"message":"forbidden"}]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"message":{"text":"hello"}}}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithContentType(ContentTypeYAML))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("data", func(t *testing.T) {
+		requests = requests[:0]
+
+		d, err := GraphQL[data](ctx, c, "/graphql", GraphQLRequest{Query: query, Variables: map[string]interface{}{"id": "1"}})
+		assert.Nil(t, err)
+		assert.Equal(t, &message{Text: "hello"}, d.Message)
+		assert.Equal(t, query, requests[0]["query"])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		d, err := GraphQL[data](ctx, c, "/graphql", GraphQLRequest{Query: query, Variables: map[string]interface{}{"id": "2"}})
+		assert.EqualError(t, err, "graphql: not found; forbidden")
+		assert.Nil(t, d.Message)
+
+		var errs GraphQLErrors
+
+		assert.True(t, errors.As(err, &errs))
+		assert.Equal(t, []interface{}{"message"}, errs[0].Path)
+	})
+
+	t.Run("persisted", func(t *testing.T) {
+		requests = requests[:0]
+
+		for i := 0; i < 2; i++ {
+			d, err := GraphQL[data](ctx, c, "/graphql", GraphQLRequest{Query: query, Variables: map[string]interface{}{"id": "1"}, Persisted: true})
+			assert.Nil(t, err)
+			assert.Equal(t, "hello", d.Message.Text)
+		}
+
+		assert.Len(t, requests, 3)
+		assert.Nil(t, requests[0]["query"])
+		assert.Equal(t, query, requests[1]["query"])
+		assert.Nil(t, requests[2]["query"])
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		_, err := GraphQL[data](ctx, c, "/error", GraphQLRequest{Query: query})
+		assert.Equal(t, http.StatusInternalServerError, statusCode(err))
+	})
+}
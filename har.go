@@ -0,0 +1,359 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HAR is an HTTP Archive (HAR 1.2), e.g. for the analysis of recorded traffic in the developer
+// tools of browsers (see HARRecorder).
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog is the log of an HTTP Archive.
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+// HARCreator is the application creating an HTTP Archive.
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a request and its response of an HTTP Archive, the times are in milliseconds.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+// HARRequest is a request of an HTTP Archive.
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARResponse is a response of an HTTP Archive, requests failed without response have the
+// status 0 and the error as status text.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+// HARNameValue is a header, cookie or query parameter of an HTTP Archive.
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a request of an HTTP Archive.
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARContent is the body of a response of an HTTP Archive.
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+// HARTimings are the timings of a request of an HTTP Archive: Wait is the time until the
+// response headers were received and Receive the time until the response body was closed.
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HARRecorder records the requests sent by clients with the option WithHARRecorder as HTTP
+// Archive. The URLs and headers are redacted (see RedactURL and RedactHeader), the bodies are
+// only recorded up to the body limit of NewHARRecorder and as they are, unless RedactBody is
+// set.
+type HARRecorder struct {
+	// RedactBody returns the redacted body of a request or response with the media type
+	// mimeType, e.g. without the passwords of login requests. The body may be truncated to the
+	// body limit. RedactBody must be set before the recorder is used.
+	RedactBody func(mimeType string, body []byte) []byte
+
+	bodyLimit int64
+	mu        sync.Mutex
+	entries   []HAREntry
+}
+
+// NewHARRecorder returns a HARRecorder recording the request and response bodies up to
+// bodyLimit bytes each, 0 records no bodies.
+func NewHARRecorder(bodyLimit int64) *HARRecorder {
+	return &HARRecorder{bodyLimit: bodyLimit}
+}
+
+// WithHARRecorder is a client option for recording the requests and responses with the
+// HARRecorder r, e.g. to capture a session during an incident. An entry is recorded when the
+// response body is closed.
+func WithHARRecorder(r *HARRecorder) Opt {
+	return func(c *Client) error {
+		if r == nil {
+			return errors.New("HAR recorder cannot be nil")
+		}
+
+		c.har = r
+
+		return nil
+	}
+}
+
+// HAR returns the HTTP Archive of the recorded entries, ordered by start time.
+func (r *HARRecorder) HAR() *HAR {
+	r.mu.Lock()
+	entries := append([]HAREntry{}, r.entries...)
+	r.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, m := range info.Deps {
+			if m.Path == "github.com/postfinance/httpclient" {
+				version = m.Version
+			}
+		}
+	}
+
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "github.com/postfinance/httpclient", Version: version},
+		Entries: entries,
+	}}
+}
+
+// WriteTo writes the HTTP Archive of the recorded entries as JSON to w.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(data)
+
+	return int64(n), err
+}
+
+// WriteFile writes the HTTP Archive of the recorded entries to the file name (e.g. session.har).
+func (r *HARRecorder) WriteFile(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Reset removes the recorded entries.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// add adds the entry e.
+func (r *HARRecorder) add(e HAREntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, e)
+}
+
+// redact returns the body with the media type mimeType redacted by RedactBody.
+func (r *HARRecorder) redact(mimeType string, body []byte) string {
+	if r.RedactBody != nil && len(body) > 0 {
+		body = r.RedactBody(mimeType, body)
+	}
+
+	return string(body)
+}
+
+// start returns the entry of the request req started at the time start.
+func (r *HARRecorder) start(req *http.Request, start time.Time) HAREntry {
+	e := HAREntry{StartedDateTime: start}
+	e.Request = HARRequest{
+		Method:      req.Method,
+		URL:         RedactURL(req.URL.String()),
+		HTTPVersion: req.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(RedactHeader(req.Header)),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+
+	if u, err := req.URL.Parse(e.Request.URL); err == nil {
+		e.Request.QueryString = harValues(u.Query())
+	}
+
+	if r.bodyLimit > 0 && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := ioutil.ReadAll(io.LimitReader(body, r.bodyLimit))
+			_ = body.Close()
+
+			mimeType := req.Header.Get("Content-Type")
+			e.Request.PostData = &HARPostData{MimeType: mimeType, Text: r.redact(mimeType, data)}
+		}
+	}
+
+	return e
+}
+
+// record records the entry e of the response resp or the (redacted) error err, the entry of a
+// response is recorded when its body is closed.
+func (r *HARRecorder) record(e HAREntry, resp *http.Response, err error, now func() time.Time) {
+	wait := millis(now().Sub(e.StartedDateTime))
+	e.Timings.Wait = wait
+	e.Time = wait
+
+	if err != nil || resp == nil {
+		e.Response = HARResponse{StatusText: errString(err), Cookies: []HARNameValue{}, Headers: []HARNameValue{}, HeadersSize: -1, BodySize: -1}
+		r.add(e)
+
+		return
+	}
+
+	e.Response = HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(RedactHeader(resp.Header)),
+		Content:     HARContent{MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: RedactURL(resp.Header.Get("Location")),
+		HeadersSize: -1,
+	}
+
+	if resp.Body == nil {
+		r.add(e)
+		return
+	}
+
+	resp.Body = &harBody{ReadCloser: resp.Body, recorder: r, entry: e, now: now}
+}
+
+// harBody is a response body recording its entry when it is closed.
+type harBody struct {
+	io.ReadCloser
+	recorder *HARRecorder
+	entry    HAREntry
+	now      func() time.Time
+	body     bytes.Buffer
+	size     int64
+	once     sync.Once
+}
+
+// Read reads from the body and records the bytes read up to the body limit.
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+
+	if rest := b.recorder.bodyLimit - int64(b.body.Len()); rest > 0 {
+		if int64(n) < rest {
+			rest = int64(n)
+		}
+
+		b.body.Write(p[:rest])
+	}
+
+	return n, err
+}
+
+// Close closes the body and records the entry.
+func (b *harBody) Close() error {
+	err := b.ReadCloser.Close()
+
+	b.once.Do(func() {
+		e := b.entry
+		e.Time = millis(b.now().Sub(e.StartedDateTime))
+		e.Timings.Receive = e.Time - e.Timings.Wait
+		e.Response.BodySize = b.size
+		e.Response.Content.Size = b.size
+		e.Response.Content.Text = b.recorder.redact(e.Response.Content.MimeType, b.body.Bytes())
+		b.recorder.add(e)
+	})
+
+	return err
+}
+
+// harHeaders returns the headers h sorted by name.
+func harHeaders(h http.Header) []HARNameValue {
+	return harValues(map[string][]string(h))
+}
+
+// harValues returns the values m sorted by name.
+func harValues(m map[string][]string) []HARNameValue {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	nv := []HARNameValue{}
+
+	for _, name := range names {
+		for _, v := range m[name] {
+			nv = append(nv, HARNameValue{Name: name, Value: v})
+		}
+	}
+
+	return nv
+}
+
+// millis returns d in milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// errString returns the message of err, empty if it is nil.
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHARRecorder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"Text":"a long response"}`))
+	}))
+	defer ts.Close()
+
+	r := NewHARRecorder(10)
+
+	c, err := New(ts.URL, WithHARRecorder(r), WithBasicAuth(username, password))
+	assert.Nil(t, err)
+
+	do := func(method, path string, body interface{}) error {
+		req, err := c.NewRequest(method, path, body)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+
+		return err
+	}
+
+	assert.Nil(t, do(http.MethodPost, "/posts?page=1&access_token=secret", message{Text: "hello"}))
+	assert.NotNil(t, do(http.MethodGet, "/missing", nil))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	assert.Nil(t, l.Close())
+	assert.NotNil(t, do(http.MethodGet, "http://"+l.Addr().String(), nil))
+
+	h := r.HAR()
+	assert.Equal(t, "1.2", h.Log.Version)
+	assert.Len(t, h.Log.Entries, 3)
+
+	t.Run("request", func(t *testing.T) {
+		e := h.Log.Entries[0]
+		assert.Equal(t, http.MethodPost, e.Request.Method)
+		assert.Equal(t, ts.URL+"/posts?page=1&access_token=xxxxx", e.Request.URL)
+		assert.Contains(t, e.Request.Headers, HARNameValue{Name: "Authorization", Value: "xxxxx"})
+		assert.Equal(t, []HARNameValue{{Name: "access_token", Value: "xxxxx"}, {Name: "page", Value: "1"}}, e.Request.QueryString)
+		assert.Equal(t, &HARPostData{MimeType: ContentTypeJSON, Text: `{"Text":"h`}, e.Request.PostData)
+	})
+
+	t.Run("response", func(t *testing.T) {
+		e := h.Log.Entries[0]
+		assert.Equal(t, http.StatusOK, e.Response.Status)
+		assert.Contains(t, e.Response.Headers, HARNameValue{Name: "Set-Cookie", Value: "xxxxx"})
+		assert.Equal(t, HARContent{Size: 26, MimeType: ContentTypeJSON, Text: `{"Text":"a`}, e.Response.Content)
+		assert.True(t, e.Time >= e.Timings.Wait)
+
+		assert.Equal(t, http.StatusNotFound, h.Log.Entries[1].Response.Status)
+
+		e = h.Log.Entries[2]
+		assert.Equal(t, 0, e.Response.Status)
+		assert.Contains(t, e.Response.StatusText, "connection refused")
+	})
+
+	t.Run("write", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		_, err := r.WriteTo(buf)
+		assert.Nil(t, err)
+		assert.NotContains(t, buf.String(), "secret")
+		assert.NotContains(t, buf.String(), password)
+
+		name := filepath.Join(t.TempDir(), "session.har")
+		assert.Nil(t, r.WriteFile(name))
+
+		data, err := ioutil.ReadFile(name)
+		assert.Nil(t, err)
+
+		var decoded HAR
+		assert.Nil(t, json.Unmarshal(data, &decoded))
+		assert.Len(t, decoded.Log.Entries, 3)
+		assert.True(t, strings.HasPrefix(decoded.Log.Entries[0].Request.URL, ts.URL))
+
+		r.Reset()
+		assert.NotNil(t, r.HAR().Log.Entries)
+		assert.Len(t, r.HAR().Log.Entries, 0)
+	})
+
+	t.Run("redacted body", func(t *testing.T) {
+		r := NewHARRecorder(100)
+		r.RedactBody = func(mimeType string, body []byte) []byte {
+			return bytes.ReplaceAll(body, []byte("hello"), []byte("xxxxx"))
+		}
+
+		c, err := New(ts.URL, WithHARRecorder(r))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodPost, "/login", message{Text: "hello"})
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+		assert.Nil(t, err)
+
+		e := r.HAR().Log.Entries[0]
+		assert.Equal(t, `{"Text":"xxxxx"}`+"\n", e.Request.PostData.Text)
+		assert.Equal(t, `{"Text":"a long response"}`, e.Response.Content.Text)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := New(ts.URL, WithHARRecorder(nil))
+		assert.NotNil(t, err)
+	})
+}
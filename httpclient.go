@@ -5,21 +5,26 @@ package httpclient
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/oauth2"
 	"golang.org/x/time/rate"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/google/go-querystring/query"
-	"github.com/pkg/errors"
 )
 
 // Constants
@@ -33,8 +38,22 @@ const (
 var (
 	ErrUnknownContentType = errors.New("unknown media type")
 	ErrTooManyRequest     = errors.New("too many requests")
+	ErrTargetType         = errors.New("target type is not string")
 )
 
+// HTTPError is returned by the default ResponseCallback for responses with a status code
+// outside the 200 range, e.g. errors.As(err, &httpErr) gives access to the status code.
+type HTTPError struct {
+	StatusCode int
+	Status     string // e.g. 404 Not Found
+	Header     http.Header
+}
+
+// Error returns the status of the response.
+func (e *HTTPError) Error() string {
+	return e.Status
+}
+
 // Client provides ....
 type Client struct {
 	// HTTP client used to communicate with the server
@@ -43,9 +62,18 @@ type Client struct {
 	// rate limiter
 	limiter *rate.Limiter
 
+	// rate limiters of the lanes (see WithLaneLimiter)
+	lanes map[string]*rate.Limiter
+
 	// Base URL for API requests.
+	//
+	// Deprecated: Use GetBaseURL and SetBaseURL, which are safe for concurrent use with
+	// requests in flight.
 	BaseURL *url.URL
 
+	// guards BaseURL, nil if the client was not created with New
+	baseURLMu *sync.RWMutex
+
 	// ContentType is used as Content-Type and Accept in request headers.
 	ContentType string
 
@@ -53,19 +81,149 @@ type Client struct {
 	username string
 	password string
 
+	// basic authentication with empty password (see WithBasicAuthAllowEmptyPassword)
+	allowEmptyPassword bool
+
+	// static bearer token (see WithBearerToken)
+	bearerToken string
+
+	// source of the OAuth2 bearer tokens (see WithTokenSource)
+	tokenSource oauth2.TokenSource
+
 	// custom http header(s)
 	header http.Header
 
+	// header(s) and basic authentication of every request (see initHeader), copied into the
+	// header of every request
+	baseHeader http.Header
+
 	Marshaler   MarshalerFunc
 	Unmarshaler UnmarshalerFunc
 
+	// MarshalerContext and UnmarshalerContext take precedence over Marshaler and Unmarshaler
+	// if they are set (see WithMarshalerContext and WithUnmarshalerContext).
+	MarshalerContext   MarshalerContextFunc
+	UnmarshalerContext UnmarshalerContextFunc
+
 	RequestCallback  RequestCallbackFunc
 	ResponseCallback ResponseCallbackFunc
+
+	// request middleware called after the RequestCallback (see UseRequestMiddleware)
+	requestMiddleware []RequestCallbackFunc
+
+	// response middleware called before the ResponseCallback (see UseResponseMiddleware)
+	responseMiddleware []ResponseCallbackFunc
+
+	// Instrumentation of the services of a generated client (see WithInstrumentation)
+	Instrumentation Instrumentation
+
+	// RetryPolicy used by Retry, DefaultRetryPolicy if nil (see WithRetryPolicy)
+	RetryPolicy *RetryPolicy
+
+	// retries of Do with the RetryPolicy (see WithRetry)
+	autoRetry bool
+
+	// circuit breaker of Do (see WithCircuitBreaker), shared by the copies of the client
+	breaker *circuitBreaker
+
+	// path prefix of the request URLs (see WithBasePath), absolute with WithPathPrefix
+	basePath         string
+	absoluteBasePath bool
+
+	// options of the services of a generated client (see WithServiceOptions)
+	serviceOpts map[string][]Opt
+
+	// memory budget of buffered responses (see WithMemoryBudget)
+	budget *MemoryBudget
+
+	// coalescing of identical GET requests (see WithSingleflight)
+	group *flightGroup
+
+	// responses of GET requests (see WithCache), shared by the copies of the client
+	cache *Cache
+
+	// results of mutating requests by idempotency key (see WithIdempotencyCache)
+	idempotency *idempotencyCache
+
+	// store of the requests queued while offline (see WithOfflineQueue)
+	offline OfflineStore
+
+	// ETags of the resources read (see WithOptimisticConcurrency)
+	etags *etagStore
+
+	// pinned public keys of the server certificates (see WithPinnedCertificates)
+	pins *pinning
+
+	// security policy of the connections (see WithSecurityPolicy)
+	policy *SecurityPolicy
+
+	// store of the scheduled requests (see WithScheduleStore)
+	schedule ScheduleStore
+
+	// priority queue of the requests (see WithPriorityQueue)
+	queue *priorityQueue
+
+	// transferred requests and bytes (see TransferStats), shared by the copies of the client
+	transfer *transferCounter
+
+	// counters of the requests (see Stats), shared by the copies of the client
+	stats *statsCounter
+
+	// rewrite rules of the request URLs (see WithRewriteRules)
+	rewrites []rewriteRule
+
+	// writers of the response bodies (see WithResponseTee)
+	tees []io.Writer
+
+	// recorder of the requests and responses (see WithHARRecorder)
+	har *HARRecorder
+
+	// handler of deprecated resources (see WithDeprecationHandler)
+	deprecation func(*http.Request, Deprecation)
+
+	// maximum number of bytes drained from response bodies (see WithDrainLimit)
+	drainLimit int64
+
+	// accepted media types of responses (see WithAccept)
+	accept []string
+
+	// time source (see WithClock), the system clock if nil
+	clock Clock
+
+	// deadline of requests without deadline (see WithDefaultDeadline)
+	defaultDeadline time.Duration
+
+	// streaming of request bodies (see WithStreaming)
+	streaming       bool
+	streamThreshold int
 }
 
 // Opt are options for New.
 type Opt func(*Client) error
 
+// MarshalerContextFunc is a MarshalerFunc receiving the context of the request, e.g. for
+// deadlines, tracing or per-request options.
+type MarshalerContextFunc func(ctx context.Context, w io.Writer, v interface{}, mediaType string) (string, error)
+
+// UnmarshalerContextFunc is an UnmarshalerFunc receiving the context of the request and the
+// full response (e.g. to decode depending on its headers). It reads the body of resp.
+type UnmarshalerContextFunc func(ctx context.Context, resp *http.Response, v interface{}, mediaType string) error
+
+// MarshalerWithContext returns the MarshalerContextFunc calling f, which ignores the context.
+func MarshalerWithContext(f MarshalerFunc) MarshalerContextFunc {
+	return func(_ context.Context, w io.Writer, v interface{}, mediaType string) (string, error) {
+		return f(w, v, mediaType)
+	}
+}
+
+// UnmarshalerWithContext returns the UnmarshalerContextFunc calling f with the body of the
+// response, which ignores the context.
+func UnmarshalerWithContext(f UnmarshalerFunc) UnmarshalerContextFunc {
+	return func(_ context.Context, resp *http.Response, v interface{}, mediaType string) error {
+		return f(resp.Body, v, mediaType)
+	}
+}
+
 // RequestCallbackFunc for custom pre-processing of requests
 // possible use cases: custom error checking, dumping requests for debugging etc.
 type RequestCallbackFunc func(*http.Request) *http.Request
@@ -83,11 +241,13 @@ type UnmarshalerFunc func(io.Reader, interface{}, string) error
 // QueryOptions adds query options opt to URL u
 // opt has to be a struct tagged according to https://github.com/google/go-querystring
 // e.g.:
-// type options struct {
-//     Page    int    `url:"page,omitempty"`
-//     PerPage int    `url:"per_page,omitempty"`
-//     Search  string `url:"search,omitempty"`
-// }
+//
+//	type options struct {
+//	    Page    int    `url:"page,omitempty"`
+//	    PerPage int    `url:"per_page,omitempty"`
+//	    Search  string `url:"search,omitempty"`
+//	}
+//
 // opt := options{1, 10, "name=testHost"}
 // ... will be added to URL u as "?page=1&per_page=10&search=name%3DtestHost"
 func QueryOptions(u string, opt interface{}) (string, error) {
@@ -99,7 +259,7 @@ func QueryOptions(u string, opt interface{}) (string, error) {
 
 	origURL, err := url.Parse(u)
 	if err != nil {
-		return u, err
+		return u, redactError(err)
 	}
 
 	origValues := origURL.Query()
@@ -122,7 +282,7 @@ func QueryOptions(u string, opt interface{}) (string, error) {
 func New(baseURL string, opts ...Opt) (*Client, error) {
 	u, err := url.Parse(baseURL)
 	if err != nil {
-		return nil, err
+		return nil, redactError(err)
 	}
 
 	c := &Client{
@@ -130,11 +290,15 @@ func New(baseURL string, opts ...Opt) (*Client, error) {
 			Timeout: 30 * time.Second,
 		},
 		BaseURL:          u,
+		baseURLMu:        new(sync.RWMutex),
 		ContentType:      ContentTypeJSON,
 		Marshaler:        marshal,
 		Unmarshaler:      unmarshal,
 		RequestCallback:  requestCallback,
 		ResponseCallback: responseCallback,
+		drainLimit:       DefaultDrainLimit,
+		transfer:         new(transferCounter),
+		stats:            new(statsCounter),
 	}
 
 	for _, opt := range opts {
@@ -143,6 +307,8 @@ func New(baseURL string, opts ...Opt) (*Client, error) {
 		}
 	}
 
+	c.initHeader()
+
 	return c, nil
 }
 
@@ -172,6 +338,53 @@ func WithUsername(u string) Opt {
 	}
 }
 
+// WithBasicAuth is a client option for setting the username and password for basic
+// authentication, which cannot be empty (see WithBasicAuthAllowEmptyPassword).
+func WithBasicAuth(username, password string) Opt {
+	return func(c *Client) error {
+		if username == "" || password == "" {
+			return errors.New("username and password cannot be empty")
+		}
+
+		c.username = username
+		c.password = password
+		c.allowEmptyPassword = false
+
+		return nil
+	}
+}
+
+// WithBasicAuthAllowEmptyPassword is like WithBasicAuth, but password can be empty, e.g. for
+// APIs expecting a token as username.
+func WithBasicAuthAllowEmptyPassword(username, password string) Opt {
+	return func(c *Client) error {
+		if username == "" {
+			return errors.New("username cannot be empty")
+		}
+
+		c.username = username
+		c.password = password
+		c.allowEmptyPassword = true
+
+		return nil
+	}
+}
+
+// WithBearerToken is a client option for authenticating the requests with the static token
+// (e.g. an API token) in the header Authorization: Bearer <token>. The token takes precedence
+// over the basic authentication.
+func WithBearerToken(token string) Opt {
+	return func(c *Client) error {
+		if token == "" {
+			return errors.New("bearer token cannot be empty")
+		}
+
+		c.bearerToken = token
+
+		return nil
+	}
+}
+
 // WithHTTPClient is a client option for setting another http client than the default one
 func WithHTTPClient(c *http.Client) Opt {
 	return func(cli *Client) error {
@@ -204,6 +417,7 @@ func WithContentType(ct string) Opt {
 // WithHeader is a client option for setting custom http header(s) for each request
 // Content-Type and Accept headers will be appended by the clients ContentType setting
 // Authorization header is overwritten if WithUsername/WithPassowrd was used to setup the client
+// The header is copied when the client is created, later changes of header have no effect.
 func WithHeader(header http.Header) Opt {
 	return func(c *Client) error {
 		c.header = header
@@ -211,49 +425,212 @@ func WithHeader(header http.Header) Opt {
 	}
 }
 
+// WithMarshalerContext is a client option for setting the MarshalerContext.
+func WithMarshalerContext(f MarshalerContextFunc) Opt {
+	return func(c *Client) error {
+		if f == nil {
+			return errors.New("marshaler cannot be nil")
+		}
+
+		c.MarshalerContext = f
+
+		return nil
+	}
+}
+
+// WithUnmarshalerContext is a client option for setting the UnmarshalerContext.
+func WithUnmarshalerContext(f UnmarshalerContextFunc) Opt {
+	return func(c *Client) error {
+		if f == nil {
+			return errors.New("unmarshaler cannot be nil")
+		}
+
+		c.UnmarshalerContext = f
+
+		return nil
+	}
+}
+
+// Clone returns a copy of the client. The copy shares the HTTP client and rate limiter with c, but
+// its settings (e.g. BaseURL, ContentType or the callbacks) can be changed independently.
+func (c *Client) Clone() *Client {
+	clone := *c
+
+	clone.BaseURL = c.GetBaseURL()
+	clone.baseURLMu = new(sync.RWMutex)
+
+	if c.header != nil {
+		clone.header = c.header.Clone()
+	}
+
+	clone.initHeader()
+
+	if c.serviceOpts != nil {
+		clone.serviceOpts = make(map[string][]Opt, len(c.serviceOpts))
+		for k, v := range c.serviceOpts {
+			clone.serviceOpts[k] = append([]Opt(nil), v...)
+		}
+	}
+
+	return &clone
+}
+
+// GetBaseURL returns a copy of the BaseURL of the client.
+func (c *Client) GetBaseURL() *url.URL {
+	u := c.baseURL()
+	if u == nil {
+		return nil
+	}
+
+	cp := *u
+
+	return &cp
+}
+
+// SetBaseURL sets the BaseURL of the client, e.g. to switch between blue/green deployments at
+// runtime. Requests created before are not affected.
+func (c *Client) SetBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return redactError(err)
+	}
+
+	if c.baseURLMu != nil {
+		c.baseURLMu.Lock()
+		defer c.baseURLMu.Unlock()
+	}
+
+	c.BaseURL = u
+
+	return nil
+}
+
+// baseURL returns the BaseURL of the client, which must not be modified.
+func (c *Client) baseURL() *url.URL {
+	if c.baseURLMu != nil {
+		c.baseURLMu.RLock()
+		defer c.baseURLMu.RUnlock()
+	}
+
+	return c.BaseURL
+}
+
 // NewRequest creates an API request. A relative URL can be provided in urlStr, which will be resolved to the
 // BaseURL of the Client. Relative URLs should always be specified without a preceding slash. If specified, the
 // value pointed to by body will be encoded and included in as the request body.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
-	rel, err := url.Parse(urlStr)
-	if err != nil {
-		return nil, err
+	return c.NewRequestWithContext(context.Background(), method, urlStr, body)
+}
+
+// NewRequestWithContext is like NewRequest, but the context ctx is passed to the MarshalerContext
+// and set as context of the request and the request options opts are applied.
+func (c *Client) NewRequestWithContext(ctx context.Context, method, urlStr string, body interface{}, opts ...RequestOpt) (*http.Request, error) {
+	if body == nil {
+		// no buffer and no marshaler, http.NoBody has no Content-Length
+		return c.newRequest(ctx, method, urlStr, http.NoBody, c.ContentType, c.ContentType, opts)
 	}
 
-	u := c.BaseURL.ResolveReference(rel)
+	marshaler := c.MarshalerContext
+	if marshaler == nil {
+		if c.Marshaler == nil {
+			panic("Marshaler is nil")
+		}
+
+		marshaler = MarshalerWithContext(c.Marshaler)
+	}
+
+	if c.streaming {
+		r, contentType, err := streamBody(ctx, marshaler, body, c.ContentType, c.streamThreshold)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := c.newRequest(ctx, method, urlStr, r, contentType, contentType, opts)
+		if b, ok := r.(io.Closer); ok && err != nil {
+			_ = b.Close()
+		}
 
-	if c.Marshaler == nil {
-		panic("Marshaler is nil")
+		return req, err
 	}
 
 	buf := new(bytes.Buffer)
 
-	contentType, err := c.Marshaler(buf, body, c.ContentType)
+	contentType, err := marshaler(ctx, buf, body, c.ContentType)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(method, u.String(), buf)
+	return c.newRequest(ctx, method, urlStr, buf, contentType, contentType, opts)
+}
+
+// initHeader precomputes the header(s) of every request, it must be called after the header or
+// the basic authentication of the client changed.
+func (c *Client) initHeader() {
+	c.baseHeader = c.requestHeader()
+}
+
+// requestHeader returns the header(s) and the basic authentication of every request.
+func (c *Client) requestHeader() http.Header {
+	h := c.header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+
+	if len(c.username) > 0 && (len(c.password) > 0 || c.allowEmptyPassword) {
+		auth := c.username + ":" + c.password
+		h.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	}
+
+	if c.bearerToken != "" {
+		h.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+
+	return h
+}
+
+// newRequest creates a request with the encoded body and the headers of the client.
+func (c *Client) newRequest(ctx context.Context, method, urlStr string, body io.Reader, contentType, accept string, opts []RequestOpt) (*http.Request, error) {
+	rel, err := url.Parse(urlStr)
 	if err != nil {
-		return nil, err
+		return nil, redactError(err)
 	}
 
-	if c.header != nil {
-		req.Header = c.header.Clone()
+	u := c.baseURL().ResolveReference(c.withBasePath(rel))
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, redactError(err)
 	}
 
-	if len(c.username) > 0 && len(c.password) > 0 {
-		req.SetBasicAuth(c.username, c.password)
+	base := c.baseHeader
+	if base == nil {
+		base = c.requestHeader()
 	}
 
-	req.Header.Add("Content-Type", contentType)
-	req.Header.Add("Accept", contentType)
+	req.Header = make(http.Header, len(base)+2)
+
+	for k, v := range base {
+		req.Header[k] = slices.Clone(v)
+	}
 
-	if c.RequestCallback == nil {
-		panic("RequestCallback is nil")
+	req.Header["Content-Type"] = append(req.Header["Content-Type"], contentType)
+	if len(c.accept) > 0 {
+		accept = strings.Join(c.accept, ", ")
 	}
 
-	return c.RequestCallback(req), nil
+	req.Header["Accept"] = append(req.Header["Accept"], accept)
+
+	if err := c.setToken(req); err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		if err := opt(req); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.requestMiddlewareChain(req), nil
 }
 
 // marshal is the default marshaler
@@ -267,11 +644,13 @@ func marshal(w io.Writer, v interface{}, mediaType string) (string, error) {
 		return ContentTypeJSON, MarshalJSON(w, v, mediaType)
 	case ContentTypeYAML:
 		return ContentTypeYAML, MarshalYAML(w, v, mediaType)
+	case ContentTypeJSONAPI:
+		return ContentTypeJSONAPI, MarshalJSONAPI(w, v, mediaType)
 	case ContentTypeText:
 		_, err := fmt.Fprint(w, v)
 		return ContentTypeText, err
 	default:
-		return mediaType, errors.Wrap(ErrUnknownContentType, mediaType)
+		return mediaType, fmt.Errorf("%s: %w", mediaType, ErrUnknownContentType)
 	}
 }
 
@@ -296,40 +675,173 @@ func MarshalYAML(w io.Writer, v interface{}, mediaType string) error {
 // pointed to by v, or returned as an error if an API error has occurred. If v implements the io.Writer interface,
 // the raw response will be written to v, without attempting to decode it.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
-	// rate limit
-	if c.limiter != nil {
-		if err := c.limiter.Wait(ctx); err != nil {
-			return nil, ErrTooManyRequest
+	ctx, cancel := c.withDefaultDeadline(ctx)
+	defer cancel()
+
+	if c.idempotency != nil && mutating(req.Method) && req.Header.Get("Idempotency-Key") != "" {
+		return c.doIdempotent(ctx, req, v)
+	}
+
+	return c.doRetry(ctx, req, v)
+}
+
+// dispatch sends the request req through the priority and offline queues of the client.
+func (c *Client) dispatch(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	if c.queue != nil {
+		if err := c.queue.acquire(ctx, priority(ctx, req)); err != nil {
+			return nil, err
 		}
+
+		defer c.queue.release()
 	}
 
-	resp, err := c.client.Do(req.WithContext(ctx))
-	if err != nil {
+	if c.offline != nil && mutating(req.Method) {
+		return c.doOffline(ctx, req, v)
+	}
+
+	return c.doBreaker(ctx, req, v)
+}
+
+// do sends the request req and decodes the response body into v.
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	if (c.group != nil || c.cache != nil) && req.Method == http.MethodGet {
+		return c.doShared(ctx, req, v)
+	}
+
+	resp, err := c.send(ctx, req)
+	if err != nil || resp == nil {
+		if resp != nil && resp.Body != nil {
+			_ = c.closeBody(resp.Body)
+		}
+
 		return resp, err
 	}
 
 	defer func() {
-		if rerr := resp.Body.Close(); rerr == nil {
+		if rerr := c.closeBody(resp.Body); rerr == nil {
 			err = rerr
 		}
 	}()
 
-	if c.ResponseCallback == nil {
-		panic("ResponseCallback is nil")
+	err = c.Unmarshal(resp, v)
+
+	return resp, err
+}
+
+// send sends the request and returns the response processed by the ResponseCallback, the
+// caller must close its body.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	// rate limit
+	if l := c.rateLimiter(ctx, req); l != nil {
+		if err := l.Wait(ctx); err != nil {
+			return nil, ErrTooManyRequest
+		}
+	}
+
+	req, err := c.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+
+	tees := c.responseTees(req)
+	req = req.WithContext(ctx)
+	counters := c.countTransfer(ctx, req)
+
+	if c.etags != nil {
+		req = c.etags.ifMatch(req)
+	}
+
+	var entry HAREntry
+	if c.har != nil {
+		entry = c.har.start(req, c.Now())
+	}
+
+	c.stats.begin()
+
+	resp, err := c.client.Do(req)
+	err = redactError(err)
+
+	c.stats.end(resp, err)
+
+	if c.har != nil {
+		c.har.record(entry, resp, err, c.Now)
 	}
 
-	resp, err = c.ResponseCallback(resp)
 	if err != nil {
 		return resp, err
 	}
 
-	if c.Unmarshaler == nil {
-		panic("Unmarshaler is nil")
+	if len(counters) > 0 && resp.Body != nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, counters: counters}
 	}
 
-	err = c.Unmarshaler(resp.Body, v, c.ContentType)
+	if len(tees) > 0 && resp.Body != nil {
+		resp.Body = &teeBody{Reader: io.TeeReader(resp.Body, io.MultiWriter(tees...)), Closer: resp.Body}
+	}
 
-	return resp, err
+	c.reportDeprecation(resp)
+
+	if c.ResponseCallback == nil {
+		_ = resp.Body.Close()
+
+		panic("ResponseCallback is nil")
+	}
+
+	resp, err = c.responseMiddlewareChain(resp)
+
+	if c.etags != nil {
+		c.etags.update(req, resp, err)
+	}
+
+	return resp, preconditionFailed(resp, err)
+}
+
+// prepare applies the rewrite rules and the security policy of the client to the request req
+// before it is sent, also by Ping and Warmup.
+func (c *Client) prepare(req *http.Request) (*http.Request, error) {
+	if len(c.rewrites) > 0 {
+		req = c.rewrite(req)
+	}
+
+	if c.policy != nil {
+		if err := c.policy.checkURL(req.URL.Scheme); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// Unmarshal decodes the body of the response resp into v (see Do) with the UnmarshalerContext
+// of the client or, if it is nil, the Unmarshaler. The context of the request of resp is passed
+// to the UnmarshalerContext. The media type is the ContentType of the client or, with WithAccept,
+// the accepted media types are tried.
+func (c *Client) Unmarshal(resp *http.Response, v interface{}) error {
+	// the raw bodies buffered by DoResult, GraphQL and WithIdempotencyCache are decoded by them
+	if buf, ok := v.(*bodyBuffer); ok {
+		_, err := io.Copy(buf, resp.Body)
+		return err
+	}
+
+	unmarshaler := c.UnmarshalerContext
+	if unmarshaler == nil {
+		if c.Unmarshaler == nil {
+			panic("Unmarshaler is nil")
+		}
+
+		unmarshaler = UnmarshalerWithContext(c.Unmarshaler)
+	}
+
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+
+	if _, ok := v.(io.Writer); len(c.accept) > 0 && v != nil && !ok {
+		return c.unmarshalAccepted(ctx, unmarshaler, resp, v)
+	}
+
+	return unmarshaler(ctx, resp, v, c.ContentType)
 }
 
 // unmarshal is the default unmarshaler
@@ -348,11 +860,13 @@ func unmarshal(r io.Reader, v interface{}, mediaType string) error {
 		return UnmarshalJSON(r, v, mediaType)
 	case ContentTypeYAML:
 		return UnmarshalYAML(r, v, mediaType)
+	case ContentTypeJSONAPI:
+		return UnmarshalJSONAPI(r, v, mediaType)
 	case ContentTypeText:
 		if x, ok := v.(*string); ok {
 			buf := new(bytes.Buffer)
 			if _, err := buf.ReadFrom(r); err != nil {
-				return errors.Wrap(err, "read into buffer")
+				return fmt.Errorf("read into buffer: %w", err)
 			}
 
 			*x = buf.String()
@@ -360,9 +874,9 @@ func unmarshal(r io.Reader, v interface{}, mediaType string) error {
 			return nil
 		}
 
-		return errors.New("target type is not string")
+		return ErrTargetType
 	default:
-		return errors.Wrap(ErrUnknownContentType, mediaType)
+		return fmt.Errorf("%s: %w", mediaType, ErrUnknownContentType)
 	}
 }
 
@@ -387,11 +901,16 @@ func requestCallback(r *http.Request) *http.Request {
 }
 
 // responseCallback checks the API response for errors, and returns them if present. A response is considered an
-// error if it has a status code outside the 200 range. API error responses are expected to have no response body.
+// error (HTTPError) if it has a status code outside the 200 range. API error responses are expected to have no
+// response body.
 func responseCallback(r *http.Response) (*http.Response, error) {
 	if c := r.StatusCode; c >= 200 && c <= 299 {
 		return r, nil
 	}
 
-	return r, errors.New(r.Status)
+	return r, &HTTPError{
+		StatusCode: r.StatusCode,
+		Status:     r.Status,
+		Header:     r.Header,
+	}
 }
@@ -3,14 +3,13 @@ package httpclient
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
-
-	"github.com/pkg/errors"
 )
 
 // drainBody reads all of b to memory and then returns two equivalent
@@ -55,8 +54,8 @@ func ExampleRequestCallbackFunc() {
 	// Clients functionality is not affected by this, because the standard requestCallback function of
 	// the client just returns the unmodified request (r).
 	c.RequestCallback = func(r *http.Request) *http.Request {
-		// to convert the request in an appropriate curl command
-		// command, _ := http2curl.GetCurlCommand(r)
+		// to convert the request in an appropriate curl command (with redacted credentials)
+		// command, _ := CurlCommand(r)
 		fmt.Fprintf(os.Stdout, "Accept: %s\nContent-Type: %s\nMethod: %s", r.Header.Get("Accept"), r.Header.Get("Content-Type"), r.Method)
 
 		return r
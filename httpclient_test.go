@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -123,6 +125,12 @@ func TestClient(t *testing.T) {
 		assert.Equal(t, req.Header["X-Requested-By"], []string{"test"})
 		assert.Contains(t, req.Header, "Content-Type")
 		assert.Contains(t, req.Header, "Accept")
+
+		req.Header.Add("X-Requested-By", "other")
+
+		req, err = c.NewRequest(http.MethodGet, "/test", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, req.Header["X-Requested-By"], []string{"test"})
 	})
 
 	t.Run("new client with headers and basic auth", func(t *testing.T) {
@@ -144,6 +152,48 @@ func TestClient(t *testing.T) {
 		assert.Equal(t, passwd, passwd)
 	})
 
+	t.Run("new client with basic auth", func(t *testing.T) {
+		c, err := New(baseurl, WithBasicAuth(username, password))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "/test", nil)
+		assert.Nil(t, err)
+		user, passwd, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, username, user)
+		assert.Equal(t, password, passwd)
+
+		_, err = New(baseurl, WithBasicAuth(username, ""))
+		assert.EqualError(t, err, "username and password cannot be empty")
+
+		_, err = New(baseurl, WithBasicAuthAllowEmptyPassword("", ""))
+		assert.EqualError(t, err, "username cannot be empty")
+	})
+
+	t.Run("new client with basic auth without password", func(t *testing.T) {
+		c, err := New(baseurl, WithBasicAuthAllowEmptyPassword("token", ""))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "/test", nil)
+		assert.Nil(t, err)
+		user, passwd, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "token", user)
+		assert.Equal(t, "", passwd)
+	})
+
+	t.Run("new client with bearer token", func(t *testing.T) {
+		c, err := New(baseurl, WithBasicAuth(username, password), WithBearerToken("token"))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "/test", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+		_, err = New(baseurl, WithBearerToken(""))
+		assert.EqualError(t, err, "bearer token cannot be empty")
+	})
+
 	t.Run("new client valid baseurl valid HTTP client", func(t *testing.T) {
 		httpC := &http.Client{}
 		c, err := New(baseurl, WithHTTPClient(httpC))
@@ -170,16 +220,98 @@ func TestClient(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 
+	t.Run("clone client", func(t *testing.T) {
+		c, err := New(baseurl, WithHeader(http.Header{"X-Requested-By": []string{"test"}}))
+		assert.Nil(t, err)
+
+		clone := c.Clone()
+		clone.BaseURL.Path = "/v2/"
+		clone.header.Set("X-Requested-By", "clone")
+		clone.ContentType = ContentTypeYAML
+
+		assert.True(t, c.client == clone.client)
+		assert.Equal(t, "", c.BaseURL.Path)
+		assert.Equal(t, "test", c.header.Get("X-Requested-By"))
+		assert.Equal(t, ContentTypeJSON, c.ContentType)
+	})
+
+	t.Run("request header", func(t *testing.T) {
+		c, err := New(baseurl, WithHeader(http.Header{"X-Tenant": []string{"a", "b"}}))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "node", nil)
+		assert.Nil(t, err)
+
+		req.Header["X-Tenant"][0] = "modified"
+
+		req, err = c.NewRequest(http.MethodGet, "node", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b"}, req.Header["X-Tenant"])
+	})
+
+	t.Run("set base url", func(t *testing.T) {
+		c, err := New(baseurl)
+		assert.Nil(t, err)
+
+		u := c.GetBaseURL()
+		u.Path = "/v2/"
+		assert.Equal(t, baseurl, c.GetBaseURL().String())
+
+		req, err := c.NewRequest(http.MethodGet, "node", nil)
+		assert.Nil(t, err)
+		assert.Nil(t, c.SetBaseURL("https://blue.domain/v2/"))
+		assert.Equal(t, "https://hostname.domain/node", req.URL.String())
+
+		req, err = c.NewRequest(http.MethodGet, "node", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://blue.domain/v2/node", req.URL.String())
+
+		assert.NotNil(t, c.SetBaseURL(":"))
+		assert.Equal(t, "https://blue.domain/v2/", c.GetBaseURL().String())
+	})
+
+	t.Run("set base url concurrently", func(t *testing.T) {
+		c, err := New(baseurl)
+		assert.Nil(t, err)
+
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			for i := 0; i < 100; i++ {
+				_ = c.SetBaseURL(fmt.Sprintf("https://host%d.domain", i%2))
+			}
+		}()
+
+		for i := 0; i < 100; i++ {
+			_, err := c.NewRequest(http.MethodGet, "node", nil)
+			assert.Nil(t, err)
+		}
+
+		<-done
+	})
+
 	t.Run("new request with Marshaler == nil", func(t *testing.T) {
 		defer func() {
 			assert.NotNil(t, recover())
 		}()
 		c, _ := New(baseurl)
 		c.Marshaler = nil
-		_, _ = c.NewRequest(http.MethodGet, "node", nil)
+		_, _ = c.NewRequest(http.MethodPost, "node", testMessage)
 		assert.Fail(t, "NewRequest did not panic")
 	})
 
+	t.Run("new request without body", func(t *testing.T) {
+		c, _ := New(baseurl)
+		c.Marshaler = nil // not called
+		req, err := c.NewRequest(http.MethodGet, "node", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, http.NoBody, req.Body)
+		assert.Equal(t, int64(0), req.ContentLength)
+		assert.Equal(t, ContentTypeJSON, req.Header.Get("Content-Type"))
+	})
+
 	t.Run("new request with RequestCallback == nil", func(t *testing.T) {
 		defer func() {
 			assert.NotNil(t, recover())
@@ -283,7 +415,8 @@ func TestClient(t *testing.T) {
 		assert.Nil(t, err)
 		assert.NotNil(t, c)
 		_, err = c.NewRequest(http.MethodGet, "node", struct{ Message string }{Message: "it's only rock'n'roll"})
-		assert.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrUnknownContentType))
+		assert.Equal(t, "unknown/unknown: unknown media type", err.Error())
 	})
 
 	// Test server
@@ -427,7 +560,7 @@ func TestClient(t *testing.T) {
 		if resp != nil && resp.Body != nil {
 			_ = resp.Body.Close()
 		}
-		assert.NotNil(t, err)
+		assert.True(t, errors.Is(err, ErrTargetType))
 	})
 
 	t.Run("do a request with error in response using default ResponseCallback", func(t *testing.T) {
@@ -444,6 +577,11 @@ func TestClient(t *testing.T) {
 		}
 		assert.NotNil(t, err)
 		assert.Equal(t, "404 Not Found", err.Error())
+
+		var httpErr *HTTPError
+		assert.True(t, errors.As(fmt.Errorf("get node: %w", err), &httpErr))
+		assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+		assert.Equal(t, "text/plain; charset=utf-8", httpErr.Header.Get("Content-Type"))
 	})
 
 	t.Run("do a request using ResponseCallback to dump the response", func(t *testing.T) {
@@ -486,3 +624,60 @@ func TestClient(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+type ctxKey struct{}
+
+func TestContextCodecs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Codec", "test")
+		_, _ = io.Copy(w, r.Body)
+	}))
+	defer ts.Close()
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	c, err := New(ts.URL,
+		WithMarshalerContext(func(ctx context.Context, w io.Writer, v interface{}, mediaType string) (string, error) {
+			_, err := fmt.Fprintf(w, "%v:%v", ctx.Value(ctxKey{}), v)
+			return ContentTypeText, err
+		}),
+		WithUnmarshalerContext(func(ctx context.Context, resp *http.Response, v interface{}, mediaType string) error {
+			b, err := ioutil.ReadAll(resp.Body)
+			*v.(*string) = fmt.Sprintf("%s %s %v", b, resp.Header.Get("X-Codec"), ctx.Value(ctxKey{}))
+
+			return err
+		}),
+	)
+	assert.Nil(t, err)
+
+	t.Run("context", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodPost, "node", "body")
+		assert.Nil(t, err)
+		assert.Equal(t, ContentTypeText, req.Header.Get("Content-Type"))
+
+		var act string
+		_, err = c.Do(ctx, req, &act)
+		assert.Nil(t, err)
+		assert.Equal(t, "value:body test value", act)
+	})
+
+	t.Run("adapters", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		ct, err := MarshalerWithContext(marshal)(ctx, buf, testMessage, ContentTypeJSON)
+		assert.Nil(t, err)
+		assert.Equal(t, ContentTypeJSON, ct)
+
+		act := message{}
+		resp := &http.Response{Body: ioutil.NopCloser(buf)}
+		assert.Nil(t, UnmarshalerWithContext(unmarshal)(ctx, resp, &act, ContentTypeJSON))
+		assert.Equal(t, testMessage, act)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := New(ts.URL, WithMarshalerContext(nil))
+		assert.NotNil(t, err)
+
+		_, err = New(ts.URL, WithUnmarshalerContext(nil))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyKeyReused is returned for a request with the Idempotency-Key of a cached request
+// with another method, URL or body.
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused for another request")
+
+// WithIdempotencyCache is a client option for caching the responses of mutating requests (POST,
+// PUT, PATCH and DELETE) with an Idempotency-Key header for the duration ttl. A request with the
+// key of a request in flight or completed within ttl is not sent, it gets the (shared) response
+// of the first request, e.g. for batch jobs retrying heavily. Requests failing without response
+// are not cached, so they can be retried.
+func WithIdempotencyCache(ttl time.Duration) Opt {
+	return func(c *Client) error {
+		if ttl <= 0 {
+			return errors.New("idempotency cache ttl must be positive")
+		}
+
+		c.idempotency = &idempotencyCache{ttl: ttl, entries: map[string]*idempotencyEntry{}}
+
+		return nil
+	}
+}
+
+// idempotencyCache are the results of mutating requests by idempotency key.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotencyEntry
+}
+
+// idempotencyEntry is the result of a request, it is available when done is closed.
+type idempotencyEntry struct {
+	fingerprint string
+	done        chan struct{}
+	expires     time.Time
+	resp        *http.Response
+	body        []byte
+	err         error
+}
+
+// entry returns the entry of key and whether it was created and the request has to be sent.
+func (ic *idempotencyCache) entry(key, fingerprint string, now time.Time) (*idempotencyEntry, bool, error) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	for k, e := range ic.entries {
+		if !e.expires.IsZero() && !now.Before(e.expires) {
+			delete(ic.entries, k)
+		}
+	}
+
+	if e, ok := ic.entries[key]; ok {
+		if e.fingerprint != fingerprint {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+
+		return e, false, nil
+	}
+
+	e := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	ic.entries[key] = e
+
+	return e, true, nil
+}
+
+// remove removes the entry e of key.
+func (ic *idempotencyCache) remove(key string, e *idempotencyEntry) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	if ic.entries[key] == e {
+		delete(ic.entries, key)
+	}
+}
+
+// doIdempotent sends the mutating request req with an idempotency key or returns the cached
+// response of the key.
+func (c *Client) doIdempotent(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	key := req.Header.Get("Idempotency-Key")
+
+	fingerprint, err := requestFingerprint(req)
+	if err != nil {
+		return nil, err
+	}
+
+	e, send, err := c.idempotency.entry(key, fingerprint, c.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if send {
+		buf := &bodyBuffer{budget: c.budget}
+		defer buf.release()
+
+		e.resp, e.err = c.doRetry(ctx, req, buf)
+		e.body = append([]byte(nil), buf.Bytes()...)
+
+		if e.resp == nil {
+			c.idempotency.remove(key, e)
+		}
+
+		c.idempotency.mu.Lock()
+		e.expires = c.Now().Add(c.idempotency.ttl)
+		c.idempotency.mu.Unlock()
+
+		close(e.done)
+	} else {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if e.resp == nil {
+		return nil, e.err
+	}
+
+	resp := *e.resp
+	resp.Header = e.resp.Header.Clone()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(e.body))
+
+	if e.err != nil {
+		return &resp, e.err
+	}
+
+	return &resp, c.Unmarshal(&resp, v)
+}
+
+// requestFingerprint returns the fingerprint of the method, URL and body of req, the body is
+// read and replaced.
+func requestFingerprint(req *http.Request) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.String() + "\n"))
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+
+		_ = req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,127 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithIdempotencyCache(t *testing.T) {
+	var calls int64
+
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&calls, 1)
+
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+
+		fmt.Fprintf(w, `{"Text":"%d"}`, n)
+	}))
+	defer ts.Close()
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := ClockFunc(func() time.Time { return now })
+
+	c, err := New(ts.URL, WithIdempotencyCache(time.Minute), WithClock(clock))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	do := func(path, key string, body interface{}) (message, error) {
+		req, err := c.NewRequest(http.MethodPost, path, body)
+		assert.Nil(t, err)
+		req.Header.Set("Idempotency-Key", key)
+
+		m := message{}
+		_, err = c.Do(ctx, req, &m)
+
+		return m, err
+	}
+
+	t.Run("in flight", func(t *testing.T) {
+		results := make([]message, 3)
+		wg := sync.WaitGroup{}
+
+		for i := range results {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				m, err := do("/slow", "a", message{Text: "a"})
+				assert.Nil(t, err)
+				results[i] = m
+			}(i)
+		}
+
+		assert.Eventually(t, func() bool { return atomic.LoadInt64(&calls) == 1 }, time.Second, time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+		assert.Equal(t, []message{{"1"}, {"1"}, {"1"}}, results)
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		m, err := do("/slow", "a", message{Text: "a"})
+		assert.Nil(t, err)
+		assert.Equal(t, "1", m.Text)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		m, err := do("/", "b", message{Text: "a"})
+		assert.Nil(t, err)
+		assert.Equal(t, "2", m.Text)
+	})
+
+	t.Run("reused key", func(t *testing.T) {
+		_, err := do("/", "b", message{Text: "other"})
+		assert.True(t, errors.Is(err, ErrIdempotencyKeyReused))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		m, err := do("/", "b", message{Text: "a"})
+		assert.Nil(t, err)
+		assert.Equal(t, "3", m.Text)
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodPost, "/", message{Text: "a"})
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		down.Close()
+
+		c, err := New(down.URL, WithIdempotencyCache(time.Minute))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodPost, "/", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Idempotency-Key", "c")
+
+		_, err = c.Do(ctx, req, nil)
+		assert.NotNil(t, err)
+		assert.Empty(t, c.idempotency.entries)
+	})
+}
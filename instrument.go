@@ -0,0 +1,32 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// Instrumentation is notified about the calls of the service methods of a generated client
+// (httpclient-gen-go -instrument), e.g. to start a span and to record the number and duration
+// of the calls per method.
+type Instrumentation interface {
+	// Start is called before the method of the service (interface type name) is called. The
+	// returned context is passed to the method, the returned function is called with the
+	// response and error of the method.
+	Start(ctx context.Context, service, method string) (context.Context, func(*http.Response, error))
+}
+
+// InstrumentationFunc is an adapter to use an ordinary function as Instrumentation.
+type InstrumentationFunc func(ctx context.Context, service, method string) (context.Context, func(*http.Response, error))
+
+// Start calls f(ctx, service, method).
+func (f InstrumentationFunc) Start(ctx context.Context, service, method string) (context.Context, func(*http.Response, error)) {
+	return f(ctx, service, method)
+}
+
+// WithInstrumentation is a client option for instrumenting the services of a generated client.
+func WithInstrumentation(i Instrumentation) Opt {
+	return func(c *Client) error {
+		c.Instrumentation = i
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentation(t *testing.T) {
+	t.Run("with instrumentation", func(t *testing.T) {
+		calls := []string{}
+
+		i := InstrumentationFunc(func(ctx context.Context, service, method string) (context.Context, func(*http.Response, error)) {
+			calls = append(calls, service+"."+method)
+
+			return ctx, func(_ *http.Response, err error) {
+				calls = append(calls, err.Error())
+			}
+		})
+
+		c, err := New(baseurl, WithInstrumentation(i))
+		assert.Nil(t, err)
+		assert.NotNil(t, c.Instrumentation)
+
+		ctx, end := c.Instrumentation.Start(context.Background(), "NodeService", "Get")
+		assert.NotNil(t, ctx)
+
+		end(nil, errors.New("failed"))
+		assert.Equal(t, []string{"NodeService.Get", "failed"}, calls)
+	})
+
+	t.Run("without instrumentation", func(t *testing.T) {
+		c, err := New(baseurl)
+		assert.Nil(t, err)
+		assert.Nil(t, c.Instrumentation)
+	})
+}
@@ -0,0 +1,366 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ContentTypeJSONAPI is the media type of JSON:API documents (see https://jsonapi.org).
+//
+// Resources are flattened into (and from) plain JSON objects, so Go structs only need json
+// tags: the type, id, attributes and relationships of a resource are fields of the same
+// object and related resources are objects with their type and id, or their attributes as
+// well if they are included in the document, e.g.
+//
+//	type Article struct {
+//	    Type   string  `json:"type"`
+//	    ID     string  `json:"id,omitempty"`
+//	    Title  string  `json:"title"`
+//	    Author *Person `json:"author,omitempty"`
+//	}
+//
+// When marshaling, the type is required and object fields (or arrays of objects) with a type
+// and an id are sent as relationships.
+const ContentTypeJSONAPI = "application/vnd.api+json"
+
+// ErrJSONAPIType is returned when marshaling a JSON:API resource without type.
+var ErrJSONAPIType = errors.New("jsonapi: resource has no type")
+
+// JSONAPIError is an error object of a JSON:API document.
+type JSONAPIError struct {
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status,omitempty"`
+	Code   string `json:"code,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	Source *struct {
+		Pointer   string `json:"pointer,omitempty"`
+		Parameter string `json:"parameter,omitempty"`
+		Header    string `json:"header,omitempty"`
+	} `json:"source,omitempty"`
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+// Error returns the detail or, if it is empty, the title of the error.
+func (e JSONAPIError) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+
+	return e.Title
+}
+
+// JSONAPIErrors is returned by JSONAPIErrorCallback for error responses with JSON:API errors.
+type JSONAPIErrors struct {
+	Errors []JSONAPIError `json:"errors"`
+	err    error
+}
+
+// Error implements the error interface.
+func (e *JSONAPIErrors) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return fmt.Sprintf("%s: %s", e.err, strings.Join(messages, "; "))
+}
+
+// Unwrap returns the error of the next ResponseCallbackFunc (e.g. *HTTPError).
+func (e *JSONAPIErrors) Unwrap() error {
+	return e.err
+}
+
+// JSONAPIErrorCallback returns a ResponseCallbackFunc which decodes the errors of JSON:API error
+// responses (see next) into a *JSONAPIErrors, e.g.
+//
+//	c.ResponseCallback = httpclient.JSONAPIErrorCallback(c.ResponseCallback)
+func JSONAPIErrorCallback(next ResponseCallbackFunc) ResponseCallbackFunc {
+	return func(r *http.Response) (*http.Response, error) {
+		r, err := next(r)
+		if err == nil || r == nil || r.Body == nil {
+			return r, err
+		}
+
+		e := &JSONAPIErrors{err: err}
+		if json.NewDecoder(r.Body).Decode(e) != nil || len(e.Errors) == 0 {
+			return r, err
+		}
+
+		return r, e
+	}
+}
+
+// JSONAPIInclude are the related resources to include in a JSON:API response. It is encoded as
+// comma separated list by QueryOptions, e.g.
+//
+//	type options struct {
+//	    Include httpclient.JSONAPIInclude `url:"include,omitempty"`
+//	}
+type JSONAPIInclude []string
+
+// EncodeValues implements query.Encoder.
+func (i JSONAPIInclude) EncodeValues(key string, v *url.Values) error {
+	v.Set(key, strings.Join(i, ","))
+
+	return nil
+}
+
+// JSONAPIFields are the sparse fieldsets of a JSON:API request by resource type. It is encoded
+// as fields[type]=a,b by QueryOptions, e.g.
+//
+//	type options struct {
+//	    Fields httpclient.JSONAPIFields `url:"fields,omitempty"`
+//	}
+type JSONAPIFields map[string][]string
+
+// EncodeValues implements query.Encoder.
+func (f JSONAPIFields) EncodeValues(key string, v *url.Values) error {
+	for typ, fields := range f {
+		v.Set(key+"["+typ+"]", strings.Join(fields, ","))
+	}
+
+	return nil
+}
+
+// jsonAPIDocument is a JSON:API document.
+type jsonAPIDocument struct {
+	Data     json.RawMessage   `json:"data"`
+	Included []jsonAPIResource `json:"included,omitempty"`
+}
+
+// jsonAPIResource is a resource object of a JSON:API document.
+type jsonAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id,omitempty"`
+	Attributes    map[string]json.RawMessage     `json:"attributes,omitempty"`
+	Relationships map[string]jsonAPIRelationship `json:"relationships,omitempty"`
+}
+
+// jsonAPIRelationship is a relationship object of a JSON:API resource.
+type jsonAPIRelationship struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// MarshalJSONAPI marshals v as JSON:API document (see ContentTypeJSONAPI).
+func MarshalJSONAPI(w io.Writer, v interface{}, mediaType string) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var data interface{}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	if err := d.Decode(&data); err != nil {
+		return err
+	}
+
+	switch x := data.(type) {
+	case map[string]interface{}:
+		if data, err = jsonAPIResourceOf(x); err != nil {
+			return err
+		}
+	case []interface{}:
+		resources := make([]interface{}, 0, len(x))
+
+		for _, e := range x {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				return ErrJSONAPIType
+			}
+
+			r, err := jsonAPIResourceOf(obj)
+			if err != nil {
+				return err
+			}
+
+			resources = append(resources, r)
+		}
+
+		data = resources
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// jsonAPIResourceOf returns the resource object of the flattened object obj.
+func jsonAPIResourceOf(obj map[string]interface{}) (map[string]interface{}, error) {
+	typ, ok := obj["type"].(string)
+	if !ok || typ == "" {
+		return nil, ErrJSONAPIType
+	}
+
+	r := map[string]interface{}{"type": typ}
+	attributes := map[string]interface{}{}
+	relationships := map[string]interface{}{}
+
+	for k, v := range obj {
+		switch {
+		case k == "type":
+		case k == "id":
+			r["id"] = v
+		case jsonAPIIdentifier(v) != nil:
+			relationships[k] = map[string]interface{}{"data": jsonAPIIdentifier(v)}
+		case jsonAPIIdentifiers(v) != nil:
+			relationships[k] = map[string]interface{}{"data": jsonAPIIdentifiers(v)}
+		default:
+			attributes[k] = v
+		}
+	}
+
+	if len(attributes) > 0 {
+		r["attributes"] = attributes
+	}
+
+	if len(relationships) > 0 {
+		r["relationships"] = relationships
+	}
+
+	return r, nil
+}
+
+// jsonAPIIdentifier returns the resource identifier of v, nil if v is not an object with a type
+// and an id.
+func jsonAPIIdentifier(v interface{}) map[string]interface{} {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	typ, _ := obj["type"].(string)
+	id, _ := obj["id"].(string)
+
+	if typ == "" || id == "" {
+		return nil
+	}
+
+	return map[string]interface{}{"type": typ, "id": id}
+}
+
+// jsonAPIIdentifiers returns the resource identifiers of v, nil if v is not a non-empty array of
+// objects with a type and an id.
+func jsonAPIIdentifiers(v interface{}) []interface{} {
+	a, ok := v.([]interface{})
+	if !ok || len(a) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, 0, len(a))
+
+	for _, e := range a {
+		id := jsonAPIIdentifier(e)
+		if id == nil {
+			return nil
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// UnmarshalJSONAPI unmarshals the JSON:API document of r into v (see ContentTypeJSONAPI).
+func UnmarshalJSONAPI(r io.Reader, v interface{}, mediaType string) error {
+	var doc jsonAPIDocument
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+
+	included := make(map[string]*jsonAPIResource, len(doc.Included))
+	for i := range doc.Included {
+		res := &doc.Included[i]
+		included[res.Type+"/"+res.ID] = res
+	}
+
+	data, err := flattenJSONAPI(doc.Data, included, map[string]bool{})
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// flattenJSONAPI returns the flattened resource (or array of resources) of the primary data or
+// relationship data raw. Included resources are flattened as well, unless they are already being
+// flattened (circular relationships).
+func flattenJSONAPI(raw json.RawMessage, included map[string]*jsonAPIResource, visiting map[string]bool) (interface{}, error) {
+	raw = bytes.TrimSpace(raw)
+
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	if raw[0] == '[' {
+		var resources []json.RawMessage
+
+		if err := json.Unmarshal(raw, &resources); err != nil {
+			return nil, err
+		}
+
+		flattened := make([]interface{}, 0, len(resources))
+
+		for _, r := range resources {
+			f, err := flattenJSONAPI(r, included, visiting)
+			if err != nil {
+				return nil, err
+			}
+
+			flattened = append(flattened, f)
+		}
+
+		return flattened, nil
+	}
+
+	var res jsonAPIResource
+
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+
+	key := res.Type + "/" + res.ID
+
+	if inc, ok := included[key]; ok && !visiting[key] && len(res.Attributes) == 0 && len(res.Relationships) == 0 {
+		res = *inc
+	}
+
+	obj := make(map[string]interface{}, 2+len(res.Attributes)+len(res.Relationships))
+
+	for k, v := range res.Attributes {
+		obj[k] = v
+	}
+
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	for k, rel := range res.Relationships {
+		f, err := flattenJSONAPI(rel.Data, included, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("relationship %s: %w", k, err)
+		}
+
+		obj[k] = f
+	}
+
+	obj["type"] = res.Type
+
+	if res.ID != "" {
+		obj["id"] = res.ID
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,201 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonAPIPerson struct {
+	Type     string            `json:"type"`
+	ID       string            `json:"id,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Articles []*jsonAPIArticle `json:"articles,omitempty"`
+}
+
+type jsonAPIArticle struct {
+	Type   string         `json:"type"`
+	ID     string         `json:"id,omitempty"`
+	Title  string         `json:"title"`
+	Tags   []string       `json:"tags,omitempty"`
+	Author *jsonAPIPerson `json:"author,omitempty"`
+}
+
+const testJSONAPIDocument = `{
+  "data": [{
+    "type": "articles",
+    "id": "1",
+    "attributes": {"title": "JSON:API", "tags": ["api"]},
+    "relationships": {"author": {"data": {"type": "people", "id": "9"}}}
+  }, {
+    "type": "articles",
+    "id": "2",
+    "attributes": {"title": "Go"},
+    "relationships": {"author": {"data": {"type": "people", "id": "8"}}}
+  }],
+  "included": [{
+    "type": "people",
+    "id": "9",
+    "attributes": {"name": "Dan"},
+    "relationships": {"articles": {"data": [{"type": "articles", "id": "1"}]}}
+  }]
+}`
+
+func TestUnmarshalJSONAPI(t *testing.T) {
+	t.Run("document", func(t *testing.T) {
+		var articles []jsonAPIArticle
+
+		assert.Nil(t, UnmarshalJSONAPI(strings.NewReader(testJSONAPIDocument), &articles, ContentTypeJSONAPI))
+		assert.Equal(t, []jsonAPIArticle{
+			{
+				Type:  "articles",
+				ID:    "1",
+				Title: "JSON:API",
+				Tags:  []string{"api"},
+				Author: &jsonAPIPerson{
+					Type:     "people",
+					ID:       "9",
+					Name:     "Dan",
+					Articles: []*jsonAPIArticle{{Type: "articles", ID: "1"}},
+				},
+			},
+			{
+				Type:   "articles",
+				ID:     "2",
+				Title:  "Go",
+				Author: &jsonAPIPerson{Type: "people", ID: "8"},
+			},
+		}, articles)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		a := &jsonAPIArticle{}
+
+		assert.Nil(t, UnmarshalJSONAPI(strings.NewReader(`{"data":null}`), &a, ContentTypeJSONAPI))
+		assert.Nil(t, a)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var a jsonAPIArticle
+
+		assert.NotNil(t, UnmarshalJSONAPI(strings.NewReader(`{"data":{"type":1}}`), &a, ContentTypeJSONAPI))
+	})
+}
+
+func TestMarshalJSONAPI(t *testing.T) {
+	t.Run("resource", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		a := jsonAPIArticle{
+			Type:   "articles",
+			Title:  "JSON:API",
+			Author: &jsonAPIPerson{Type: "people", ID: "9", Name: "Dan"},
+		}
+
+		assert.Nil(t, MarshalJSONAPI(buf, a, ContentTypeJSONAPI))
+		assert.JSONEq(t, `{"data":{
+			"type":"articles",
+			"attributes":{"title":"JSON:API"},
+			"relationships":{"author":{"data":{"type":"people","id":"9"}}}
+		}}`, buf.String())
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		p := []jsonAPIPerson{{
+			Type:     "people",
+			ID:       "9",
+			Articles: []*jsonAPIArticle{{Type: "articles", ID: "1"}, {Type: "articles", ID: "2"}},
+		}}
+
+		assert.Nil(t, MarshalJSONAPI(buf, p, ContentTypeJSONAPI))
+		assert.JSONEq(t, `{"data":[{
+			"type":"people",
+			"id":"9",
+			"relationships":{"articles":{"data":[{"type":"articles","id":"1"},{"type":"articles","id":"2"}]}}
+		}]}`, buf.String())
+	})
+
+	t.Run("no type", func(t *testing.T) {
+		assert.Equal(t, ErrJSONAPIType, MarshalJSONAPI(ioutil.Discard, jsonAPIArticle{Title: "JSON:API"}, ContentTypeJSONAPI))
+	})
+}
+
+func TestJSONAPI(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSONAPI)
+
+		switch r.URL.Path {
+		case "/articles":
+			assert.Equal(t, "author", r.URL.Query().Get("include"))
+			assert.Equal(t, "title", r.URL.Query().Get("fields[articles]"))
+			assert.Equal(t, "name", r.URL.Query().Get("fields[people]"))
+
+			_, _ = w.Write([]byte(testJSONAPIDocument))
+		case "/invalid":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"errors":[{"status":"422","title":"Invalid Attribute","detail":"title is required","source":{"pointer":"/data/attributes/title"}},{"title":"Invalid Type"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithContentType(ContentTypeJSONAPI))
+	assert.Nil(t, err)
+
+	c.ResponseCallback = JSONAPIErrorCallback(c.ResponseCallback)
+	ctx := context.Background()
+
+	t.Run("query", func(t *testing.T) {
+		u, err := QueryOptions("/articles", struct {
+			Include JSONAPIInclude `url:"include,omitempty"`
+			Fields  JSONAPIFields  `url:"fields,omitempty"`
+		}{
+			Include: JSONAPIInclude{"author"},
+			Fields:  JSONAPIFields{"articles": {"title"}, "people": {"name"}},
+		})
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, u, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, ContentTypeJSONAPI, req.Header.Get("Accept"))
+
+		var articles []jsonAPIArticle
+
+		_, err = c.Do(ctx, req, &articles)
+		assert.Nil(t, err)
+		assert.Len(t, articles, 2)
+		assert.Equal(t, "Dan", articles[0].Author.Name)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodPost, "/invalid", jsonAPIArticle{Type: "articles"})
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+		assert.EqualError(t, err, "422 Unprocessable Entity: title is required; Invalid Type")
+
+		var errs *JSONAPIErrors
+
+		assert.True(t, errors.As(err, &errs))
+		assert.Equal(t, "/data/attributes/title", errs.Errors[0].Source.Pointer)
+		assert.Equal(t, http.StatusUnprocessableEntity, statusCode(err))
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/missing", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+		assert.EqualError(t, err, "404 Not Found")
+	})
+}
@@ -0,0 +1,77 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// laneKey is the context key of the rate limit lane.
+type laneKey struct{}
+
+// WithLane returns a copy of ctx with the rate limit lane name for the requests sent with it
+// (see WithLaneLimiter).
+func WithLane(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, laneKey{}, name)
+}
+
+// RequestLane is a request option setting the rate limit lane name of the request (see
+// WithLane).
+func RequestLane(name string) RequestOpt {
+	return func(r *http.Request) error {
+		*r = *r.WithContext(WithLane(r.Context(), name))
+		return nil
+	}
+}
+
+// lane returns the rate limit lane of ctx or, if it has none, of the request req.
+func lane(ctx context.Context, req *http.Request) string {
+	if name, ok := ctx.Value(laneKey{}).(string); ok {
+		return name
+	}
+
+	name, _ := req.Context().Value(laneKey{}).(string)
+
+	return name
+}
+
+// WithLaneLimiter is a client option for rate limiting the requests of the lane name (see
+// WithLane and RequestLane) with the limiter l instead of the rate limiter of the client (see
+// WithRateLimiter), e.g. to keep interactive requests from waiting for the quota used up by a
+// batch job. Unlike separate clients, the lanes share the connections and the authentication.
+// Requests without lane or with a lane without limiter use the rate limiter of the client.
+func WithLaneLimiter(name string, l *rate.Limiter) Opt {
+	return func(c *Client) error {
+		if name == "" {
+			return errors.New("lane name cannot be empty")
+		}
+
+		if l == nil {
+			return errors.New("lane limiter cannot be nil")
+		}
+
+		// copy on write, the map is shared by the copies of the client
+		lanes := make(map[string]*rate.Limiter, len(c.lanes)+1)
+		for k, v := range c.lanes {
+			lanes[k] = v
+		}
+
+		lanes[name] = l
+		c.lanes = lanes
+
+		return nil
+	}
+}
+
+// rateLimiter returns the rate limiter of the request req sent with ctx, nil if there is none.
+func (c *Client) rateLimiter(ctx context.Context, req *http.Request) *rate.Limiter {
+	if len(c.lanes) > 0 {
+		if l, ok := c.lanes[lane(ctx, req)]; ok {
+			return l
+		}
+	}
+
+	return c.limiter
+}
@@ -0,0 +1,60 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestLanes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// the limiter of the client does not allow any request
+	c, err := New(ts.URL,
+		WithRateLimiter(rate.NewLimiter(rate.Limit(1), 0)),
+		WithLaneLimiter("interactive", rate.NewLimiter(rate.Inf, 0)),
+	)
+	assert.Nil(t, err)
+
+	do := func(ctx context.Context, opts ...RequestOpt) error {
+		req, err := c.NewRequestWithContext(context.Background(), http.MethodGet, "", nil, opts...)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+
+		return err
+	}
+
+	t.Run("client limiter", func(t *testing.T) {
+		assert.Equal(t, ErrTooManyRequest, do(context.Background()))
+		assert.Equal(t, ErrTooManyRequest, do(WithLane(context.Background(), "batch")))
+	})
+
+	t.Run("lane limiter", func(t *testing.T) {
+		assert.Nil(t, do(WithLane(context.Background(), "interactive")))
+		assert.Nil(t, do(context.Background(), RequestLane("interactive")))
+	})
+
+	t.Run("service options", func(t *testing.T) {
+		c, err := New(ts.URL, WithServiceOptions("Node", WithLaneLimiter("batch", rate.NewLimiter(1, 1))))
+		assert.Nil(t, err)
+
+		s, err := c.ServiceClient("Node")
+		assert.Nil(t, err)
+		assert.Len(t, s.lanes, 1)
+		assert.Len(t, c.lanes, 0)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := New(ts.URL, WithLaneLimiter("", rate.NewLimiter(1, 1)))
+		assert.NotNil(t, err)
+
+		_, err = New(ts.URL, WithLaneLimiter("batch", nil))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,227 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrLinkNotFound is returned by FollowLink if there is no link with the relation type.
+	ErrLinkNotFound = errors.New("link not found")
+
+	// ErrTemplatedLink is returned by FollowLink for templated links, which have to be expanded
+	// by the caller.
+	ErrTemplatedLink = errors.New("templated link")
+)
+
+// Link is a hypermedia link of a Link header (see RFC 8288) or of the _links of a HAL resource.
+type Link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// Links are hypermedia links by relation type.
+type Links map[string][]Link
+
+// Get returns the first link with the relation type rel, which is matched case-insensitively
+// if there is no exact match.
+func (l Links) Get(rel string) (Link, bool) {
+	if links := l[rel]; len(links) > 0 {
+		return links[0], true
+	}
+
+	for r, links := range l {
+		if strings.EqualFold(r, rel) && len(links) > 0 {
+			return links[0], true
+		}
+	}
+
+	return Link{}, false
+}
+
+// UnmarshalJSON decodes HAL links, which are a link object or an array of link objects per
+// relation type.
+func (l *Links) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	links := make(Links, len(raw))
+
+	for rel, v := range raw {
+		var link Link
+
+		if err := json.Unmarshal(v, &link); err == nil {
+			links[rel] = []Link{link}
+			continue
+		}
+
+		var list []Link
+
+		if err := json.Unmarshal(v, &list); err != nil {
+			return fmt.Errorf("link %s: %w", rel, err)
+		}
+
+		links[rel] = list
+	}
+
+	*l = links
+
+	return nil
+}
+
+// HAL can be embedded in response types of HAL (application/hal+json) resources to decode their
+// _links, e.g.
+//
+//	type Order struct {
+//	    httpclient.HAL
+//	    Total float64 `json:"total"`
+//	}
+type HAL struct {
+	Links Links `json:"_links,omitempty"`
+}
+
+// HALLinks returns the _links of the resource.
+func (h HAL) HALLinks() Links {
+	return h.Links
+}
+
+// ResponseLinks returns the links of the Link headers of the response resp and, if the decoded
+// response body v has a HALLinks method (e.g. by embedding HAL), its _links. Relative links are
+// resolved against the URL of the request of resp.
+func ResponseLinks(resp *http.Response, v interface{}) Links {
+	links := Links{}
+
+	for _, l := range parseLinks(resp.Header) {
+		for _, rel := range l.rels {
+			links[rel] = append(links[rel], l.Link)
+		}
+	}
+
+	if hal, ok := v.(interface{ HALLinks() Links }); ok {
+		for rel, l := range hal.HALLinks() {
+			links[rel] = append(links[rel], l...)
+		}
+	}
+
+	if resp.Request == nil || resp.Request.URL == nil {
+		return links
+	}
+
+	for _, l := range links {
+		for i := range l {
+			if l[i].Templated {
+				continue
+			}
+
+			if u, err := resp.Request.URL.Parse(l[i].Href); err == nil {
+				l[i].Href = u.String()
+			}
+		}
+	}
+
+	return links
+}
+
+// FollowLink fetches the resource of the link with the relation type rel of links (see
+// ResponseLinks) with a GET request and decodes it into v (see Do). Relative links are resolved
+// against the BaseURL (see NewRequest).
+func (c *Client) FollowLink(ctx context.Context, links Links, rel string, v interface{}, opts ...RequestOpt) (*http.Response, error) {
+	link, ok := links.Get(rel)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", rel, ErrLinkNotFound)
+	}
+
+	if link.Templated {
+		return nil, fmt.Errorf("%s: %w", rel, ErrTemplatedLink)
+	}
+
+	req, err := c.NewRequestWithContext(ctx, http.MethodGet, link.Href, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(ctx, req, v)
+}
+
+// LinkURL returns the target URL of the link with the relation type rel in the Link
+// header(s) of h (see RFC 8288), e.g. for
+//
+//	Link: <https://api.example.com/posts?page=2>; rel="next"
+//
+// LinkURL(h, "next") returns "https://api.example.com/posts?page=2". An empty string
+// is returned if there is no such link.
+func LinkURL(h http.Header, rel string) string {
+	for _, l := range parseLinks(h) {
+		for _, r := range l.rels {
+			if strings.EqualFold(r, rel) {
+				return l.Href
+			}
+		}
+	}
+
+	return ""
+}
+
+// headerLink is a link of a Link header with its relation types.
+type headerLink struct {
+	Link
+	rels []string
+}
+
+// parseLinks returns the links of the Link header(s) of h.
+func parseLinks(h http.Header) []headerLink {
+	var links []headerLink
+
+	for _, v := range h.Values("Link") {
+		for v != "" {
+			start := strings.IndexByte(v, '<')
+			end := strings.IndexByte(v, '>')
+
+			if start < 0 || end < start {
+				break
+			}
+
+			l := headerLink{Link: Link{Href: v[start+1 : end]}}
+			v = v[end+1:]
+
+			params := v
+			if next := strings.IndexByte(v, '<'); next >= 0 {
+				params, v = v[:next], v[next:]
+			} else {
+				v = ""
+			}
+
+			for _, p := range strings.Split(params, ";") {
+				kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+				if len(kv) != 2 {
+					continue
+				}
+
+				value := strings.Trim(strings.TrimRight(kv[1], ", "), `"`)
+
+				switch strings.ToLower(kv[0]) {
+				case "rel":
+					l.rels = strings.Fields(value)
+				case "type":
+					l.Type = value
+				case "title":
+					l.Title = value
+				}
+			}
+
+			links = append(links, l)
+		}
+	}
+
+	return links
+}
@@ -0,0 +1,149 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkURL(t *testing.T) {
+	h := http.Header{}
+	h.Add("Link", `<https://hostname.domain/posts?page=1>; rel="first", <https://hostname.domain/posts?page=3>; rel="next"`)
+	h.Add("Link", `<https://hostname.domain/posts?page=9>; rel="last alternate"`)
+
+	t.Run("next", func(t *testing.T) {
+		assert.Equal(t, "https://hostname.domain/posts?page=3", LinkURL(h, "next"))
+	})
+
+	t.Run("multiple relation types", func(t *testing.T) {
+		assert.Equal(t, "https://hostname.domain/posts?page=9", LinkURL(h, "alternate"))
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		assert.Equal(t, "https://hostname.domain/posts?page=1", LinkURL(h, "First"))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		assert.Equal(t, "", LinkURL(h, "prev"))
+		assert.Equal(t, "", LinkURL(http.Header{}, "next"))
+	})
+}
+
+func TestLinks(t *testing.T) {
+	type order struct {
+		HAL
+		Total float64 `json:"total"`
+	}
+
+	t.Run("HAL", func(t *testing.T) {
+		var o order
+
+		assert.Nil(t, json.Unmarshal([]byte(`{
+			"_links": {
+				"self": {"href": "/orders/1"},
+				"items": [{"href": "/orders/1/items/1"}, {"href": "/orders/1/items/2"}],
+				"find": {"href": "/orders{?id}", "templated": true}
+			},
+			"total": 30
+		}`), &o))
+		assert.Equal(t, 30.0, o.Total)
+		assert.Equal(t, Links{
+			"self":  {{Href: "/orders/1"}},
+			"items": {{Href: "/orders/1/items/1"}, {Href: "/orders/1/items/2"}},
+			"find":  {{Href: "/orders{?id}", Templated: true}},
+		}, o.Links)
+
+		l, ok := o.Links.Get("Self")
+		assert.True(t, ok)
+		assert.Equal(t, "/orders/1", l.Href)
+
+		_, ok = o.Links.Get("next")
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var o order
+
+		assert.NotNil(t, json.Unmarshal([]byte(`{"_links":{"self":"/orders/1"}}`), &o))
+	})
+
+	t.Run("response", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "https://hostname.domain/api/orders/1", nil)
+		assert.Nil(t, err)
+
+		resp := &http.Response{Header: http.Header{}, Request: req}
+		resp.Header.Add("Link", `</api/orders/2>; rel="next"; title="Next order"; type="application/hal+json"`)
+
+		o := &order{HAL: HAL{Links: Links{"self": {{Href: "1"}}, "find": {{Href: "/orders{?id}", Templated: true}}}}}
+
+		assert.Equal(t, Links{
+			"next": {{Href: "https://hostname.domain/api/orders/2", Title: "Next order", Type: "application/hal+json"}},
+			"self": {{Href: "https://hostname.domain/api/orders/1"}},
+			"find": {{Href: "/orders{?id}", Templated: true}},
+		}, ResponseLinks(resp, o))
+	})
+}
+
+func TestFollowLink(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+
+		switch r.URL.Path {
+		case "/orders/1":
+			w.Header().Set("Link", `</orders/3>; rel="next"`)
+			_, _ = w.Write([]byte(`{"_links":{"customer":{"href":"/customers/9"}},"total":30}`))
+		case "/customers/9":
+			assert.Equal(t, "basic", r.Header.Get("X-Auth"))
+			_ = json.NewEncoder(w).Encode(message{Text: "customer 9"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithHeader(http.Header{"X-Auth": []string{"basic"}}))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	var o struct {
+		HAL
+		Total float64 `json:"total"`
+	}
+
+	req, err := c.NewRequest(http.MethodGet, "/orders/1", nil)
+	assert.Nil(t, err)
+
+	resp, err := c.Do(ctx, req, &o)
+	assert.Nil(t, err)
+
+	links := ResponseLinks(resp, o)
+
+	t.Run("HAL", func(t *testing.T) {
+		var m message
+
+		_, err := c.FollowLink(ctx, links, "customer", &m)
+		assert.Nil(t, err)
+		assert.Equal(t, "customer 9", m.Text)
+	})
+
+	t.Run("Link header", func(t *testing.T) {
+		_, err := c.FollowLink(ctx, links, "next", nil)
+		assert.Equal(t, http.StatusNotFound, statusCode(err))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := c.FollowLink(ctx, links, "prev", nil)
+		assert.True(t, errors.Is(err, ErrLinkNotFound))
+	})
+
+	t.Run("templated", func(t *testing.T) {
+		_, err := c.FollowLink(ctx, Links{"find": {{Href: "/orders{?id}", Templated: true}}}, "find", nil)
+		assert.True(t, errors.Is(err, ErrTemplatedLink))
+	})
+}
@@ -0,0 +1,52 @@
+package httpclient
+
+import "net/http"
+
+// UseRequestMiddleware appends the functions f to the request middleware of the client, which
+// is called by NewRequest in the order of registration after the RequestCallback, e.g. to inject
+// headers and to dump the requests. Every function gets the request returned by the previous
+// one. UseRequestMiddleware must not be called concurrently with requests, the copies of the
+// client (see Clone) have their own middleware.
+func (c *Client) UseRequestMiddleware(f ...RequestCallbackFunc) {
+	c.requestMiddleware = append(append([]RequestCallbackFunc(nil), c.requestMiddleware...), f...)
+}
+
+// requestMiddlewareChain calls the RequestCallback and the request middleware of the client
+// with req.
+func (c *Client) requestMiddlewareChain(req *http.Request) *http.Request {
+	if c.RequestCallback == nil {
+		panic("RequestCallback is nil")
+	}
+
+	req = c.RequestCallback(req)
+
+	for _, f := range c.requestMiddleware {
+		req = f(req)
+	}
+
+	return req
+}
+
+// UseResponseMiddleware appends the functions f to the response middleware of the client, which
+// is called for every response in the order of registration before the ResponseCallback, e.g.
+// for logging, metrics and the mapping of error responses to errors. Every function gets the
+// response returned by the previous one, the first error is returned without calling the
+// remaining functions and the ResponseCallback, which still checks the status code of the
+// responses passed on. UseResponseMiddleware must not be called concurrently with requests, the
+// copies of the client (see Clone) have their own middleware.
+func (c *Client) UseResponseMiddleware(f ...ResponseCallbackFunc) {
+	c.responseMiddleware = append(append([]ResponseCallbackFunc(nil), c.responseMiddleware...), f...)
+}
+
+// responseMiddlewareChain calls the response middleware and the ResponseCallback of the client
+// with resp.
+func (c *Client) responseMiddlewareChain(resp *http.Response) (*http.Response, error) {
+	for _, f := range c.responseMiddleware {
+		var err error
+		if resp, err = f(resp); err != nil {
+			return resp, err
+		}
+	}
+
+	return c.ResponseCallback(resp)
+}
@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseRequestMiddleware(t *testing.T) {
+	c, err := New(baseurl)
+	assert.Nil(t, err)
+
+	var calls []string
+
+	c.RequestCallback = func(r *http.Request) *http.Request {
+		calls = append(calls, "callback")
+		return r
+	}
+
+	c.UseRequestMiddleware(func(r *http.Request) *http.Request {
+		calls = append(calls, "header")
+		r.Header.Set("X-Request-Id", "1")
+
+		return r
+	})
+
+	clone := c.Clone()
+
+	c.UseRequestMiddleware(func(r *http.Request) *http.Request {
+		cmd, err := CurlCommand(r)
+		assert.Nil(t, err)
+
+		calls = append(calls, cmd)
+
+		return r
+	})
+
+	t.Run("order", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "1", req.Header.Get("X-Request-Id"))
+		assert.Equal(t, []string{
+			"callback",
+			"header",
+			`curl -X 'GET' 'https://hostname.domain/nodes' -H 'Accept: application/json' -H 'Content-Type: application/json' -H 'X-Request-Id: 1'`,
+		}, calls)
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		calls = nil
+
+		_, err := clone.NewRequest(http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"callback", "header"}, calls)
+	})
+}
+
+func TestUseResponseMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conflict":
+			w.WriteHeader(http.StatusConflict)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	errConflict := errors.New("conflict")
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	var logged []int
+
+	c.UseResponseMiddleware(func(resp *http.Response) (*http.Response, error) {
+		logged = append(logged, resp.StatusCode)
+		return resp, nil
+	}, func(resp *http.Response) (*http.Response, error) {
+		if resp.StatusCode == http.StatusConflict {
+			return resp, errConflict
+		}
+
+		return resp, nil
+	})
+
+	do := func(path string) error {
+		req, err := c.NewRequest(http.MethodGet, path, nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+
+		return err
+	}
+
+	assert.Nil(t, do("/"))
+	assert.Equal(t, errConflict, do("/conflict"))
+	assert.Equal(t, http.StatusNotFound, statusCode(do("/missing"))) // default status check
+	assert.Equal(t, []int{http.StatusOK, http.StatusConflict, http.StatusNotFound}, logged)
+}
@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrODataLiteral is returned by QueryOptions for filters with values which cannot be
+// represented as OData literals.
+var ErrODataLiteral = errors.New("unsupported OData literal")
+
+// OData are the OData system query options of a request. They are added by QueryOptions, directly
+// or embedded in other query options, e.g.
+//
+//	u, err := httpclient.QueryOptions("Products", httpclient.OData{
+//	    Filter: httpclient.ODataAnd(
+//	        httpclient.ODataEq("Category", "Kid's toys"),
+//	        httpclient.ODataLt("Price", 20),
+//	    ),
+//	    Select:  []string{"Name", "Price"},
+//	    OrderBy: []string{"Price desc"},
+//	    Top:     10,
+//	})
+//
+// adds the filter (escaped) and the other options to the URL:
+//
+//	$filter=Category eq 'Kid''s toys' and Price lt 20
+//
+// Zero values are omitted.
+type OData struct {
+	Filter  ODataFilter `url:"$filter,omitempty"`
+	Select  []string    `url:"$select,comma,omitempty"`
+	Expand  []string    `url:"$expand,comma,omitempty"`
+	OrderBy []string    `url:"$orderby,comma,omitempty"`
+	Top     int         `url:"$top,omitempty"`
+	Skip    int         `url:"$skip,omitempty"`
+	Count   bool        `url:"$count,omitempty"`
+	Search  string      `url:"$search,omitempty"`
+}
+
+// ODataFilter is an OData $filter expression built with the ODataEq, ODataAnd, ... functions,
+// which quote and escape the values as OData literals.
+type ODataFilter struct {
+	expr     string
+	compound bool // joined with and/or, parenthesized when joined again
+	err      error
+}
+
+// String returns the filter expression.
+func (f ODataFilter) String() string {
+	return f.expr
+}
+
+// Err returns the error of the filter, e.g. ErrODataLiteral for a value of an unsupported type.
+func (f ODataFilter) Err() error {
+	return f.err
+}
+
+// EncodeValues implements query.Encoder.
+func (f ODataFilter) EncodeValues(key string, v *url.Values) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	if f.expr != "" {
+		v.Set(key, f.expr)
+	}
+
+	return nil
+}
+
+// ODataEq returns the filter field eq value.
+func ODataEq(field string, value interface{}) ODataFilter {
+	return odataCompare(field, "eq", value)
+}
+
+// ODataNe returns the filter field ne value.
+func ODataNe(field string, value interface{}) ODataFilter {
+	return odataCompare(field, "ne", value)
+}
+
+// ODataGt returns the filter field gt value.
+func ODataGt(field string, value interface{}) ODataFilter {
+	return odataCompare(field, "gt", value)
+}
+
+// ODataGe returns the filter field ge value.
+func ODataGe(field string, value interface{}) ODataFilter {
+	return odataCompare(field, "ge", value)
+}
+
+// ODataLt returns the filter field lt value.
+func ODataLt(field string, value interface{}) ODataFilter {
+	return odataCompare(field, "lt", value)
+}
+
+// ODataLe returns the filter field le value.
+func ODataLe(field string, value interface{}) ODataFilter {
+	return odataCompare(field, "le", value)
+}
+
+// ODataIn returns the filter field in (values...).
+func ODataIn(field string, values ...interface{}) ODataFilter {
+	literals := make([]string, 0, len(values))
+
+	for _, v := range values {
+		l, err := ODataLiteral(v)
+		if err != nil {
+			return ODataFilter{err: err}
+		}
+
+		literals = append(literals, l)
+	}
+
+	return ODataFilter{expr: fmt.Sprintf("%s in (%s)", field, strings.Join(literals, ","))}
+}
+
+// ODataContains returns the filter contains(field,value).
+func ODataContains(field, value string) ODataFilter {
+	return odataFunc("contains", field, value)
+}
+
+// ODataStartsWith returns the filter startswith(field,value).
+func ODataStartsWith(field, value string) ODataFilter {
+	return odataFunc("startswith", field, value)
+}
+
+// ODataEndsWith returns the filter endswith(field,value).
+func ODataEndsWith(field, value string) ODataFilter {
+	return odataFunc("endswith", field, value)
+}
+
+// ODataAnd returns the conjunction of the filters, empty filters are ignored.
+func ODataAnd(filters ...ODataFilter) ODataFilter {
+	return odataJoin("and", filters)
+}
+
+// ODataOr returns the disjunction of the filters, empty filters are ignored.
+func ODataOr(filters ...ODataFilter) ODataFilter {
+	return odataJoin("or", filters)
+}
+
+// ODataNot returns the negation of the filter f.
+func ODataNot(f ODataFilter) ODataFilter {
+	if f.err != nil || f.expr == "" {
+		return f
+	}
+
+	return ODataFilter{expr: "not (" + f.expr + ")"}
+}
+
+// ODataLiteral returns value as OData literal: strings are quoted (with single quotes escaped),
+// time.Time values are formatted as RFC 3339 and nil is null. Other types than booleans, integers,
+// floats and fmt.Stringer (quoted as string) return ErrODataLiteral.
+func ODataLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case fmt.Stringer:
+		return ODataLiteral(v.String())
+	default:
+		return "", fmt.Errorf("%T: %w", value, ErrODataLiteral)
+	}
+}
+
+// odataCompare returns the filter field op value.
+func odataCompare(field, op string, value interface{}) ODataFilter {
+	l, err := ODataLiteral(value)
+	if err != nil {
+		return ODataFilter{err: err}
+	}
+
+	return ODataFilter{expr: field + " " + op + " " + l}
+}
+
+// odataFunc returns the filter name(field,value).
+func odataFunc(name, field, value string) ODataFilter {
+	l, _ := ODataLiteral(value)
+
+	return ODataFilter{expr: name + "(" + field + "," + l + ")"}
+}
+
+// odataJoin joins the filters with the logical operator op.
+func odataJoin(op string, filters []ODataFilter) ODataFilter {
+	exprs := make([]string, 0, len(filters))
+
+	for _, f := range filters {
+		if f.err != nil {
+			return f
+		}
+
+		if f.expr == "" {
+			continue
+		}
+
+		if f.compound {
+			exprs = append(exprs, "("+f.expr+")")
+			continue
+		}
+
+		exprs = append(exprs, f.expr)
+	}
+
+	return ODataFilter{expr: strings.Join(exprs, " "+op+" "), compound: len(exprs) > 1}
+}
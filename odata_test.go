@@ -0,0 +1,103 @@
+package httpclient
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestODataLiteral(t *testing.T) {
+	for _, tc := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{"Kid's toys", "'Kid''s toys'"},
+		{true, "true"},
+		{42, "42"},
+		{uint8(7), "7"},
+		{1.5, "1.5"},
+		{float32(0.1), "0.1"},
+		{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "2020-01-02T03:04:05Z"},
+		{time.Second, "'1s'"},
+	} {
+		l, err := ODataLiteral(tc.value)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, l)
+	}
+
+	_, err := ODataLiteral([]string{"a"})
+	assert.True(t, errors.Is(err, ErrODataLiteral))
+}
+
+func TestODataFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		filter   ODataFilter
+		expected string
+	}{
+		{"eq", ODataEq("Name", "O'Neil"), "Name eq 'O''Neil'"},
+		{"comparisons", ODataAnd(ODataNe("A", 1), ODataGt("B", 2), ODataGe("C", 3), ODataLt("D", 4), ODataLe("E", 5)), "A ne 1 and B gt 2 and C ge 3 and D lt 4 and E le 5"},
+		{"in", ODataIn("Status", "open", "closed"), "Status in ('open','closed')"},
+		{"functions", ODataOr(ODataContains("Name", "a"), ODataStartsWith("Name", "b"), ODataEndsWith("Name", "c")), "contains(Name,'a') or startswith(Name,'b') or endswith(Name,'c')"},
+		{"nested", ODataAnd(ODataOr(ODataEq("A", 1), ODataEq("B", 2)), ODataNot(ODataEq("C", "x and y"))), "(A eq 1 or B eq 2) and not (C eq 'x and y')"},
+		{"empty", ODataAnd(ODataFilter{}, ODataOr(ODataFilter{}, ODataEq("A", 1))), "A eq 1"},
+		{"not empty", ODataNot(ODataFilter{}), ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Nil(t, tc.filter.Err())
+			assert.Equal(t, tc.expected, tc.filter.String())
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		f := ODataAnd(ODataEq("A", 1), ODataNot(ODataIn("B", struct{}{})))
+		assert.True(t, errors.Is(f.Err(), ErrODataLiteral))
+		assert.True(t, errors.Is(ODataEq("A", []int{}).Err(), ErrODataLiteral))
+	})
+}
+
+func TestODataQueryOptions(t *testing.T) {
+	t.Run("options", func(t *testing.T) {
+		u, err := QueryOptions("Products?api-version=2", OData{
+			Filter:  ODataAnd(ODataEq("Category", "Kid's & baby's toys"), ODataLt("Price", 20)),
+			Select:  []string{"Name", "Price"},
+			Expand:  []string{"Supplier"},
+			OrderBy: []string{"Price desc", "Name"},
+			Top:     10,
+			Skip:    20,
+			Count:   true,
+		})
+		assert.Nil(t, err)
+
+		parsed, err := url.Parse(u)
+		assert.Nil(t, err)
+		assert.Equal(t, url.Values{
+			"api-version": {"2"},
+			"$filter":     {"Category eq 'Kid''s & baby''s toys' and Price lt 20"},
+			"$select":     {"Name,Price"},
+			"$expand":     {"Supplier"},
+			"$orderby":    {"Price desc,Name"},
+			"$top":        {"10"},
+			"$skip":       {"20"},
+			"$count":      {"true"},
+		}, parsed.Query())
+	})
+
+	t.Run("embedded", func(t *testing.T) {
+		u, err := QueryOptions("Products", struct {
+			OData
+			Version string `url:"api-version"`
+		}{OData: OData{Top: 1}, Version: "2"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Products?%24top=1&api-version=2", u)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := QueryOptions("Products", OData{Filter: ODataEq("Tags", []string{"a"})})
+		assert.True(t, errors.Is(err, ErrODataLiteral))
+	})
+}
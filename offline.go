@@ -0,0 +1,178 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// ErrQueued is returned for requests queued by the offline queue of the client (see
+// WithOfflineQueue).
+var ErrQueued = errors.New("request queued for replay")
+
+// OfflineStore persists the requests of the offline queue (see WithOfflineQueue) in order, e.g.
+// in a file or a local database.
+type OfflineStore interface {
+	// Append appends the request s to the queue.
+	Append(ctx context.Context, s ScheduledRequest) error
+
+	// List returns the queued requests in order.
+	List(ctx context.Context) ([]ScheduledRequest, error)
+
+	// Remove removes the request with the ID id from the queue.
+	Remove(ctx context.Context, id string) error
+}
+
+// WithOfflineQueue is a client option for storing and forwarding mutating requests (POST, PUT,
+// PATCH and DELETE) while the network or the server is down, e.g. for tools used over flaky
+// links. Requests failing with a network error, the status 502, 503 or 504 or ErrCircuitOpen
+// (see WithCircuitBreaker) are appended to the store s and Do returns ErrQueued. While requests
+// are queued, further mutating requests are queued as well to keep their order. Replay sends the
+// queued requests once the connectivity returns. Every mutating request gets an Idempotency-Key
+// header, if it has none, so the server can detect duplicates.
+func WithOfflineQueue(s OfflineStore) Opt {
+	return func(c *Client) error {
+		if s == nil {
+			return errors.New("offline store cannot be nil")
+		}
+
+		c.offline = s
+
+		return nil
+	}
+}
+
+// Replay sends the queued requests (see WithOfflineQueue) in order and returns the number of
+// requests sent. It stops at the first request failing because the network or the server is
+// still down, which remains queued. Other errors of the requests are returned, but the requests
+// are removed from the queue.
+func (c *Client) Replay(ctx context.Context) (int, error) {
+	if c.offline == nil {
+		return 0, nil
+	}
+
+	queued, err := c.offline.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+
+	for i, s := range queued {
+		req, err := c.scheduledRequest(ctx, s)
+		if err != nil {
+			return i, err
+		}
+
+		resp, err := c.doBreaker(ctx, req, nil)
+		if offline(ctx, resp, err) {
+			return i, errors.Join(append(errs, err)...)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", s.Method, s.URL, err))
+		}
+
+		if err := c.offline.Remove(ctx, s.ID); err != nil {
+			return i, errors.Join(append(errs, err)...)
+		}
+	}
+
+	return len(queued), errors.Join(errs...)
+}
+
+// doOffline sends the mutating request req or queues it, if the network or server is down or
+// other requests are queued.
+func (c *Client) doOffline(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	s, err := newScheduledRequest(req, c.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Header.Get("Idempotency-Key") == "" {
+		req.Header.Set("Idempotency-Key", s.ID)
+		s.Header.Set("Idempotency-Key", s.ID)
+	}
+
+	queued, err := c.offline.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(queued) > 0 {
+		return nil, c.enqueue(ctx, s, nil)
+	}
+
+	resp, err := c.doBreaker(ctx, req, v)
+	if offline(ctx, resp, err) {
+		return resp, c.enqueue(ctx, s, err)
+	}
+
+	return resp, err
+}
+
+// enqueue appends s to the offline queue, cause is the error of the request.
+func (c *Client) enqueue(ctx context.Context, s ScheduledRequest, cause error) error {
+	if err := c.offline.Append(ctx, s); err != nil {
+		return errors.Join(cause, fmt.Errorf("queue request: %w", err))
+	}
+
+	if cause != nil {
+		return fmt.Errorf("%w: %w", ErrQueued, cause)
+	}
+
+	return ErrQueued
+}
+
+// offline reports whether the request failed because the network or the server is down: dial
+// errors, timeouts, refused or reset connections, the status 502, 503 or 504 and an open
+// circuit breaker (ErrCircuitOpen).
+func offline(ctx context.Context, resp *http.Response, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+
+		return false
+	}
+
+	return connectionFailure(err)
+}
+
+// connectionFailure reports whether err is a failure of the connection: dial errors, timeouts
+// and refused or reset connections, not e.g. TLS or pinning failures, which cannot succeed later.
+func connectionFailure(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// mutating reports whether method is a mutating method.
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
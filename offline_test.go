@@ -0,0 +1,227 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryOfflineStore struct {
+	mu       sync.Mutex
+	requests []ScheduledRequest
+}
+
+func (s *memoryOfflineStore) Append(_ context.Context, r ScheduledRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r)
+
+	return nil
+}
+
+func (s *memoryOfflineStore) List(context.Context) ([]ScheduledRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]ScheduledRequest(nil), s.requests...), nil
+}
+
+func (s *memoryOfflineStore) Remove(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.requests {
+		if r.ID == id {
+			s.requests = append(s.requests[:i], s.requests[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func TestWithOfflineQueue(t *testing.T) {
+	var (
+		down     int32
+		mu       sync.Mutex
+		received []string
+		keys     []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		received = append(received, r.Method+" "+string(b))
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	store := &memoryOfflineStore{}
+
+	c, err := New(ts.URL, WithOfflineQueue(store))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	do := func(method string, body interface{}) error {
+		req, err := c.NewRequest(method, "/items", body)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+
+		return err
+	}
+
+	assert.Nil(t, do(http.MethodPost, message{Text: "1"}))
+	assert.Len(t, keys[0], 32)
+
+	atomic.StoreInt32(&down, 1)
+
+	err = do(http.MethodPost, message{Text: "2"})
+	assert.True(t, errors.Is(err, ErrQueued))
+	assert.EqualError(t, err, "request queued for replay: 503 Service Unavailable")
+
+	atomic.StoreInt32(&down, 0)
+
+	assert.True(t, errors.Is(do(http.MethodDelete, nil), ErrQueued))
+	assert.Nil(t, do(http.MethodGet, nil))
+	assert.Len(t, store.requests, 2)
+
+	atomic.StoreInt32(&down, 1)
+
+	n, err := c.Replay(ctx)
+	assert.Equal(t, 0, n)
+	assert.NotNil(t, err)
+
+	atomic.StoreInt32(&down, 0)
+
+	n, err = c.Replay(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	assert.Empty(t, store.requests)
+
+	assert.Equal(t, []string{
+		`POST {"Text":"1"}` + "\n",
+		"GET ",
+		`POST {"Text":"2"}` + "\n",
+		"DELETE ",
+	}, received)
+	assert.Equal(t, "", keys[1])
+	assert.Len(t, map[string]bool{keys[0]: true, keys[2]: true, keys[3]: true}, 3)
+
+	t.Run("network error", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		down.Close()
+
+		store := &memoryOfflineStore{}
+
+		c, err := New(down.URL, WithOfflineQueue(store))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodPut, "/items/1", message{Text: "1"})
+		assert.Nil(t, err)
+		req.Header.Set("Idempotency-Key", "key")
+
+		_, err = c.Do(ctx, req, nil)
+		assert.True(t, errors.Is(err, ErrQueued))
+		assert.Equal(t, "key", store.requests[0].Header.Get("Idempotency-Key"))
+	})
+
+	t.Run("pinning failure", func(t *testing.T) {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer ts.Close()
+
+		other := sha256.Sum256([]byte("other"))
+		store := &memoryOfflineStore{}
+
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()), WithPinnedCertificates(base64.StdEncoding.EncodeToString(other[:])), WithOfflineQueue(store))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodPut, "/items/1", message{Text: "1"})
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+		assert.True(t, errors.Is(err, ErrPinMismatch))
+		assert.False(t, errors.Is(err, ErrQueued))
+		assert.Len(t, store.requests, 0)
+	})
+	t.Run("circuit breaker", func(t *testing.T) {
+		var (
+			hits   int32
+			status int32 = http.StatusInternalServerError
+		)
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			w.WriteHeader(int(atomic.LoadInt32(&status)))
+		}))
+		defer ts.Close()
+
+		now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		store := &memoryOfflineStore{}
+
+		c, err := New(ts.URL,
+			WithClock(ClockFunc(func() time.Time { return now })),
+			WithCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 1, Cooldown: time.Minute}),
+			WithOfflineQueue(store),
+		)
+		assert.Nil(t, err)
+
+		post := func() error {
+			req, err := c.NewRequest(http.MethodPost, "/items", message{Text: "1"})
+			assert.Nil(t, err)
+
+			_, err = c.Do(ctx, req, nil)
+
+			return err
+		}
+
+		assert.Equal(t, http.StatusInternalServerError, statusCode(post()))
+		assert.Equal(t, CircuitOpen, c.CircuitState())
+
+		err = post()
+		assert.True(t, errors.Is(err, ErrQueued))
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+		for i := 0; i < 3; i++ {
+			assert.True(t, errors.Is(post(), ErrQueued)) // queued behind the first one
+		}
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+		assert.Len(t, store.requests, 4)
+
+		// the requests stay queued while the circuit is open
+		n, err := c.Replay(ctx)
+		assert.Equal(t, 0, n)
+		assert.True(t, errors.Is(err, ErrCircuitOpen))
+		assert.Len(t, store.requests, 4)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+		atomic.StoreInt32(&status, http.StatusOK)
+		now = now.Add(time.Minute)
+
+		n, err = c.Replay(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, CircuitClosed, c.CircuitState())
+		assert.Equal(t, int32(5), atomic.LoadInt32(&hits))
+	})
+}
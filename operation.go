@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrNoOperation is returned by AwaitOperation if the response does not refer to a
+// long-running operation.
+var ErrNoOperation = errors.New("no long-running operation")
+
+// OperationStatus can be implemented by the result type of AwaitOperation for APIs reporting
+// the state of long-running operations in the body of the status responses (e.g. with a field
+// status running, succeeded or failed) instead of with the status code 202. Done reports
+// whether the operation is finished and returns its error, if it failed.
+type OperationStatus interface {
+	Done() (bool, error)
+}
+
+// AwaitOperation follows the long-running operation of the response resp with status 202
+// (accepted): it polls the status URL of the Operation-Location or Location header with GET
+// requests until the status is not 202 anymore and returns the last response with its body
+// decoded into a value of type T (see DoResult). If T implements OperationStatus, the polling
+// continues until the operation is done. Between the requests it waits for the duration of the
+// Retry-After header of the last response, or interval if there is none. Error responses
+// return the error of the ResponseCallback of the client (e.g. the typed error of a generated
+// client).
+func AwaitOperation[T any](ctx context.Context, c *Client, resp *http.Response, interval time.Duration) (T, *http.Response, error) {
+	var zero T
+
+	if resp.StatusCode != http.StatusAccepted {
+		return zero, resp, fmt.Errorf("%w: %s", ErrNoOperation, resp.Status)
+	}
+
+	statusURL, err := operationURL(resp, "")
+	if err != nil {
+		return zero, resp, err
+	}
+
+	for {
+		if err := sleep(ctx, retryAfter(resp.Header, c.Now(), interval)); err != nil {
+			return zero, resp, err
+		}
+
+		req, err := c.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+		if err != nil {
+			return zero, resp, err
+		}
+
+		r, err := DoResult[T](ctx, c, req)
+		if r == nil {
+			return zero, resp, err
+		}
+
+		resp = r.Response
+
+		if r.StatusCode == http.StatusAccepted {
+			if statusURL, err = operationURL(resp, statusURL); err != nil {
+				return zero, resp, err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return zero, resp, err
+		}
+
+		if s, ok := any(r.Value).(OperationStatus); ok {
+			done, err := s.Done()
+			if err != nil {
+				return r.Value, resp, err
+			}
+
+			if !done {
+				continue
+			}
+		}
+
+		return r.Value, resp, nil
+	}
+}
+
+// operationURL returns the status URL of the operation of resp, resolved against the URL of its
+// request, or current if the response has none.
+func operationURL(resp *http.Response, current string) (string, error) {
+	loc := resp.Header.Get("Operation-Location")
+	if loc == "" {
+		loc = resp.Header.Get("Location")
+	}
+
+	if loc == "" {
+		if current == "" {
+			return "", fmt.Errorf("%w: no Operation-Location or Location header", ErrNoOperation)
+		}
+
+		return current, nil
+	}
+
+	if resp.Request == nil {
+		return loc, nil
+	}
+
+	u, err := resp.Request.URL.Parse(loc)
+	if err != nil {
+		return "", redactError(err)
+	}
+
+	return u.String(), nil
+}
+
+// retryAfter returns the duration of the Retry-After header h (seconds or HTTP date, relative
+// to now), or fallback if there is none.
+func retryAfter(h http.Header, now time.Time, fallback time.Duration) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return fallback
+	}
+
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return t.Sub(now)
+	}
+
+	return fallback
+}
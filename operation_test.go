@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type operation struct {
+	Status string
+	Error  string
+}
+
+func (o operation) Done() (bool, error) {
+	switch o.Status {
+	case "failed":
+		return true, errors.New(o.Error)
+	case "succeeded":
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func TestAwaitOperation(t *testing.T) {
+	var polls int64
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			w.Header().Set("Location", "ops/1")
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusAccepted)
+		case "/ops/1":
+			if atomic.AddInt64(&polls, 1) < 3 {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"Text":"done"}`))
+		case "/status":
+			n := atomic.AddInt64(&polls, 1)
+			switch {
+			case n < 2:
+				fmt.Fprint(w, `{"Status":"running"}`)
+			case r.URL.Query().Get("fail") != "":
+				fmt.Fprint(w, `{"Status":"failed","Error":"quota exceeded"}`)
+			default:
+				fmt.Fprint(w, `{"Status":"succeeded"}`)
+			}
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	accepted := func(header string, path string) *http.Response {
+		req, err := c.NewRequest(http.MethodPost, "/start", nil)
+		assert.Nil(t, err)
+
+		return &http.Response{
+			StatusCode: http.StatusAccepted,
+			Status:     "202 Accepted",
+			Header:     http.Header{header: {path}},
+			Request:    req,
+		}
+	}
+
+	t.Run("result", func(t *testing.T) {
+		atomic.StoreInt64(&polls, 0)
+
+		req, err := c.NewRequest(http.MethodPost, "/start", nil)
+		assert.Nil(t, err)
+
+		resp, err := c.Do(ctx, req, nil)
+		assert.Nil(t, err)
+
+		m, resp, err := AwaitOperation[message](ctx, c, resp, time.Millisecond)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "done", m.Text)
+		assert.Equal(t, int64(3), atomic.LoadInt64(&polls))
+	})
+
+	t.Run("operation status", func(t *testing.T) {
+		atomic.StoreInt64(&polls, 0)
+
+		o, _, err := AwaitOperation[operation](ctx, c, accepted("Operation-Location", "/status"), time.Millisecond)
+		assert.Nil(t, err)
+		assert.Equal(t, "succeeded", o.Status)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&polls))
+	})
+
+	t.Run("operation failed", func(t *testing.T) {
+		atomic.StoreInt64(&polls, 0)
+
+		_, _, err := AwaitOperation[operation](ctx, c, accepted("Operation-Location", "/status?fail=1"), time.Millisecond)
+		assert.EqualError(t, err, "quota exceeded")
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		_, resp, err := AwaitOperation[message](ctx, c, accepted("Location", "/error"), time.Millisecond)
+
+		var httpErr *HTTPError
+
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("no operation", func(t *testing.T) {
+		_, _, err := AwaitOperation[message](ctx, c, &http.Response{StatusCode: http.StatusOK, Status: "200 OK"}, 0)
+		assert.EqualError(t, err, "no long-running operation: 200 OK")
+
+		_, _, err = AwaitOperation[message](ctx, c, accepted("X-Other", "/status"), 0)
+		assert.True(t, errors.Is(err, ErrNoOperation))
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Second},
+		{"120", 2 * time.Minute},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		{"soon", time.Second},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.want, retryAfter(http.Header{"Retry-After": {tc.value}}, now, time.Second))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package httpclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+)
+
+// Pages returns an iterator over the items of the pages starting with urlStr for range-over-func
+// loops:
+//
+//	for post, err := range httpclient.Pages[*Post](ctx, c, "/posts") {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Every page is decoded into []T and the URL of the next page is taken from the Link header
+// (see LinkURL), a relative link is resolved against the URL of the response. The pages are
+// fetched lazily while iterating, errors (e.g. a canceled context) are yielded as last element.
+// The iterator can be ranged over repeatedly, every loop starts with urlStr.
+func Pages[T any](ctx context.Context, c *Client, urlStr string) iter.Seq2[T, error] {
+	return PagesFunc(ctx, c, urlStr, func(page []T, resp *http.Response) ([]T, string) {
+		next := LinkURL(resp.Header, "next")
+		if next == "" || resp.Request == nil {
+			return page, next
+		}
+
+		u, err := resp.Request.URL.Parse(next)
+		if err != nil {
+			return page, next // reported by NewRequest
+		}
+
+		return page, u.String()
+	})
+}
+
+// PagesFunc is like Pages, but every page is decoded into a value of type P and next returns its
+// items and the URL of the next page (empty for the last page), e.g. for APIs with a page token
+// in the response body.
+func PagesFunc[P, T any](ctx context.Context, c *Client, urlStr string, next func(page P, resp *http.Response) ([]T, string)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		u := urlStr
+
+		for u != "" {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			req, err := c.NewRequest(http.MethodGet, u, nil)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			page, resp, err := DoTyped[P](ctx, c, req)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var items []T
+
+			items, u = next(page, resp)
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
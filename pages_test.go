@@ -0,0 +1,175 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPages(t *testing.T) {
+	var fetched int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/error":
+			if page > 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		case "/token":
+			next := ""
+			if page < 2 {
+				next = strconv.Itoa(page + 1)
+			}
+
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": []int{page}, "next": next})
+
+			return
+		}
+
+		switch {
+		case page >= 3:
+		case r.URL.Path == "/v2/relative":
+			w.Header().Set("Link", fmt.Sprintf(`<?page=%d>; rel="next"`, page+1))
+		default:
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next"`, "http://"+r.Host, r.URL.Path, page+1))
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_ = json.NewEncoder(w).Encode([]message{{Text: fmt.Sprintf("%d.1", page)}, {Text: fmt.Sprintf("%d.2", page)}})
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	t.Run("all pages", func(t *testing.T) {
+		atomic.StoreInt32(&fetched, 0)
+		items := []string{}
+
+		for m, err := range Pages[message](context.Background(), c, "/posts?page=1") {
+			assert.Nil(t, err)
+
+			items = append(items, m.Text)
+		}
+
+		assert.Equal(t, []string{"1.1", "1.2", "2.1", "2.2", "3.1", "3.2"}, items)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&fetched))
+	})
+
+	t.Run("repeated", func(t *testing.T) {
+		pages := Pages[message](context.Background(), c, "/posts?page=1")
+
+		for i := 0; i < 2; i++ {
+			n := 0
+
+			for _, err := range pages {
+				assert.Nil(t, err)
+
+				n++
+			}
+
+			assert.Equal(t, 6, n)
+		}
+	})
+
+	t.Run("relative link", func(t *testing.T) {
+		c, err := New(ts.URL + "/v2/")
+		assert.Nil(t, err)
+
+		items := []string{}
+
+		for m, err := range Pages[message](context.Background(), c, "relative?page=2") {
+			assert.Nil(t, err)
+
+			items = append(items, m.Text)
+		}
+
+		assert.Equal(t, []string{"2.1", "2.2", "3.1", "3.2"}, items)
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		atomic.StoreInt32(&fetched, 0)
+
+		for m := range Pages[*message](context.Background(), c, "/posts?page=1") {
+			assert.Equal(t, "1.1", m.Text)
+			break
+		}
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		items, errs := 0, 0
+
+		for _, err := range Pages[message](context.Background(), c, "/error?page=1") {
+			if err != nil {
+				errs++
+				continue
+			}
+
+			items++
+		}
+
+		assert.Equal(t, 2, items)
+		assert.Equal(t, 1, errs)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		atomic.StoreInt32(&fetched, 0)
+
+		var last error
+
+		for _, err := range Pages[message](ctx, c, "/posts?page=1") {
+			cancel()
+
+			last = err
+		}
+
+		assert.Equal(t, context.Canceled, last)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+	})
+
+	t.Run("token", func(t *testing.T) {
+		type page struct {
+			Items []int  `json:"items"`
+			Next  string `json:"next"`
+		}
+
+		items := []int{}
+
+		next := func(p page, _ *http.Response) ([]int, string) {
+			if p.Next == "" {
+				return p.Items, ""
+			}
+
+			return p.Items, "/token?page=" + p.Next
+		}
+
+		for i, err := range PagesFunc(context.Background(), c, "/token?page=0", next) {
+			assert.Nil(t, err)
+
+			items = append(items, i)
+		}
+
+		assert.Equal(t, []int{0, 1, 2}, items)
+	})
+}
@@ -0,0 +1,149 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// NewPatternRequest creates a request for a pattern in the syntax of the patterns of
+// http.ServeMux, e.g. "GET /posts/{id}", so servers and clients can share the route strings.
+// The method is optional (default GET), host patterns are not supported. The path is resolved
+// relative to the BaseURL like the URLs of NewRequest, e.g. "GET /posts" with the BaseURL
+// https://hostname.domain/v2/ is https://hostname.domain/v2/posts. The wildcards are
+// replaced by the path escaped values of params, which is a map with string keys or a struct
+// (pointer), whose fields are matched by their tag path (e.g. `path:"id"`) or case-insensitive
+// by their name. The segments of a {name...} wildcard are escaped separately and {$} is removed.
+// body and opts are handled like by NewRequestWithContext.
+func (c *Client) NewPatternRequest(ctx context.Context, pattern string, params, body interface{}, opts ...RequestOpt) (*http.Request, error) {
+	method, p, err := expandPattern(pattern, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.NewRequestWithContext(ctx, method, p, body, opts...)
+}
+
+// expandPattern returns the method and the path of pattern with the wildcards replaced by the
+// values of params.
+func expandPattern(pattern string, params interface{}) (string, string, error) {
+	method, p := http.MethodGet, strings.TrimSpace(pattern)
+
+	if i := strings.IndexAny(p, " \t"); i >= 0 {
+		method, p = p[:i], strings.TrimSpace(p[i+1:])
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		return "", "", fmt.Errorf("pattern %q: path must start with /", pattern)
+	}
+
+	values, err := pathValues(params)
+	if err != nil {
+		return "", "", fmt.Errorf("pattern %q: %w", pattern, err)
+	}
+
+	b := new(strings.Builder)
+
+	for {
+		start := strings.IndexByte(p, '{')
+		if start < 0 {
+			b.WriteString(p)
+			break
+		}
+
+		end := strings.IndexByte(p[start:], '}')
+		if end < 0 {
+			return "", "", fmt.Errorf("pattern %q: missing }", pattern)
+		}
+
+		b.WriteString(p[:start])
+		name := p[start+1 : start+end]
+		p = p[start+end+1:]
+
+		if name == "$" {
+			continue
+		}
+
+		rest := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+
+		v, ok := values(name)
+		if !ok {
+			return "", "", fmt.Errorf("pattern %q: missing value for %s", pattern, name)
+		}
+
+		if !rest {
+			b.WriteString(url.PathEscape(v))
+			continue
+		}
+
+		segments := strings.Split(v, "/")
+		for i, s := range segments {
+			segments[i] = url.PathEscape(s)
+		}
+
+		b.WriteString(strings.Join(segments, "/"))
+	}
+
+	return method, relativePath(b.String()), nil
+}
+
+// relativePath returns the path p without its leading slash, so it is resolved relative to the
+// BaseURL. A first segment with a colon is prefixed with ./ to not be parsed as scheme.
+func relativePath(p string) string {
+	p = strings.TrimPrefix(p, "/")
+
+	if first := strings.SplitN(p, "/", 2)[0]; strings.Contains(first, ":") {
+		p = "./" + p
+	}
+
+	return p
+}
+
+// pathValues returns a function returning the value of a wildcard from params.
+func pathValues(params interface{}) (func(string) (string, bool), error) {
+	v := reflect.ValueOf(params)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Ptr:
+		return func(string) (string, bool) { return "", false }, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("params must have string keys, not %s", v.Type().Key())
+		}
+
+		return func(name string) (string, bool) {
+			x := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !x.IsValid() {
+				return "", false
+			}
+
+			return fmt.Sprint(x.Interface()), true
+		}, nil
+	case reflect.Struct:
+		return func(name string) (string, bool) {
+			t := v.Type()
+
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				if f.PkgPath != "" {
+					continue // unexported
+				}
+
+				if tag, ok := f.Tag.Lookup("path"); ok && tag == name || !ok && strings.EqualFold(f.Name, name) {
+					return fmt.Sprint(v.Field(i).Interface()), true
+				}
+			}
+
+			return "", false
+		}, nil
+	default:
+		return nil, fmt.Errorf("params must be a map or a struct, not %s", v.Type())
+	}
+}
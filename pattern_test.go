@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPatternRequest(t *testing.T) {
+	type params struct {
+		ID    int
+		Owner string `path:"user"`
+		File  string
+	}
+
+	tt := []struct {
+		name    string
+		pattern string
+		params  interface{}
+		method  string
+		url     string
+	}{
+		{"struct", "GET /users/{user}/posts/{id}", params{ID: 1, Owner: "a b"}, "GET", "https://hostname.domain/users/a%20b/posts/1"},
+		{"struct pointer", "DELETE /posts/{id}", &params{ID: 2}, "DELETE", "https://hostname.domain/posts/2"},
+		{"map", "PUT /posts/{id}", map[string]string{"id": "a/b"}, "PUT", "https://hostname.domain/posts/a%2Fb"},
+		{"map interface", "/posts/{id}", map[string]interface{}{"id": 3}, "GET", "https://hostname.domain/posts/3"},
+		{"rest", "GET /files/{file...}", params{File: "dir/a b.txt"}, "GET", "https://hostname.domain/files/dir/a%20b.txt"},
+		{"end", "GET /posts/{$}", nil, "GET", "https://hostname.domain/posts/"},
+	}
+
+	c, err := New(baseurl)
+	assert.Nil(t, err)
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := c.NewPatternRequest(context.Background(), tc.pattern, tc.params, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.method, req.Method)
+			assert.Equal(t, tc.url, req.URL.String())
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			pattern string
+			params  interface{}
+			err     string
+		}{
+			{"GET /posts/{id}", nil, `pattern "GET /posts/{id}": missing value for id`},
+			{"GET example.com/posts", nil, `pattern "GET example.com/posts": path must start with /`},
+			{"GET /posts/{id", map[string]string{"id": "1"}, `pattern "GET /posts/{id": missing }`},
+			{"GET /posts/{id}", map[int]string{1: "1"}, `pattern "GET /posts/{id}": params must have string keys, not int`},
+			{"GET /posts/{id}", 1, `pattern "GET /posts/{id}": params must be a map or a struct, not int`},
+		} {
+			_, err := c.NewPatternRequest(context.Background(), tc.pattern, tc.params, nil)
+			assert.EqualError(t, err, tc.err)
+		}
+	})
+
+	t.Run("base path", func(t *testing.T) {
+		c, err := New(baseurl + "/v2/")
+		assert.Nil(t, err)
+
+		for _, tc := range []struct {
+			pattern string
+			params  interface{}
+			url     string
+		}{
+			{"GET /posts/{id}", map[string]int{"id": 1}, "https://hostname.domain/v2/posts/1"},
+			{"GET /{$}", nil, "https://hostname.domain/v2/"},
+			{"GET /{id}/comments", map[string]string{"id": "a:b"}, "https://hostname.domain/v2/a:b/comments"},
+		} {
+			req, err := c.NewPatternRequest(context.Background(), tc.pattern, tc.params, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.url, req.URL.String())
+		}
+	})
+
+	t.Run("server route", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/{user}/posts/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+		req, err := c.NewPatternRequest(context.Background(), "GET /users/{user}/posts/{id}", params{ID: 1, Owner: "a b"}, nil)
+		assert.Nil(t, err)
+
+		_, pattern := mux.Handler(req)
+		assert.Equal(t, "GET /users/{user}/posts/{id}", pattern)
+	})
+}
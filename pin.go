@@ -0,0 +1,149 @@
+package httpclient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrPinMismatch is returned for connections to servers whose certificate chain contains no
+// pinned public key (see WithPinnedCertificates).
+var ErrPinMismatch = errors.New("certificate chain does not match the pinned keys")
+
+// PinFailure is passed to the handler of WithPinFailureHandler if the certificate chain of a
+// server does not match the pinned keys.
+type PinFailure struct {
+	Host   string
+	Chain  []*x509.Certificate
+	Hashes []string // SPKI hashes of the chain
+}
+
+// SPKIHash returns the pin of the certificate cert: the base64 encoded SHA-256 hash of its
+// SubjectPublicKeyInfo, e.g. for
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WithPinnedCertificates is a client option for pinning the public keys of the server
+// certificates: in addition to the normal verification, the certificate chain of the server
+// has to contain a certificate with one of the SPKI hashes spkiHashes (see SPKIHash, the
+// prefix sha256/ is allowed), otherwise the request fails with ErrPinMismatch. Pinning the
+// keys of the issuing CAs and a backup key avoids outages when certificates are renewed.
+// Certificates that are not verified (e.g. with InsecureSkipVerify) never match the pins.
+//
+// The option modifies a clone of the transport of the HTTP client, which has to be an
+// *http.Transport, so it has to be applied after WithHTTPClient. It can be combined with
+// WithSecurityPolicy in any order.
+func WithPinnedCertificates(spkiHashes ...string) Opt {
+	return func(c *Client) error {
+		if len(spkiHashes) == 0 {
+			return errors.New("no pinned certificates")
+		}
+
+		p := c.pins.clone()
+		p.hashes = map[string]bool{}
+
+		for _, h := range spkiHashes {
+			h = strings.TrimPrefix(h, "sha256/")
+
+			if b, err := base64.StdEncoding.DecodeString(h); err != nil || len(b) != sha256.Size {
+				return fmt.Errorf("invalid SPKI hash %q", h)
+			}
+
+			p.hashes[h] = true
+		}
+
+		c.pins = p
+
+		return p.install(c)
+	}
+}
+
+// WithPinFailureHandler is a client option for reporting the pin failures of
+// WithPinnedCertificates, e.g. to a security monitoring. The handler f must not block.
+func WithPinFailureHandler(f func(PinFailure)) Opt {
+	return func(c *Client) error {
+		p := c.pins.clone()
+		p.report = f
+		c.pins = p
+
+		if p.hashes == nil {
+			return nil // installed by WithPinnedCertificates
+		}
+
+		return p.install(c)
+	}
+}
+
+// pinning are the pinned SPKI hashes and the handler of pin failures.
+type pinning struct {
+	hashes map[string]bool
+	report func(PinFailure)
+
+	// transport of the client without the pins, updated by the options modifying the
+	// transport after the pins were installed (see updateTransport)
+	unpinned *http.Transport
+}
+
+// clone returns a copy of the pinning p, which may be nil. The pinning of a client is not
+// modified, it is shared by its copies (see Clone) and used by its transport.
+func (p *pinning) clone() *pinning {
+	if p == nil {
+		return &pinning{}
+	}
+
+	cp := *p
+
+	return &cp
+}
+
+// install sets the verification of the pins on a clone of the transport of the client c.
+// The pins installed before (e.g. on the client c was cloned from) are replaced.
+func (p *pinning) install(c *Client) error {
+	if p.unpinned == nil {
+		t, err := c.cloneTransport()
+		if err != nil {
+			return fmt.Errorf("certificate pinning: %w", err)
+		}
+
+		p.unpinned = t
+	}
+
+	t := p.unpinned.Clone()
+	verifyConnection(t.TLSClientConfig, p.verify)
+	c.setTransport(t)
+
+	return nil
+}
+
+// verify checks whether a verified certificate chain of the connection cs contains a pinned
+// key, the unverified peer certificates are not trusted.
+func (p *pinning) verify(cs tls.ConnectionState) error {
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			if p.hashes[SPKIHash(cert)] {
+				return nil
+			}
+		}
+	}
+
+	if p.report != nil {
+		f := PinFailure{Host: cs.ServerName, Chain: cs.PeerCertificates}
+		for _, cert := range cs.PeerCertificates {
+			f.Hashes = append(f.Hashes, SPKIHash(cert))
+		}
+
+		p.report(f)
+	}
+
+	return fmt.Errorf("%s: %w", cs.ServerName, ErrPinMismatch)
+}
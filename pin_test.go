@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinnedCertificates(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	pin := SPKIHash(ts.Certificate())
+	other := sha256.Sum256([]byte("other"))
+	otherPin := base64.StdEncoding.EncodeToString(other[:])
+
+	get := func(c *Client) error {
+		req, err := c.NewRequest(http.MethodGet, "/", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+
+		return err
+	}
+
+	t.Run("pinned", func(t *testing.T) {
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()), WithPinnedCertificates(otherPin, "sha256/"+pin))
+		assert.Nil(t, err)
+		assert.Nil(t, get(c))
+		assert.Nil(t, ts.Client().Transport.(*http.Transport).TLSClientConfig.VerifyConnection)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		var failures []PinFailure
+
+		c, err := New(ts.URL,
+			WithPinFailureHandler(func(f PinFailure) { failures = append(failures, f) }),
+			WithHTTPClient(ts.Client()),
+			WithPinnedCertificates(otherPin),
+		)
+		assert.Nil(t, err)
+
+		err = get(c)
+		assert.True(t, errors.Is(err, ErrPinMismatch))
+		assert.Len(t, failures, 1)
+		assert.Equal(t, []string{pin}, failures[0].Hashes)
+	})
+
+	t.Run("security policy", func(t *testing.T) {
+		var failures []PinFailure
+
+		c, err := New(ts.URL,
+			WithHTTPClient(ts.Client()),
+			WithPinnedCertificates(otherPin),
+			WithSecurityPolicy(SecurityPolicy{MinTLSVersion: tls.VersionTLS13}),
+			WithPinFailureHandler(func(f PinFailure) { failures = append(failures, f) }),
+		)
+		assert.Nil(t, err)
+
+		cfg := c.client.Transport.(*http.Transport).TLSClientConfig
+		assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+
+		err = get(c)
+		assert.True(t, errors.Is(err, ErrPinMismatch))
+		assert.Len(t, failures, 1)
+
+		// the policy is kept if the pins are installed again
+		c, err = New(ts.URL,
+			WithHTTPClient(ts.Client()),
+			WithPinnedCertificates(pin),
+			WithSecurityPolicy(SecurityPolicy{CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}}),
+			WithPinnedCertificates(pin),
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, c.client.Transport.(*http.Transport).TLSClientConfig.CipherSuites)
+		assert.Nil(t, get(c))
+	})
+
+	t.Run("normal verification", func(t *testing.T) {
+		c, err := New(ts.URL, WithPinnedCertificates(pin))
+		assert.Nil(t, err)
+
+		err = get(c)
+		assert.NotNil(t, err)
+		assert.False(t, errors.Is(err, ErrPinMismatch))
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()), WithPinnedCertificates(pin))
+		assert.Nil(t, err)
+
+		var failures []PinFailure
+
+		clone := c.Clone()
+		assert.Nil(t, WithPinnedCertificates(otherPin)(clone))
+		assert.Nil(t, WithPinFailureHandler(func(f PinFailure) { failures = append(failures, f) })(clone))
+
+		assert.True(t, errors.Is(get(clone), ErrPinMismatch))
+		assert.Len(t, failures, 1)
+		assert.Nil(t, get(c))
+		assert.Len(t, failures, 1)
+	})
+
+	t.Run("not verified", func(t *testing.T) {
+		tr := ts.Client().Transport.(*http.Transport).Clone()
+		tr.TLSClientConfig.InsecureSkipVerify = true
+
+		c, err := New(ts.URL, WithHTTPClient(&http.Client{Transport: tr}), WithPinnedCertificates(pin))
+		assert.Nil(t, err)
+
+		err = get(c)
+		assert.True(t, errors.Is(err, ErrPinMismatch))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := New(ts.URL, WithPinnedCertificates())
+		assert.EqualError(t, err, "no pinned certificates")
+
+		_, err = New(ts.URL, WithPinnedCertificates("abc"))
+		assert.EqualError(t, err, `invalid SPKI hash "abc"`)
+
+		_, err = New(ts.URL, WithHTTPClient(&http.Client{Transport: roundTripper(nil)}), WithPinnedCertificates(pin))
+		assert.EqualError(t, err, "certificate pinning: *http.Transport required, not httpclient.roundTripper")
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
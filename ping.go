@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Availability is the result of Ping.
+type Availability struct {
+	// StatusCode is the status code of the response, 0 if the request failed.
+	StatusCode int
+
+	// Latency is the time until the response headers were received.
+	Latency time.Duration
+
+	// TLSExpiry is the expiry of the server certificate, zero without TLS.
+	TLSExpiry time.Time
+}
+
+// Ping sends a cheap request to path (see NewRequest), e.g. for the readiness probe of a service
+// depending on the API. It sends a HEAD request and falls back to GET if the server does not
+// allow HEAD, the response body is discarded. It returns an error (HTTPError) if the status code
+// is outside the 200 range. The rewrite rules and the security policy of the client are applied,
+// the rate limiter and the ResponseCallback are not used.
+func (c *Client) Ping(ctx context.Context, path string) (*Availability, error) {
+	a, err := c.ping(ctx, http.MethodHead, path)
+	if a != nil && (a.StatusCode == http.StatusMethodNotAllowed || a.StatusCode == http.StatusNotImplemented) {
+		a, err = c.ping(ctx, http.MethodGet, path)
+	}
+
+	return a, err
+}
+
+// ping sends a request with method to path.
+func (c *Client) ping(ctx context.Context, method, path string) (*Availability, error) {
+	req, err := c.newRequest(ctx, method, path, http.NoBody, c.ContentType, c.ContentType, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if req, err = c.prepare(req); err != nil {
+		return nil, err
+	}
+
+	start := c.Now()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &Availability{Latency: c.Now().Sub(start)}, redactError(err)
+	}
+
+	a := &Availability{
+		StatusCode: resp.StatusCode,
+		Latency:    c.Now().Sub(start),
+	}
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		a.TLSExpiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+
+	// the connection is only reused if the body is read to the end and closed
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if _, err := responseCallback(resp); err != nil {
+		return a, err
+	}
+
+	return a, nil
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPing(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/down", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	t.Run("available", func(t *testing.T) {
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		c, err := New(ts.URL)
+		assert.Nil(t, err)
+
+		a, err := c.Ping(context.Background(), "/health")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, a.StatusCode)
+		assert.True(t, a.Latency > 0)
+		assert.True(t, a.TLSExpiry.IsZero())
+	})
+
+	t.Run("GET fallback", func(t *testing.T) {
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		c, err := New(ts.URL)
+		assert.Nil(t, err)
+
+		a, err := c.Ping(context.Background(), "/get")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, a.StatusCode)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		c, err := New(ts.URL)
+		assert.Nil(t, err)
+
+		a, err := c.Ping(context.Background(), "/down")
+
+		var httpErr *HTTPError
+
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.StatusServiceUnavailable, a.StatusCode)
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		ts := httptest.NewTLSServer(mux)
+		defer ts.Close()
+
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()))
+		assert.Nil(t, err)
+
+		a, err := c.Ping(context.Background(), "/health")
+		assert.Nil(t, err)
+		assert.Equal(t, ts.Certificate().NotAfter, a.TLSExpiry)
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		ts := httptest.NewServer(mux)
+		ts.Close()
+
+		c, err := New(ts.URL)
+		assert.Nil(t, err)
+
+		a, err := c.Ping(context.Background(), "/health")
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, a.StatusCode)
+	})
+}
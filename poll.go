@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Poll sends the request req with the client c repeatedly until until reports true for the
+// response and its body decoded into a value of type T (see DoTyped), e.g. for change feeds
+// with long polling. It waits interval (0 for long polling) between the requests. Failed
+// requests are retried with the backoff of the RetryPolicy of the client if they are
+// retryable (see RetryPolicy), other errors are returned. Poll returns the error of ctx if it
+// is done before. The body of req is reset with GetBody for every request.
+func Poll[T any](ctx context.Context, c *Client, req *http.Request, interval time.Duration, until func(*http.Response, T) bool) (T, *http.Response, error) {
+	p := DefaultRetryPolicy
+	if c.RetryPolicy != nil {
+		p = *c.RetryPolicy
+	}
+
+	retryable := p.retryable()
+	backoff := p.Backoff
+	ctx = context.WithValue(ctx, retryingKey{}, true)
+
+	for {
+		r := req.Clone(ctx)
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				var zero T
+				return zero, nil, err
+			}
+
+			r.Body = body
+		}
+
+		v, resp, err := DoTyped[T](ctx, c, r)
+
+		wait := interval
+
+		switch {
+		case err == nil:
+			if until(resp, v) {
+				return v, resp, nil
+			}
+
+			backoff = p.Backoff
+		case ctx.Err() != nil || !retryable(resp, err):
+			return v, resp, err
+		default:
+			wait = jitter(backoff, p.Jitter)
+
+			if backoff *= 2; p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+				backoff = p.MaxBackoff
+			}
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			var zero T
+			return zero, resp, err
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
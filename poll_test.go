@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoll(t *testing.T) {
+	var calls int64
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&calls, 1)
+
+		switch {
+		case r.URL.Path == "/notfound":
+			w.WriteHeader(http.StatusNotFound)
+		case n == 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprintf(w, `{"Text":"%d"}`, n)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 1, Backoff: time.Millisecond}))
+	assert.Nil(t, err)
+
+	t.Run("until", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/feed", nil)
+		assert.Nil(t, err)
+
+		m, resp, err := Poll(context.Background(), c, req, time.Millisecond, func(_ *http.Response, m message) bool {
+			return m.Text == "4"
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "4", m.Text)
+		assert.Equal(t, int64(4), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/notfound", nil)
+		assert.Nil(t, err)
+
+		_, resp, err := Poll(context.Background(), c, req, 0, func(*http.Response, message) bool { return false })
+
+		var httpErr *HTTPError
+
+		assert.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		req, err := c.NewRequest(http.MethodPost, "/feed", message{Text: "since"})
+		assert.Nil(t, err)
+
+		_, _, err = Poll(ctx, c, req, time.Millisecond, func(*http.Response, message) bool { return false })
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
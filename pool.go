@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// TenantFunc returns the client options of the tenant with the ID tenantID, e.g. its
+// credentials (WithBasicAuth), headers (WithHeader) or base path (WithBasePath).
+type TenantFunc func(tenantID string) ([]Opt, error)
+
+// Pool manages the clients of tenants (see Pool.For). The clients are copies of a base client
+// (see Clone) with the options of the tenant applied, they share the HTTP client (transport and
+// connections), the rate limiter, the cache and the statistics of the base client. Responses are
+// cached by URL and request headers, so the tenants only share cached responses if they send
+// the same headers (including the authentication).
+type Pool struct {
+	base    *Client
+	tenant  TenantFunc
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewPool returns a pool of the tenant clients of the base client c with the options of the
+// tenants returned by tenant.
+func NewPool(c *Client, tenant TenantFunc) (*Pool, error) {
+	if c == nil {
+		return nil, errors.New("base client cannot be nil")
+	}
+
+	if tenant == nil {
+		return nil, errors.New("tenant func cannot be nil")
+	}
+
+	return &Pool{base: c, tenant: tenant, clients: map[string]*Client{}}, nil
+}
+
+// For returns the client of the tenant with the ID tenantID, which is created on the first call
+// and reused afterwards.
+func (p *Pool) For(tenantID string) (*Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.clients[tenantID]; ok {
+		return c, nil
+	}
+
+	opts, err := p.tenant(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+	}
+
+	c := p.base.Clone()
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+
+	c.initHeader()
+	p.clients[tenantID] = c
+
+	return c, nil
+}
+
+// Remove removes the client of the tenant with the ID tenantID, e.g. after its credentials
+// changed, the next call of For creates a new client.
+func (p *Pool) Remove(tenantID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.clients, tenantID)
+}
+
+// Len returns the number of tenant clients.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.clients)
+}
@@ -0,0 +1,111 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	var conns int32
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, _, _ := r.BasicAuth()
+		_, _ = w.Write([]byte(`{"Text":"` + u + " " + r.URL.Path + `"}`))
+	}))
+	ts.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	p, err := NewPool(c, func(tenantID string) ([]Opt, error) {
+		if tenantID == "unknown" {
+			return nil, errors.New("unknown tenant")
+		}
+
+		return []Opt{WithBasicAuth(tenantID, "secret"), WithBasePath(tenantID)}, nil
+	})
+	assert.Nil(t, err)
+
+	t.Run("for", func(t *testing.T) {
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				tenant := []string{"a", "b"}[i%2]
+
+				tc, err := p.For(tenant)
+				assert.Nil(t, err)
+
+				req, err := tc.NewRequest(http.MethodGet, "/posts", nil)
+				assert.Nil(t, err)
+
+				m := message{}
+				_, err = tc.Do(context.Background(), req, &m)
+				assert.Nil(t, err)
+				assert.Equal(t, tenant+" /"+tenant+"/posts", m.Text)
+			}(i)
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, 2, p.Len())
+		assert.Equal(t, int64(10), c.Stats().Requests)
+
+		// new tenants reuse the idle connections
+		before := atomic.LoadInt32(&conns)
+
+		for _, tenant := range []string{"c", "d", "e"} {
+			tc, err := p.For(tenant)
+			assert.Nil(t, err)
+
+			req, err := tc.NewRequest(http.MethodGet, "/posts", nil)
+			assert.Nil(t, err)
+
+			_, err = tc.Do(context.Background(), req, nil)
+			assert.Nil(t, err)
+		}
+
+		assert.Equal(t, before, atomic.LoadInt32(&conns))
+		assert.Equal(t, 5, p.Len())
+
+		a1, _ := p.For("a")
+		a2, _ := p.For("a")
+		assert.True(t, a1 == a2)
+
+		p.Remove("a")
+		assert.Equal(t, 4, p.Len())
+
+		a3, _ := p.For("a")
+		assert.False(t, a1 == a3)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := p.For("unknown")
+		assert.EqualError(t, err, "tenant unknown: unknown tenant")
+
+		_, err = NewPool(nil, func(string) ([]Opt, error) { return nil, nil })
+		assert.NotNil(t, err)
+
+		_, err = NewPool(c, nil)
+		assert.NotNil(t, err)
+	})
+}
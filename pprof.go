@@ -0,0 +1,32 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+)
+
+// PprofLabels returns an Instrumentation setting the pprof labels service and method (e.g.
+// NodeService and Get) for the calls of the service methods of a generated client, so CPU
+// profiles of the consumers attribute the time (including Do) to the API calls. It calls next,
+// if it is not nil:
+//
+//	c, err := httpclient.New(baseURL, httpclient.WithInstrumentation(httpclient.PprofLabels(tracing)))
+func PprofLabels(next Instrumentation) Instrumentation {
+	return InstrumentationFunc(func(ctx context.Context, service, method string) (context.Context, func(*http.Response, error)) {
+		parent := ctx
+
+		ctx = pprof.WithLabels(ctx, pprof.Labels("service", service, "method", method))
+		pprof.SetGoroutineLabels(ctx)
+
+		end := func(*http.Response, error) {}
+		if next != nil {
+			ctx, end = next.Start(ctx, service, method)
+		}
+
+		return ctx, func(resp *http.Response, err error) {
+			end(resp, err)
+			pprof.SetGoroutineLabels(parent)
+		}
+	})
+}
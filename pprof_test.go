@@ -0,0 +1,42 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPprofLabels(t *testing.T) {
+	t.Run("labels", func(t *testing.T) {
+		ctx, end := PprofLabels(nil).Start(context.Background(), "NodeService", "Get")
+
+		service, _ := pprof.Label(ctx, "service")
+		method, _ := pprof.Label(ctx, "method")
+
+		assert.Equal(t, "NodeService", service)
+		assert.Equal(t, "Get", method)
+
+		end(nil, nil)
+	})
+
+	t.Run("next", func(t *testing.T) {
+		calls := []string{}
+
+		next := InstrumentationFunc(func(ctx context.Context, service, method string) (context.Context, func(*http.Response, error)) {
+			v, _ := pprof.Label(ctx, "method")
+			calls = append(calls, "start "+v)
+
+			return ctx, func(*http.Response, error) {
+				calls = append(calls, "end")
+			}
+		})
+
+		_, end := PprofLabels(next).Start(context.Background(), "NodeService", "List")
+		end(nil, nil)
+
+		assert.Equal(t, []string{"start List", "end"}, calls)
+	})
+}
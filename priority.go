@@ -0,0 +1,207 @@
+package httpclient
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrShed is returned for requests dropped by the priority queue of the client, because the
+// queue is full of requests with higher priority (see WithPriorityQueue).
+var ErrShed = errors.New("request shed by priority queue")
+
+// Priority is the priority of a request for the priority queue of the client (see
+// WithPriorityQueue), higher values are sent first.
+type Priority int
+
+// Priorities of requests.
+const (
+	PriorityLow    Priority = -10
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 10
+)
+
+// priorityKey is the context key of the priority.
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx with the priority p for the requests sent with it.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// RequestPriority is a request option setting the priority p of the request (see WithPriority).
+func RequestPriority(p Priority) RequestOpt {
+	return func(r *http.Request) error {
+		*r = *r.WithContext(WithPriority(r.Context(), p))
+		return nil
+	}
+}
+
+// priority returns the priority of ctx or, if it has none, of the request req.
+func priority(ctx context.Context, req *http.Request) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+
+	if p, ok := req.Context().Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+
+	return PriorityNormal
+}
+
+// WithPriorityQueue is a client option for limiting the number of requests in flight (sent with
+// Do) to maxInflight. Further requests wait in a queue and are sent by priority (see
+// WithPriority and RequestPriority), e.g. to protect interactive calls from batch jobs sharing
+// the client. The rate limiter of the client is only awaited by requests in flight, so the
+// queue orders the requests waiting for it as well. If more than maxQueued (0 for unlimited)
+// requests wait, the request with the lowest priority is shed with ErrShed.
+func WithPriorityQueue(maxInflight, maxQueued int) Opt {
+	return func(c *Client) error {
+		if maxInflight < 1 {
+			return errors.New("max inflight requests must be at least 1")
+		}
+
+		if maxQueued < 0 {
+			return errors.New("max queued requests cannot be negative")
+		}
+
+		c.queue = &priorityQueue{maxInflight: maxInflight, maxQueued: maxQueued}
+
+		return nil
+	}
+}
+
+// priorityQueue limits the requests in flight and queues the others by priority.
+type priorityQueue struct {
+	mu          sync.Mutex
+	maxInflight int
+	maxQueued   int
+	inflight    int
+	seq         uint64
+	waiting     waiters
+}
+
+// waiter is a queued request.
+type waiter struct {
+	priority Priority
+	seq      uint64
+	index    int
+	ready    chan error
+}
+
+// acquire waits until the request with priority p can be sent, it must be released if no error
+// is returned.
+func (q *priorityQueue) acquire(ctx context.Context, p Priority) error {
+	q.mu.Lock()
+
+	if q.inflight < q.maxInflight && len(q.waiting) == 0 {
+		q.inflight++
+		q.mu.Unlock()
+
+		return nil
+	}
+
+	if q.maxQueued > 0 && len(q.waiting) >= q.maxQueued {
+		lowest := q.waiting.lowest()
+		if lowest.priority >= p {
+			q.mu.Unlock()
+			return ErrShed
+		}
+
+		heap.Remove(&q.waiting, lowest.index)
+		lowest.ready <- ErrShed
+	}
+
+	q.seq++
+	w := &waiter{priority: p, seq: q.seq, ready: make(chan error, 1)}
+	heap.Push(&q.waiting, w)
+	q.mu.Unlock()
+
+	select {
+	case err := <-w.ready:
+		return err
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+
+	if w.index >= 0 {
+		heap.Remove(&q.waiting, w.index)
+		q.mu.Unlock()
+
+		return ctx.Err()
+	}
+
+	q.mu.Unlock()
+
+	// the request was dequeued concurrently
+	if err := <-w.ready; err == nil {
+		q.release()
+	}
+
+	return ctx.Err()
+}
+
+// release passes the slot of a request in flight to the queued request with the highest
+// priority.
+func (q *priorityQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiting) == 0 {
+		q.inflight--
+		return
+	}
+
+	w := heap.Pop(&q.waiting).(*waiter)
+	w.ready <- nil
+}
+
+// waiters is a heap of waiters, ordered by priority and arrival.
+type waiters []*waiter
+
+func (w waiters) Len() int { return len(w) }
+
+func (w waiters) Less(i, j int) bool {
+	if w[i].priority != w[j].priority {
+		return w[i].priority > w[j].priority
+	}
+
+	return w[i].seq < w[j].seq
+}
+
+func (w waiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index = i
+	w[j].index = j
+}
+
+func (w *waiters) Push(x interface{}) {
+	x.(*waiter).index = len(*w)
+	*w = append(*w, x.(*waiter))
+}
+
+func (w *waiters) Pop() interface{} {
+	old := *w
+	x := old[len(old)-1]
+	x.index = -1
+	*w = old[:len(old)-1]
+
+	return x
+}
+
+// lowest returns the waiter with the lowest priority, which arrived last.
+func (w waiters) lowest() *waiter {
+	lowest := w[0]
+
+	for _, x := range w[1:] {
+		if x.priority < lowest.priority || (x.priority == lowest.priority && x.seq > lowest.seq) {
+			lowest = x
+		}
+	}
+
+	return lowest
+}
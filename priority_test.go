@@ -0,0 +1,143 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPriorityQueue(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	block := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			<-block
+		}
+
+		mu.Lock()
+		order = append(order, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	// wait waits until the queue of c has inflight requests in flight and queued requests
+	wait := func(c *Client, inflight, queued int) {
+		for {
+			c.queue.mu.Lock()
+			i, q := c.queue.inflight, len(c.queue.waiting)
+			c.queue.mu.Unlock()
+
+			if i == inflight && q == queued {
+				return
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	send := func(c *Client, ctx context.Context, path string, opts ...RequestOpt) <-chan error {
+		done := make(chan error, 1)
+
+		go func() {
+			req, err := c.NewRequestWithContext(ctx, http.MethodGet, path, nil, opts...)
+			assert.Nil(t, err)
+
+			_, err = c.Do(ctx, req, nil)
+			done <- err
+		}()
+
+		return done
+	}
+
+	t.Run("order", func(t *testing.T) {
+		order = nil
+
+		c, err := New(ts.URL, WithPriorityQueue(1, 0))
+		assert.Nil(t, err)
+
+		ctx := context.Background()
+		blocked := send(c, ctx, "/block")
+
+		wait(c, 1, 0)
+
+		low := send(c, WithPriority(ctx, PriorityLow), "/low")
+		wait(c, 1, 1)
+		normal := send(c, ctx, "/normal")
+		wait(c, 1, 2)
+		high := send(c, ctx, "/high", RequestPriority(PriorityHigh))
+		wait(c, 1, 3)
+
+		block <- struct{}{}
+
+		for _, done := range []<-chan error{blocked, low, normal, high} {
+			assert.Nil(t, <-done)
+		}
+
+		assert.Equal(t, []string{"/block", "/high", "/normal", "/low"}, order)
+	})
+
+	t.Run("shed", func(t *testing.T) {
+		c, err := New(ts.URL, WithPriorityQueue(1, 1))
+		assert.Nil(t, err)
+
+		ctx := context.Background()
+		blocked := send(c, ctx, "/block")
+
+		wait(c, 1, 0)
+
+		low := send(c, WithPriority(ctx, PriorityLow), "/low")
+		wait(c, 1, 1)
+
+		high := send(c, WithPriority(ctx, PriorityHigh), "/high")
+		assert.True(t, errors.Is(<-low, ErrShed))
+
+		other := send(c, WithPriority(ctx, PriorityLow), "/low")
+		assert.True(t, errors.Is(<-other, ErrShed))
+
+		block <- struct{}{}
+
+		assert.Nil(t, <-blocked)
+		assert.Nil(t, <-high)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		c, err := New(ts.URL, WithPriorityQueue(1, 0))
+		assert.Nil(t, err)
+
+		blocked := send(c, context.Background(), "/block")
+
+		wait(c, 1, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		canceled := send(c, ctx, "/canceled")
+		wait(c, 1, 1)
+		cancel()
+
+		assert.True(t, errors.Is(<-canceled, context.Canceled))
+		wait(c, 1, 0)
+
+		block <- struct{}{}
+
+		assert.Nil(t, <-blocked)
+		wait(c, 0, 0)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := New(ts.URL, WithPriorityQueue(0, 0))
+		assert.NotNil(t, err)
+
+		_, err = New(ts.URL, WithPriorityQueue(1, -1))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo is the quota of a client announced by the server in the rate limit headers of
+// a response (see ParseRateLimit).
+type RateLimitInfo struct {
+	// Limit is the number of requests of the quota and Remaining the number of requests left,
+	// -1 if unknown.
+	Limit     int
+	Remaining int
+
+	// Reset is the time the quota is reset, zero if unknown.
+	Reset time.Time
+}
+
+// ParseRateLimit parses the rate limit headers h and reports whether there are any. It supports
+// the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers, the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers and the RateLimit header of the IETF drafts
+// (e.g. "limit=100, remaining=50, reset=30" or "default";r=50;t=30). Resets are delta seconds
+// relative to now or, for large values of the Reset headers, Unix times.
+func ParseRateLimit(h http.Header, now time.Time) (RateLimitInfo, bool) {
+	info := RateLimitInfo{Limit: -1, Remaining: -1}
+	found := false
+
+	for _, prefix := range []string{"X-Ratelimit-", "Ratelimit-"} {
+		if v, ok := headerInt(h, prefix+"Limit"); ok {
+			info.Limit, found = v, true
+		}
+
+		if v, ok := headerInt(h, prefix+"Remaining"); ok {
+			info.Remaining, found = v, true
+		}
+
+		if v, ok := headerInt(h, prefix+"Reset"); ok {
+			info.Reset, found = resetTime(v, now), true
+		}
+
+		if found {
+			return info, true
+		}
+	}
+
+	v := h.Get("RateLimit")
+	if v == "" {
+		return info, false
+	}
+
+	for _, field := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' }) {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.Trim(kv[1], `"`))
+		if err != nil {
+			continue
+		}
+
+		switch kv[0] {
+		case "limit":
+			info.Limit, found = n, true
+		case "remaining", "r":
+			info.Remaining, found = n, true
+		case "reset", "t":
+			info.Reset, found = now.Add(time.Duration(n)*time.Second), true
+		}
+	}
+
+	return info, found
+}
+
+// headerInt returns the integer value of the header key of h.
+func headerInt(h http.Header, key string) (int, bool) {
+	v := h.Get(key)
+	if v == "" {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// resetTime returns the time of the reset v, a Unix time if it is after 2001 or delta seconds
+// relative to now.
+func resetTime(v int, now time.Time) time.Time {
+	if v > 1e9 {
+		return time.Unix(int64(v), 0)
+	}
+
+	return now.Add(time.Duration(v) * time.Second)
+}
@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name   string
+		header http.Header
+		want   RateLimitInfo
+		found  bool
+	}{
+		{"none", http.Header{}, RateLimitInfo{Limit: -1, Remaining: -1}, false},
+		{"x-ratelimit", http.Header{
+			"X-Ratelimit-Limit":     {"5000"},
+			"X-Ratelimit-Remaining": {"4999"},
+			"X-Ratelimit-Reset":     {"1577934245"},
+		}, RateLimitInfo{5000, 4999, time.Unix(1577934245, 0)}, true},
+		{"ratelimit headers", http.Header{
+			"Ratelimit-Limit":     {"100"},
+			"Ratelimit-Remaining": {"0"},
+			"Ratelimit-Reset":     {"30"},
+		}, RateLimitInfo{100, 0, now.Add(30 * time.Second)}, true},
+		{"ratelimit", http.Header{"Ratelimit": {"limit=100, remaining=50, reset=60"}}, RateLimitInfo{100, 50, now.Add(time.Minute)}, true},
+		{"ratelimit structured", http.Header{"Ratelimit": {`"default";r=50;t=30`}}, RateLimitInfo{-1, 50, now.Add(30 * time.Second)}, true},
+		{"remaining only", http.Header{"X-Ratelimit-Remaining": {"7"}}, RateLimitInfo{Limit: -1, Remaining: 7}, true},
+		{"invalid", http.Header{"X-Ratelimit-Limit": {"many"}}, RateLimitInfo{Limit: -1, Remaining: -1}, false},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			info, found := ParseRateLimit(tc.header, now)
+			assert.Equal(t, tc.found, found)
+			assert.True(t, tc.want.Reset.Equal(info.Reset))
+
+			tc.want.Reset, info.Reset = time.Time{}, time.Time{}
+			assert.Equal(t, tc.want, info)
+		})
+	}
+}
+
+func TestResultRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/limited" {
+			w.Header().Set("X-RateLimit-Remaining", "3")
+		}
+
+		_, _ = w.Write([]byte(`{"Text":"hello"}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	r, err := Get[message](context.Background(), c, "/limited")
+	assert.Nil(t, err)
+	assert.Equal(t, &RateLimitInfo{Limit: -1, Remaining: 3}, r.RateLimit)
+
+	r, err = Get[message](context.Background(), c, "/")
+	assert.Nil(t, err)
+	assert.Nil(t, r.RateLimit)
+}
@@ -0,0 +1,219 @@
+package httpclient
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// redacted replaces the credentials in URLs, headers and dumps.
+const redacted = "xxxxx"
+
+// SensitiveHeaders are the headers whose values are redacted by RedactHeader.
+// nolint: gochecknoglobals
+var SensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// sensitiveParams are the (lower case) names and name parts of query parameters whose values are
+// redacted by RedactURL.
+// nolint: gochecknoglobals
+var sensitiveParams = struct {
+	names []string
+	parts []string
+}{
+	names: []string{"key", "sig", "code", "auth", "pwd", "session", "sessionid"},
+	parts: []string{"token", "secret", "password", "passwd", "apikey", "api_key", "signature", "credential"},
+}
+
+// RedactURL returns the URL s with the credentials replaced: the password of the userinfo (or the
+// username if there is no password, e.g. a token) and the values of sensitive query parameters
+// (e.g. access_token, api_key or signature). Invalid URLs are redacted as far as possible.
+func RedactURL(s string) string {
+	if i := strings.Index(s, "://"); i >= 0 {
+		start := i + 3
+
+		authority := s[start:]
+		if j := strings.IndexAny(authority, "/?#"); j >= 0 {
+			authority = authority[:j]
+		}
+
+		if at := strings.LastIndex(authority, "@"); at >= 0 {
+			userinfo := redacted
+			if k := strings.Index(authority[:at], ":"); k >= 0 {
+				userinfo = authority[:k] + ":" + redacted
+			}
+
+			s = s[:start] + userinfo + s[start+at:]
+		}
+	}
+
+	q := strings.Index(s, "?")
+	if q < 0 {
+		return s
+	}
+
+	end := len(s)
+	if h := strings.Index(s[q:], "#"); h >= 0 {
+		end = q + h
+	}
+
+	params := strings.Split(s[q+1:end], "&")
+
+	for i, p := range params {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		name, err := url.QueryUnescape(kv[0])
+		if err != nil {
+			name = kv[0]
+		}
+
+		if sensitiveParam(name) {
+			params[i] = kv[0] + "=" + redacted
+		}
+	}
+
+	return s[:q+1] + strings.Join(params, "&") + s[end:]
+}
+
+// sensitiveParam reports whether the value of the query parameter name is a credential.
+func sensitiveParam(name string) bool {
+	name = strings.ToLower(name)
+
+	for _, n := range sensitiveParams.names {
+		if name == n {
+			return true
+		}
+	}
+
+	for _, p := range sensitiveParams.parts {
+		if strings.Contains(name, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactHeader returns a copy of the header h with the values of the SensitiveHeaders replaced.
+func RedactHeader(h http.Header) http.Header {
+	h = h.Clone()
+
+	for _, name := range SensitiveHeaders {
+		if values := h.Values(name); len(values) > 0 {
+			for i := range values {
+				values[i] = redacted
+			}
+		}
+	}
+
+	return h
+}
+
+// DumpRequest is like httputil.DumpRequestOut, but with the credentials of the URL and the
+// headers redacted (see RedactURL and RedactHeader), e.g. for debug logs in a RequestCallback.
+// The body is only dumped if it can be read again (see http.Request.GetBody).
+func DumpRequest(r *http.Request, body bool) ([]byte, error) {
+	clone := r.Clone(r.Context())
+	clone.Header = RedactHeader(r.Header)
+
+	if u, err := url.Parse(RedactURL(r.URL.String())); err == nil {
+		u.User = nil
+		clone.URL = u
+	}
+
+	if body && r.GetBody != nil {
+		b, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		clone.Body = b
+	} else {
+		body = false
+	}
+
+	return httputil.DumpRequestOut(clone, body)
+}
+
+// DumpResponse is like httputil.DumpResponse, but with the credentials of the headers redacted
+// (see RedactHeader), e.g. for debug logs in a ResponseCallback.
+func DumpResponse(resp *http.Response, body bool) ([]byte, error) {
+	clone := *resp
+	clone.Header = RedactHeader(resp.Header)
+
+	b, err := httputil.DumpResponse(&clone, body)
+
+	resp.Body = clone.Body // replaced by httputil.DumpResponse to read the body again
+
+	return b, err
+}
+
+// CurlCommand returns the curl command for the request r with the credentials of the URL and
+// the headers redacted (see RedactURL and RedactHeader), e.g. for debug logs in a
+// RequestCallback. The body is only added if it can be read again (see http.Request.GetBody).
+func CurlCommand(r *http.Request) (string, error) {
+	quote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+
+	u := *r.URL
+	u.User = nil
+
+	cmd := []string{"curl", "-X", quote(r.Method), quote(RedactURL(u.String()))}
+
+	h := RedactHeader(r.Header)
+
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, v := range h[name] {
+			cmd = append(cmd, "-H", quote(name+": "+v))
+		}
+	}
+
+	if r.GetBody != nil {
+		b, err := r.GetBody()
+		if err != nil {
+			return "", err
+		}
+
+		defer b.Close()
+
+		data, err := ioutil.ReadAll(b)
+		if err != nil {
+			return "", err
+		}
+
+		if len(data) > 0 {
+			cmd = append(cmd, "-d", quote(string(data)))
+		}
+	}
+
+	return strings.Join(cmd, " "), nil
+}
+
+// redactError returns err with the credentials of the URL redacted, if it is a *url.Error.
+func redactError(err error) error {
+	if e, ok := err.(*url.Error); ok { // nolint: errorlint // only the returned error is redacted
+		return &url.Error{Op: e.Op, URL: RedactURL(e.URL), Err: e.Err}
+	}
+
+	return err
+}
@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-querystring/query"
+)
+
+// RequestOpt is an option of a single request (see NewRequestWithContext).
+type RequestOpt func(*http.Request) error
+
+// Options is the constraint of the query options of Query, it is implemented by structs
+// embedding QueryStruct. Other types (e.g. an int) are rejected at compile time, unlike with
+// QueryOptions.
+type Options interface {
+	queryOptions()
+}
+
+// QueryStruct marks a struct tagged according to https://github.com/google/go-querystring as
+// query options for Query, if it is embedded:
+//
+//	type ListOptions struct {
+//		httpclient.QueryStruct
+//		Page    int `url:"page,omitempty"`
+//		PerPage int `url:"per_page,omitempty"`
+//	}
+type QueryStruct struct{}
+
+func (QueryStruct) queryOptions() {}
+
+// Query is a request option adding the query options v to the URL of the request. Existing
+// query parameters with the same names are replaced.
+func Query[T Options](v T) RequestOpt {
+	return func(r *http.Request) error {
+		values, err := query.Values(v)
+		if err != nil {
+			return err
+		}
+
+		return QueryValues(values)(r)
+	}
+}
+
+// QueryValues is a request option adding the query parameters v to the URL of the request.
+// Existing query parameters with the same names are replaced.
+func QueryValues(v url.Values) RequestOpt {
+	return func(r *http.Request) error {
+		q := r.URL.Query()
+		for k, values := range v {
+			q[k] = values
+		}
+
+		r.URL.RawQuery = q.Encode()
+
+		return nil
+	}
+}
+
+// AddHeader is a request option adding the value to the header key of the request.
+func AddHeader(key, value string) RequestOpt {
+	return func(r *http.Request) error {
+		r.Header.Add(key, value)
+		return nil
+	}
+}
+
+// SetHeader is a request option replacing the values of the header key of the request (e.g.
+// set by the client) with value.
+func SetHeader(key, value string) RequestOpt {
+	return func(r *http.Request) error {
+		r.Header.Set(key, value)
+		return nil
+	}
+}
+
+// RemoveHeader is a request option removing the header key (e.g. Accept) from the request.
+func RemoveHeader(key string) RequestOpt {
+	return func(r *http.Request) error {
+		r.Header.Del(key)
+		return nil
+	}
+}
+
+// RawBody is a request option for sending data with the content type contentType as body of the
+// request, e.g. a binary file with a request created by NewRequestWithContext without body.
+func RawBody(data []byte, contentType string) RequestOpt {
+	return func(r *http.Request) error {
+		r.ContentLength = int64(len(data))
+		r.GetBody = func() (io.ReadCloser, error) {
+			if len(data) == 0 {
+				return http.NoBody, nil
+			}
+
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		}
+		r.Body, _ = r.GetBody()
+		r.Header.Set("Content-Type", contentType)
+
+		return nil
+	}
+}
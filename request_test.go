@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type listOptions struct {
+	QueryStruct
+	Page    int    `url:"page,omitempty"`
+	PerPage int    `url:"per_page,omitempty"`
+	Search  string `url:"search,omitempty"`
+}
+
+func TestRequestOpt(t *testing.T) {
+	c, err := New(baseurl)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("query", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodGet, "nodes?page=1&sort=name", nil,
+			Query(listOptions{Page: 2, Search: "name=testHost"}))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://hostname.domain/nodes?page=2&search=name%3DtestHost&sort=name", req.URL.String())
+	})
+
+	t.Run("query pointer", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodGet, "nodes", nil, Query(&listOptions{PerPage: 10}))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://hostname.domain/nodes?per_page=10", req.URL.String())
+	})
+
+	t.Run("query values", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodGet, "nodes", nil,
+			QueryValues(url.Values{"tag": {"a", "b"}}))
+		assert.Nil(t, err)
+		assert.Equal(t, "https://hostname.domain/nodes?tag=a&tag=b", req.URL.String())
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		c, err := New(baseurl, WithHeader(http.Header{"X-Tenant": {"a"}}))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequestWithContext(ctx, http.MethodGet, "nodes", nil,
+			RemoveHeader("Accept"),
+			SetHeader("Content-Type", ContentTypeYAML),
+			AddHeader("X-Tenant", "b"),
+			AddHeader("If-None-Match", `"v1"`),
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, http.Header{
+			"Content-Type":  {ContentTypeYAML},
+			"X-Tenant":      {"a", "b"},
+			"If-None-Match": {`"v1"`},
+		}, req.Header)
+
+		req, err = c.NewRequestWithContext(ctx, http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, req.Header["X-Tenant"])
+		assert.Equal(t, ContentTypeJSON, req.Header.Get("Accept"))
+	})
+
+	t.Run("raw body", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodPut, "files/1", nil, RawBody([]byte("data"), "application/octet-stream"))
+		assert.Nil(t, err)
+		assert.Equal(t, "application/octet-stream", req.Header.Get("Content-Type"))
+		assert.Equal(t, int64(4), req.ContentLength)
+
+		body, err := ioutil.ReadAll(req.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, "data", string(body))
+
+		req, err = c.NewRequestWithContext(ctx, http.MethodPut, "files/1", nil, RawBody(nil, "application/octet-stream"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.NoBody, req.Body)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		fail := func(*http.Request) error {
+			return errors.New("failed")
+		}
+
+		_, err := c.NewRequestWithContext(ctx, http.MethodGet, "nodes", nil, fail)
+		assert.EqualError(t, err, "failed")
+	})
+}
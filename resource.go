@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Resource implements the CRUD methods of a simple REST resource with items of type T and
+// identifiers of type ID, e.g. for a path template posts/{id}:
+//
+//	GET    posts       List
+//	POST   posts       Create
+//	GET    posts/{id}  Get
+//	PUT    posts/{id}  Update
+//	DELETE posts/{id}  Delete
+//
+// The path template is relative to the BaseURL of the client (see NewRequest), without {id} the
+// items are at <path>/{id}.
+type Resource[T, ID any] struct {
+	client *Client
+	path   string
+}
+
+// NewResource returns the Resource with the path template path of the client c.
+func NewResource[T, ID any](c *Client, path string) *Resource[T, ID] {
+	if !strings.Contains(path, "{id}") {
+		path = strings.TrimSuffix(path, "/") + "/{id}"
+	}
+
+	return &Resource[T, ID]{
+		client: c,
+		path:   path,
+	}
+}
+
+// List returns the items, e.g. with the request option Query for paging or filtering.
+func (r *Resource[T, ID]) List(ctx context.Context, opts ...RequestOpt) ([]T, *http.Response, error) {
+	req, err := r.client.NewRequestWithContext(ctx, http.MethodGet, r.collection(), nil, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return DoTyped[[]T](ctx, r.client, req)
+}
+
+// Get returns the item with the identifier id.
+func (r *Resource[T, ID]) Get(ctx context.Context, id ID, opts ...RequestOpt) (*T, *http.Response, error) {
+	return r.send(ctx, http.MethodGet, r.item(id), nil, opts)
+}
+
+// Create creates the item v and returns the created item.
+func (r *Resource[T, ID]) Create(ctx context.Context, v *T, opts ...RequestOpt) (*T, *http.Response, error) {
+	return r.send(ctx, http.MethodPost, r.collection(), v, opts)
+}
+
+// Update replaces the item with the identifier id by v and returns the updated item.
+func (r *Resource[T, ID]) Update(ctx context.Context, id ID, v *T, opts ...RequestOpt) (*T, *http.Response, error) {
+	return r.send(ctx, http.MethodPut, r.item(id), v, opts)
+}
+
+// Delete deletes the item with the identifier id.
+func (r *Resource[T, ID]) Delete(ctx context.Context, id ID, opts ...RequestOpt) (*http.Response, error) {
+	req, err := r.client.NewRequestWithContext(ctx, http.MethodDelete, r.item(id), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.client.Do(ctx, req, nil)
+}
+
+// send sends a request with the body v (or nil) and returns the decoded item.
+func (r *Resource[T, ID]) send(ctx context.Context, method, urlStr string, v *T, opts []RequestOpt) (*T, *http.Response, error) {
+	var body interface{}
+	if v != nil {
+		body = v
+	}
+
+	req, err := r.client.NewRequestWithContext(ctx, method, urlStr, body, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return DoTyped[*T](ctx, r.client, req)
+}
+
+// collection returns the path of the items.
+func (r *Resource[T, ID]) collection() string {
+	p := r.path[:strings.Index(r.path, "{id}")]
+
+	return strings.TrimSuffix(p, "/")
+}
+
+// item returns the path of the item with the identifier id.
+func (r *Resource[T, ID]) item(id ID) string {
+	return strings.Replace(r.path, "{id}", url.PathEscape(fmt.Sprint(id)), 1)
+}
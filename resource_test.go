@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type post struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func TestResource(t *testing.T) {
+	posts := map[int]post{1: {1, "first"}}
+	requests := []string{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+
+		id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/posts/"))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/posts":
+			_ = json.NewEncoder(w).Encode([]post{posts[1]})
+		case r.Method == http.MethodPost:
+			p := post{}
+			_ = json.NewDecoder(r.Body).Decode(&p)
+			p.ID = len(posts) + 1
+			posts[p.ID] = p
+			_ = json.NewEncoder(w).Encode(p)
+		case r.Method == http.MethodPut:
+			p := post{}
+			_ = json.NewDecoder(r.Body).Decode(&p)
+			p.ID = id
+			posts[id] = p
+			_ = json.NewEncoder(w).Encode(p)
+		case r.Method == http.MethodDelete:
+			delete(posts, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			p, ok := posts[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(p)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL + "/api/")
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	r := NewResource[post, int](c, "posts/{id}")
+
+	t.Run("list", func(t *testing.T) {
+		requests = requests[:0]
+
+		items, _, err := r.List(ctx, Query(listOptions{Page: 2}))
+		assert.Nil(t, err)
+		assert.Equal(t, []post{{1, "first"}}, items)
+
+		_, _, err = r.List(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"GET /api/posts?page=2", "GET /api/posts"}, requests)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		p, _, err := r.Get(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, &post{1, "first"}, p)
+
+		p, resp, err := r.Get(ctx, 42)
+		assert.NotNil(t, err)
+		assert.Nil(t, p)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("create update delete", func(t *testing.T) {
+		requests = requests[:0]
+
+		p, _, err := r.Create(ctx, &post{Title: "second"})
+		assert.Nil(t, err)
+		assert.Equal(t, &post{2, "second"}, p)
+
+		p, _, err = r.Update(ctx, 2, &post{Title: "updated"})
+		assert.Nil(t, err)
+		assert.Equal(t, &post{2, "updated"}, p)
+
+		_, err = r.Delete(ctx, 2)
+		assert.Nil(t, err)
+		assert.Len(t, posts, 1)
+
+		assert.Equal(t, []string{"POST /api/posts", "PUT /api/posts/2", "DELETE /api/posts/2"}, requests)
+	})
+
+	t.Run("path without id", func(t *testing.T) {
+		r := NewResource[post, string](c, "posts/")
+		assert.Equal(t, "posts", r.collection())
+		assert.Equal(t, "posts/a%2Fb", r.item("a/b"))
+	})
+}
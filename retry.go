@@ -0,0 +1,219 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls the retries of Retry and, with WithRetry, of Do.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+
+	// Backoff is the time to wait before the first retry, it is doubled for every
+	// further retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Jitter randomizes the backoff by up to the fraction Jitter (0 to 1) in both directions,
+	// e.g. 0.2 waits between 80% and 120% of the backoff, so clients do not retry in lockstep.
+	Jitter float64
+
+	// Retryable reports whether a failed attempt is retried, if nil connection failures (dial
+	// errors, timeouts and refused or reset connections) and responses with the status
+	// StatusCodes (default 429 and 5xx) are retried, but not requests queued by the offline
+	// queue (ErrQueued).
+	Retryable   func(*http.Response, error) bool
+	StatusCodes []int
+}
+
+// DefaultRetryPolicy is used by Retry if the client has no RetryPolicy.
+// nolint: gochecknoglobals
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     100 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+}
+
+// WithRetryPolicy is a client option for setting the RetryPolicy used by Retry.
+func WithRetryPolicy(p RetryPolicy) Opt {
+	return func(c *Client) error {
+		if p.MaxAttempts < 1 {
+			return errors.New("max attempts must be at least 1")
+		}
+
+		if p.Jitter < 0 || p.Jitter > 1 {
+			return errors.New("jitter must be between 0 and 1")
+		}
+
+		c.RetryPolicy = &p
+
+		return nil
+	}
+}
+
+// WithRetry is a client option for retrying the failed requests of Do with the RetryPolicy p
+// (see Retry), so the callers do not need retry loops. Only idempotent requests are retried:
+// requests with the methods GET, HEAD, OPTIONS, TRACE, PUT and DELETE or with an
+// Idempotency-Key header, whose body can be sent again (see http.Request.GetBody, which is set
+// by NewRequest). The requests sent by the fn of Retry and by Poll are not retried again by Do,
+// so their attempts do not multiply.
+func WithRetry(p RetryPolicy) Opt {
+	return func(c *Client) error {
+		if err := WithRetryPolicy(p)(c); err != nil {
+			return err
+		}
+
+		c.autoRetry = true
+
+		return nil
+	}
+}
+
+// retryingKey is the context key of the attempts of Retry and Poll.
+type retryingKey struct{}
+
+// Retry calls fn until it succeeds, the error is not retryable or the maximum number of
+// attempts of the RetryPolicy of the client is reached. Between the attempts it waits with
+// exponential backoff, unless ctx is done. fn must be safe to be called more than once,
+// i.e. the request must be idempotent.
+func (c *Client) Retry(ctx context.Context, fn func(context.Context) (*http.Response, error)) (*http.Response, error) {
+	p := DefaultRetryPolicy
+	if c.RetryPolicy != nil {
+		p = *c.RetryPolicy
+	}
+
+	retryable := p.retryable()
+	backoff := p.Backoff
+	ctx = context.WithValue(ctx, retryingKey{}, true)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := fn(ctx)
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+
+		t := time.NewTimer(jitter(backoff, p.Jitter))
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return resp, err
+		case <-t.C:
+		}
+
+		c.stats.retry()
+
+		if backoff *= 2; p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// doRetry sends the request req with dispatch and, with WithRetry, retries it if it failed and
+// is idempotent and not already an attempt of Retry or Poll.
+func (c *Client) doRetry(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	if !c.autoRetry || !idempotent(req) || ctx.Value(retryingKey{}) != nil {
+		return c.dispatch(ctx, req, v)
+	}
+
+	attempt := req
+
+	return c.Retry(ctx, func(ctx context.Context) (*http.Response, error) {
+		r := attempt
+		attempt = nil
+
+		if r == nil {
+			var err error
+			if r, err = rewind(req); err != nil {
+				return nil, err
+			}
+		}
+
+		return c.dispatch(ctx, r, v)
+	})
+}
+
+// idempotent reports whether the request req can be sent again.
+func idempotent(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// rewind returns a copy of the request req with a new body.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.WithContext(req.Context())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		r.Body = body
+	}
+
+	return r, nil
+}
+
+// jitter returns d randomized by up to the fraction f in both directions.
+func jitter(d time.Duration, f float64) time.Duration {
+	if f <= 0 {
+		return d
+	}
+
+	return time.Duration(float64(d) * (1 + f*(2*rand.Float64()-1))) // nolint: gosec // no security
+}
+
+// retryable returns the Retryable of p or, if it is nil, retryableDefault with the StatusCodes
+// of p.
+func (p RetryPolicy) retryable() func(*http.Response, error) bool {
+	if p.Retryable != nil {
+		return p.Retryable
+	}
+
+	return func(resp *http.Response, err error) bool {
+		return retryableDefault(resp, err, p.StatusCodes)
+	}
+}
+
+// retryableDefault reports whether err is a connection failure (see connectionFailure) or resp
+// (or the HTTPError in err) has one of the status codes or, if there are none, the status 429
+// (too many requests) or 5xx. Queued requests (ErrQueued) are not retried, they are replayed.
+func retryableDefault(resp *http.Response, err error, statusCodes []int) bool {
+	if errors.Is(err, ErrQueued) {
+		return false
+	}
+
+	status := statusCode(err)
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	if status == 0 {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && connectionFailure(err)
+	}
+
+	if len(statusCodes) > 0 {
+		for _, code := range statusCodes {
+			if status == code {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return status == http.StatusTooManyRequests || status >= 500
+}
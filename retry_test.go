@@ -0,0 +1,285 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}
+
+	attempts := func(statusCodes ...int) (func(context.Context) (*http.Response, error), *int) {
+		n := 0
+
+		return func(context.Context) (*http.Response, error) {
+			code := statusCodes[n]
+			n++
+
+			resp := &http.Response{StatusCode: code, Status: http.StatusText(code)}
+			if code >= 300 {
+				return resp, errors.New(resp.Status)
+			}
+
+			return resp, nil
+		}, &n
+	}
+
+	t.Run("with invalid retry policy", func(t *testing.T) {
+		_, err := New(baseurl, WithRetryPolicy(RetryPolicy{}))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("success after retries", func(t *testing.T) {
+		c, err := New(baseurl, WithRetryPolicy(policy))
+		assert.Nil(t, err)
+
+		fn, n := attempts(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+
+		resp, err := c.Retry(context.Background(), fn)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 3, *n)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		c, err := New(baseurl, WithRetryPolicy(policy))
+		assert.Nil(t, err)
+
+		fn, n := attempts(http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK)
+
+		resp, err := c.Retry(context.Background(), fn)
+		assert.NotNil(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, 3, *n)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		c, err := New(baseurl, WithRetryPolicy(policy))
+		assert.Nil(t, err)
+
+		fn, n := attempts(http.StatusNotFound, http.StatusOK)
+
+		_, err = c.Retry(context.Background(), fn)
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, *n)
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		c, err := New(baseurl, WithRetryPolicy(policy))
+		assert.Nil(t, err)
+
+		n := 0
+
+		_, err = c.Retry(context.Background(), func(context.Context) (*http.Response, error) {
+			n++
+			return nil, &url.Error{Op: "Get", URL: baseurl, Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, 3, n)
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		c, err := New(baseurl, WithRetryPolicy(policy))
+		assert.Nil(t, err)
+
+		for _, e := range []error{ErrQueued, ErrCircuitOpen, ErrMemoryBudget, errors.New("x509: certificate signed by unknown authority")} {
+			n := 0
+
+			_, err = c.Retry(context.Background(), func(context.Context) (*http.Response, error) {
+				n++
+				return nil, e
+			})
+			assert.Equal(t, e, err)
+			assert.Equal(t, 1, n, e)
+		}
+
+		assert.False(t, retryableDefault(&http.Response{StatusCode: http.StatusServiceUnavailable}, ErrQueued, nil))
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		c, err := New(baseurl, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}))
+		assert.Nil(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		fn, n := attempts(http.StatusBadGateway, http.StatusOK)
+
+		_, err = c.Retry(ctx, fn)
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, *n)
+	})
+
+	t.Run("default retry policy", func(t *testing.T) {
+		c, err := New(baseurl)
+		assert.Nil(t, err)
+		assert.Nil(t, c.RetryPolicy)
+
+		fn, n := attempts(http.StatusInternalServerError, http.StatusOK)
+
+		_, err = c.Retry(context.Background(), fn)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, *n)
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		failures = map[string]int{}
+		bodies   []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if failures[r.URL.Path] > 0 {
+			failures[r.URL.Path]--
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if r.URL.Path == "/conflict" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"Text":"ok"}`))
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Jitter: 0.5}))
+	assert.Nil(t, err)
+
+	do := func(c *Client, method, path string, fail int, opts ...RequestOpt) (*http.Response, error) {
+		mu.Lock()
+		failures[path] = fail
+		bodies = nil
+		mu.Unlock()
+
+		req, err := c.NewRequestWithContext(context.Background(), method, path, message{Text: "body"}, opts...)
+		assert.Nil(t, err)
+
+		return c.Do(context.Background(), req, &message{})
+	}
+
+	t.Run("success after retries", func(t *testing.T) {
+		before := c.Stats().Retries
+
+		resp, err := do(c, http.MethodPut, "/put", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(2), c.Stats().Retries-before)
+		assert.Len(t, bodies, 3)
+		assert.Equal(t, bodies[0], bodies[2]) // the body is sent again
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		_, err := do(c, http.MethodGet, "/get", 3)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(err))
+		assert.Len(t, bodies, 3)
+	})
+
+	t.Run("not idempotent", func(t *testing.T) {
+		_, err := do(c, http.MethodPost, "/post", 1)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(err))
+		assert.Len(t, bodies, 1)
+
+		_, err = do(c, http.MethodPost, "/post", 1, SetHeader("Idempotency-Key", "1"))
+		assert.Nil(t, err)
+		assert.Len(t, bodies, 2)
+	})
+
+	t.Run("status codes", func(t *testing.T) {
+		_, err := do(c, http.MethodGet, "/conflict", 0)
+		assert.Equal(t, http.StatusConflict, statusCode(err))
+		assert.Len(t, bodies, 1)
+
+		c, err := New(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 2, StatusCodes: []int{http.StatusConflict}}))
+		assert.Nil(t, err)
+
+		_, err = do(c, http.MethodGet, "/conflict", 0)
+		assert.Equal(t, http.StatusConflict, statusCode(err))
+		assert.Len(t, bodies, 2)
+
+		_, err = do(c, http.MethodGet, "/get", 1)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(err))
+		assert.Len(t, bodies, 1)
+	})
+
+	t.Run("nested retry", func(t *testing.T) {
+		mu.Lock()
+		failures["/get"] = 5
+		mu.Unlock()
+
+		attempts := 0
+
+		_, err := c.Retry(context.Background(), func(ctx context.Context) (*http.Response, error) {
+			attempts++
+
+			req, err := c.NewRequestWithContext(ctx, http.MethodGet, "/get", nil)
+			assert.Nil(t, err)
+
+			return c.Do(ctx, req, nil)
+		})
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode(err))
+		assert.Equal(t, 3, attempts)
+
+		mu.Lock()
+		assert.Equal(t, 2, failures["/get"]) // 3 requests, not 9
+		mu.Unlock()
+	})
+
+	t.Run("offline queue", func(t *testing.T) {
+		store := &memoryOfflineStore{}
+
+		c, err := New(ts.URL, WithOfflineQueue(store), WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+		assert.Nil(t, err)
+
+		_, err = do(c, http.MethodPut, "/put", 1)
+		assert.True(t, errors.Is(err, ErrQueued))
+		assert.Len(t, bodies, 1)
+		assert.Len(t, store.requests, 1) // queued once, not once per attempt
+
+		_, err = do(c, http.MethodPut, "/put", 0)
+		assert.True(t, errors.Is(err, ErrQueued))
+		assert.Len(t, bodies, 0)
+		assert.Len(t, store.requests, 2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := New(ts.URL, WithRetry(RetryPolicy{}))
+		assert.NotNil(t, err)
+
+		_, err = New(ts.URL, WithRetry(RetryPolicy{MaxAttempts: 1, Jitter: 2}))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestJitter(t *testing.T) {
+	assert.Equal(t, time.Second, jitter(time.Second, 0))
+
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second, 0.2)
+		assert.True(t, d >= 800*time.Millisecond && d <= 1200*time.Millisecond, d)
+	}
+}
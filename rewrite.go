@@ -0,0 +1,133 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RewriteRule rewrites the URLs of the requests starting with From to start with To instead (see
+// WithRewriteRules), e.g. with From http://orders:8080/api and To https://gateway.domain/orders/api
+// the URL http://orders:8080/api/orders/1 becomes https://gateway.domain/orders/api/orders/1.
+// The paths are matched by segments, the scheme and host of From are optional (any scheme or
+// host matches), the scheme and host of To as well (the scheme or host is kept).
+type RewriteRule struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// rewriteRule is a parsed RewriteRule.
+type rewriteRule struct {
+	from, to *url.URL
+}
+
+// WithRewriteRules is a client option for rewriting the URLs of the requests with the rules just
+// before they are sent, e.g. so the same (generated) client can target the backends directly in
+// development and through an API gateway with path prefixes in production. The first matching
+// rule is applied.
+func WithRewriteRules(rules ...RewriteRule) Opt {
+	return func(c *Client) error {
+		parsed := make([]rewriteRule, 0, len(c.rewrites)+len(rules))
+		parsed = append(parsed, c.rewrites...)
+
+		for _, r := range rules {
+			rr, err := r.parse()
+			if err != nil {
+				return err
+			}
+
+			parsed = append(parsed, rr)
+		}
+
+		c.rewrites = parsed
+
+		return nil
+	}
+}
+
+// parse returns the parsed rule r.
+func (r RewriteRule) parse() (rewriteRule, error) {
+	if r.From == "" || r.To == "" {
+		return rewriteRule{}, errors.New("rewrite rule requires from and to")
+	}
+
+	from, err := url.Parse(r.From)
+	if err != nil {
+		return rewriteRule{}, fmt.Errorf("invalid rewrite rule from: %w", redactError(err))
+	}
+
+	to, err := url.Parse(r.To)
+	if err != nil {
+		return rewriteRule{}, fmt.Errorf("invalid rewrite rule to: %w", redactError(err))
+	}
+
+	if from.RawQuery != "" || from.Fragment != "" || to.RawQuery != "" || to.Fragment != "" {
+		return rewriteRule{}, fmt.Errorf("rewrite rule %s -> %s: query and fragment are not supported", RedactURL(r.From), RedactURL(r.To))
+	}
+
+	return rewriteRule{from: from, to: to}, nil
+}
+
+// rewrite returns the URL u rewritten by the rule r and whether r matches u.
+func (r rewriteRule) rewrite(u *url.URL) (*url.URL, bool) {
+	if r.from.Scheme != "" && !strings.EqualFold(r.from.Scheme, u.Scheme) {
+		return nil, false
+	}
+
+	if r.from.Host != "" && !strings.EqualFold(r.from.Host, u.Host) {
+		return nil, false
+	}
+
+	prefix := strings.TrimSuffix(r.from.EscapedPath(), "/")
+	p := u.EscapedPath()
+
+	if !strings.HasPrefix(p, prefix) || (len(p) > len(prefix) && p[len(prefix)] != '/') {
+		return nil, false
+	}
+
+	rest := p[len(prefix):]
+	target := strings.TrimSuffix(r.to.EscapedPath(), "/") + rest
+
+	if target == "" && rest == "" && strings.HasSuffix(r.to.EscapedPath(), "/") {
+		target = "/"
+	}
+
+	path, err := url.PathUnescape(target)
+	if err != nil {
+		return nil, false
+	}
+
+	rewritten := *u
+	rewritten.Path, rewritten.RawPath = path, target
+
+	if r.to.Scheme != "" {
+		rewritten.Scheme = r.to.Scheme
+	}
+
+	if r.to.Host != "" {
+		rewritten.Host = r.to.Host
+	}
+
+	return &rewritten, true
+}
+
+// rewrite returns the request req with the URL rewritten by the first matching rewrite rule of
+// the client, req itself if no rule matches.
+func (c *Client) rewrite(req *http.Request) *http.Request {
+	for _, r := range c.rewrites {
+		u, ok := r.rewrite(req.URL)
+		if !ok {
+			continue
+		}
+
+		rewritten := req.WithContext(req.Context())
+		rewritten.URL = u
+		rewritten.Host = u.Host
+
+		return rewritten
+	}
+
+	return req
+}
@@ -0,0 +1,167 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// ScheduledRequest is a request scheduled with DoAt, as stored in the ScheduleStore, or a request
+// queued while offline (see WithOfflineQueue), At is the time it was queued. The Header does not
+// contain the credentials of the request (see SensitiveHeaders).
+type ScheduledRequest struct {
+	ID     string
+	At     time.Time
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// ScheduleStore persists scheduled requests (see WithScheduleStore), e.g. in a database.
+type ScheduleStore interface {
+	// Save stores the scheduled request s before waiting for its time.
+	Save(ctx context.Context, s ScheduledRequest) error
+
+	// Delete removes the scheduled request with the ID id after it was sent.
+	Delete(ctx context.Context, id string) error
+}
+
+// WithScheduleStore is a client option for persisting the requests scheduled with DoAt and
+// DoAfter in the store s, so requests pending on shutdown can be resumed on the next start
+// with Resume.
+func WithScheduleStore(s ScheduleStore) Opt {
+	return func(c *Client) error {
+		if s == nil {
+			return errors.New("schedule store cannot be nil")
+		}
+
+		c.schedule = s
+
+		return nil
+	}
+}
+
+// DoAt sends the request req with Do at the time t of the clock of the client (see WithClock),
+// e.g. for APIs with strict time windows. It waits until t, unless ctx is done before. With a
+// ScheduleStore the request is saved before waiting and deleted after it was sent.
+func (c *Client) DoAt(ctx context.Context, req *http.Request, v interface{}, t time.Time) (*http.Response, error) {
+	if c.schedule == nil {
+		return c.doAt(ctx, req, v, t, "")
+	}
+
+	s, err := newScheduledRequest(req, t)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.schedule.Save(ctx, s); err != nil {
+		return nil, fmt.Errorf("save scheduled request: %w", err)
+	}
+
+	return c.doAt(ctx, req, v, t, s.ID)
+}
+
+// DoAfter sends the request req with Do after the duration d (see DoAt).
+func (c *Client) DoAfter(ctx context.Context, req *http.Request, v interface{}, d time.Duration) (*http.Response, error) {
+	return c.DoAt(ctx, req, v, c.Now().Add(d))
+}
+
+// Resume sends the stored scheduled request s at its time (see DoAt), e.g. after a restart.
+func (c *Client) Resume(ctx context.Context, s ScheduledRequest, v interface{}) (*http.Response, error) {
+	req, err := c.scheduledRequest(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.doAt(ctx, req, v, s.At, s.ID)
+}
+
+// scheduledRequest returns the request of s with the current credentials of the client (see
+// newScheduledRequest).
+func (c *Client) scheduledRequest(ctx context.Context, s ScheduledRequest) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, s.Method, s.URL, bytes.NewReader(s.Body))
+	if err != nil {
+		return nil, redactError(err)
+	}
+
+	req.Header = s.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	base := c.baseHeader
+	if base == nil {
+		base = c.requestHeader()
+	}
+
+	for _, k := range SensitiveHeaders {
+		if v, ok := base[http.CanonicalHeaderKey(k)]; ok {
+			req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+
+	if err := c.setToken(req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// doAt waits until t and sends req, the scheduled request with the ID id is deleted afterwards.
+func (c *Client) doAt(ctx context.Context, req *http.Request, v interface{}, t time.Time, id string) (*http.Response, error) {
+	if err := sleep(ctx, t.Sub(c.Now())); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Do(ctx, req, v)
+
+	if id != "" && c.schedule != nil {
+		if derr := c.schedule.Delete(context.WithoutCancel(ctx), id); derr != nil {
+			err = errors.Join(err, fmt.Errorf("delete scheduled request: %w", derr))
+		}
+	}
+
+	return resp, err
+}
+
+// newScheduledRequest returns the scheduled request of req with a random ID, the body of req is
+// read and replaced. The credentials (the SensitiveHeaders) are not stored, the current ones of
+// the client are set when the request is sent (see scheduledRequest).
+func newScheduledRequest(req *http.Request, t time.Time) (ScheduledRequest, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ScheduledRequest{}, err
+	}
+
+	s := ScheduledRequest{
+		ID:     hex.EncodeToString(id),
+		At:     t,
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+
+	for _, k := range SensitiveHeaders {
+		s.Header.Del(k)
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return ScheduledRequest{}, err
+		}
+
+		_ = req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		s.Body = body
+	}
+
+	return s, nil
+}
@@ -0,0 +1,171 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryScheduleStore struct {
+	mu       sync.Mutex
+	requests map[string]ScheduledRequest
+}
+
+func (s *memoryScheduleStore) Save(_ context.Context, r ScheduledRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[r.ID] = r
+
+	return nil
+}
+
+func (s *memoryScheduleStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.requests, id)
+
+	return nil
+}
+
+func (s *memoryScheduleStore) list() []ScheduledRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := make([]ScheduledRequest, 0, len(s.requests))
+	for _, r := range s.requests {
+		l = append(l, r)
+	}
+
+	return l
+}
+
+func TestDoAt(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+	}))
+	defer ts.Close()
+
+	store := &memoryScheduleStore{requests: map[string]ScheduledRequest{}}
+
+	c, err := New(ts.URL, WithScheduleStore(store))
+	assert.Nil(t, err)
+
+	t.Run("after", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodPost, "/jobs", message{Text: "later"})
+		assert.Nil(t, err)
+
+		start := time.Now()
+		done := make(chan error, 1)
+
+		go func() {
+			_, err := c.DoAfter(context.Background(), req, nil, 50*time.Millisecond)
+			done <- err
+		}()
+
+		assert.Eventually(t, func() bool { return len(store.list()) == 1 }, time.Second, time.Millisecond)
+
+		assert.Nil(t, <-done)
+		assert.True(t, time.Since(start) >= 50*time.Millisecond)
+		assert.Empty(t, store.list())
+		assert.Equal(t, []string{`{"Text":"later"}` + "\n"}, bodies)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		bodies = nil
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		req, err := c.NewRequest(http.MethodPost, "/jobs", message{Text: "resumed"})
+		assert.Nil(t, err)
+
+		go func() {
+			assert.Eventually(t, func() bool { return len(store.list()) == 1 }, time.Second, time.Millisecond)
+			cancel()
+		}()
+
+		_, err = c.DoAt(ctx, req, nil, time.Now().Add(time.Hour))
+		assert.True(t, errors.Is(err, context.Canceled))
+
+		pending := store.list()
+		assert.Len(t, pending, 1)
+		assert.Empty(t, bodies)
+
+		pending[0].At = time.Now()
+
+		_, err = c.Resume(context.Background(), pending[0], nil)
+		assert.Nil(t, err)
+		assert.Empty(t, store.list())
+		assert.Equal(t, []string{`{"Text":"resumed"}` + "\n"}, bodies)
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		var auth []string
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = append(auth, r.Header.Get("Authorization"))
+		}))
+		defer ts.Close()
+
+		store := &memoryScheduleStore{requests: map[string]ScheduledRequest{}}
+
+		c, err := New(ts.URL, WithScheduleStore(store), WithBasicAuth(username, "old"))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodPost, "/jobs", nil)
+		assert.Nil(t, err)
+		req.Header.Set("Cookie", "session=1")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = c.DoAt(ctx, req, nil, time.Now().Add(time.Hour))
+		assert.True(t, errors.Is(err, context.Canceled))
+
+		pending := store.list()
+		assert.Len(t, pending, 1)
+		assert.Equal(t, "", pending[0].Header.Get("Authorization"))
+		assert.Equal(t, "", pending[0].Header.Get("Cookie"))
+		assert.Equal(t, ContentTypeJSON, pending[0].Header.Get("Content-Type"))
+
+		// the credentials changed before the restart
+		c, err = New(ts.URL, WithScheduleStore(store), WithBearerToken("new"))
+		assert.Nil(t, err)
+
+		pending[0].At = time.Now()
+
+		_, err = c.Resume(context.Background(), pending[0], nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"Bearer new"}, auth)
+	})
+
+	t.Run("without store", func(t *testing.T) {
+		frozen := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		c, err := New(ts.URL, WithClock(ClockFunc(func() time.Time { return frozen })))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "/jobs", nil)
+		assert.Nil(t, err)
+
+		_, err = c.DoAt(context.Background(), req, nil, frozen.Add(-time.Minute))
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,175 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrPlaintext is returned for http:// URLs if the SecurityPolicy of the client does not allow
+// plaintext requests.
+var ErrPlaintext = errors.New("plaintext http is forbidden by the security policy")
+
+// SecurityPolicy is a policy for the connections of clients (see WithSecurityPolicy), e.g.
+// shipped by a security team for all clients:
+//
+//	client, err := api.NewClient(baseURL, httpclient.WithSecurityPolicy(policy.Default))
+type SecurityPolicy struct {
+	// MinTLSVersion is the minimum TLS version (default tls.VersionTLS12).
+	MinTLSVersion uint16
+
+	// CipherSuites are the approved cipher suites of TLS 1.2 and older versions (default: the
+	// cipher suites of crypto/tls), the cipher suites of TLS 1.3 are not configurable.
+	CipherSuites []uint16
+
+	// AllowPlaintext allows http:// base and request URLs.
+	AllowPlaintext bool
+}
+
+// WithSecurityPolicy is a client option for enforcing the security policy p: the TLS settings
+// are set on a clone of the transport of the HTTP client (which has to be an *http.Transport,
+// so the option has to be applied after WithHTTPClient) and verified for every connection,
+// http:// base URLs, request URLs and redirects fail with ErrPlaintext unless plaintext is
+// allowed.
+func WithSecurityPolicy(p SecurityPolicy) Opt {
+	return func(c *Client) error {
+		if p.MinTLSVersion == 0 {
+			p.MinTLSVersion = tls.VersionTLS12
+		}
+
+		if err := p.checkURL(c.GetBaseURL().Scheme); err != nil {
+			return err
+		}
+
+		err := c.updateTransport(func(t *http.Transport) {
+			cfg := t.TLSClientConfig
+			if cfg.MinVersion < p.MinTLSVersion {
+				cfg.MinVersion = p.MinTLSVersion
+			}
+
+			if len(p.CipherSuites) > 0 {
+				cfg.CipherSuites = p.CipherSuites
+			}
+
+			verifyConnection(cfg, p.verify)
+		})
+		if err != nil {
+			return fmt.Errorf("security policy: %w", err)
+		}
+
+		checkRedirect := c.client.CheckRedirect
+		c.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if err := p.checkURL(req.URL.Scheme); err != nil {
+				return err
+			}
+
+			if checkRedirect != nil {
+				return checkRedirect(req, via)
+			}
+
+			if len(via) >= 10 { // like the default policy of http.Client
+				return errors.New("stopped after 10 redirects")
+			}
+
+			return nil
+		}
+
+		c.policy = &p
+
+		return nil
+	}
+}
+
+// checkURL returns ErrPlaintext for the URL scheme http if plaintext is not allowed.
+func (p *SecurityPolicy) checkURL(scheme string) error {
+	if scheme == "http" && !p.AllowPlaintext {
+		return ErrPlaintext
+	}
+
+	return nil
+}
+
+// verify checks the TLS version and cipher suite of the connection cs.
+func (p *SecurityPolicy) verify(cs tls.ConnectionState) error {
+	if cs.Version < p.MinTLSVersion {
+		return fmt.Errorf("TLS version %s is forbidden by the security policy", tls.VersionName(cs.Version))
+	}
+
+	if cs.Version >= tls.VersionTLS13 || len(p.CipherSuites) == 0 {
+		return nil
+	}
+
+	for _, id := range p.CipherSuites {
+		if id == cs.CipherSuite {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cipher suite %s is forbidden by the security policy", tls.CipherSuiteName(cs.CipherSuite))
+}
+
+// cloneTransport returns a clone of the *http.Transport of the HTTP client of c
+// (http.DefaultTransport if it is nil) with a TLS configuration.
+func (c *Client) cloneTransport() (*http.Transport, error) {
+	rt := c.client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("*http.Transport required, not %T", rt)
+	}
+
+	t = t.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return t, nil
+}
+
+// updateTransport applies f to a clone of the transport of the HTTP client of c (see
+// cloneTransport). With pinned certificates, f is also applied to the transport without the
+// pins, which is used if they are installed again (see WithPinFailureHandler), so the options
+// can be applied in any order.
+func (c *Client) updateTransport(f func(*http.Transport)) error {
+	t, err := c.cloneTransport()
+	if err != nil {
+		return err
+	}
+
+	f(t)
+	c.setTransport(t)
+
+	if c.pins != nil && c.pins.unpinned != nil {
+		p := c.pins.clone()
+		p.unpinned = p.unpinned.Clone()
+		f(p.unpinned)
+		c.pins = p
+	}
+
+	return nil
+}
+
+// setTransport sets the transport t on a copy of the HTTP client of c, which may be shared.
+func (c *Client) setTransport(t *http.Transport) {
+	hc := *c.client
+	hc.Transport = t
+	c.client = &hc
+}
+
+// verifyConnection adds the verification f to the VerifyConnection function of cfg.
+func verifyConnection(cfg *tls.Config, f func(tls.ConnectionState) error) {
+	verify := cfg.VerifyConnection
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if verify != nil {
+			if err := verify(cs); err != nil {
+				return err
+			}
+		}
+
+		return f(cs)
+	}
+}
@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityPolicy(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, plain.URL, http.StatusFound)
+		}
+	}))
+	ts.TLS = &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}
+	ts.StartTLS()
+
+	defer ts.Close()
+
+	get := func(c *Client, path string) error {
+		req, err := c.NewRequest(http.MethodGet, path, nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, nil)
+
+		return err
+	}
+
+	t.Run("TLS", func(t *testing.T) {
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()), WithSecurityPolicy(SecurityPolicy{
+			CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		}))
+		assert.Nil(t, err)
+		assert.Nil(t, get(c, "/"))
+		assert.Nil(t, ts.Client().Transport.(*http.Transport).TLSClientConfig.VerifyConnection)
+	})
+
+	t.Run("TLS version", func(t *testing.T) {
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()), WithSecurityPolicy(SecurityPolicy{MinTLSVersion: tls.VersionTLS13}))
+		assert.Nil(t, err)
+		assert.NotNil(t, get(c, "/"))
+	})
+
+	t.Run("cipher suite", func(t *testing.T) {
+		p := SecurityPolicy{CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}}
+
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()), WithSecurityPolicy(p))
+		assert.Nil(t, err)
+		assert.NotNil(t, get(c, "/"))
+
+		p.MinTLSVersion = tls.VersionTLS12
+		assert.EqualError(t, p.verify(tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}),
+			"cipher suite TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 is forbidden by the security policy")
+		assert.EqualError(t, p.verify(tls.ConnectionState{Version: tls.VersionTLS11}), "TLS version TLS 1.1 is forbidden by the security policy")
+		assert.Nil(t, p.verify(tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256}))
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		_, err := New(plain.URL, WithSecurityPolicy(SecurityPolicy{}))
+		assert.Equal(t, ErrPlaintext, err)
+
+		c, err := New(plain.URL, WithSecurityPolicy(SecurityPolicy{AllowPlaintext: true}))
+		assert.Nil(t, err)
+		assert.Nil(t, get(c, "/"))
+	})
+
+	t.Run("plaintext request", func(t *testing.T) {
+		c, err := New(ts.URL, WithHTTPClient(ts.Client()), WithSecurityPolicy(SecurityPolicy{}))
+		assert.Nil(t, err)
+		assert.Equal(t, ErrPlaintext, get(c, plain.URL))
+		assert.True(t, errors.Is(get(c, "/redirect"), ErrPlaintext))
+
+		_, err = c.Ping(context.Background(), plain.URL)
+		assert.Equal(t, ErrPlaintext, err)
+	})
+}
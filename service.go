@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// WithServiceOptions is a client option for setting options which only apply to a service
+// (field name, e.g. Node) of a generated client. The generated client uses a copy of the
+// client with these options for the service (see ServiceClient).
+func WithServiceOptions(service string, opts ...Opt) Opt {
+	return func(c *Client) error {
+		if service == "" {
+			return errors.New("service cannot be empty")
+		}
+
+		if c.serviceOpts == nil {
+			c.serviceOpts = map[string][]Opt{}
+		}
+
+		c.serviceOpts[service] = append(c.serviceOpts[service], opts...)
+
+		return nil
+	}
+}
+
+// ServiceClient returns the client for service: c itself or, if options were set for
+// service with WithServiceOptions, a copy of c with these options applied.
+func (c *Client) ServiceClient(service string) (*Client, error) {
+	opts := c.serviceOpts[service]
+	if len(opts) == 0 {
+		return c, nil
+	}
+
+	clone := c.Clone()
+	clone.serviceOpts = nil
+
+	for _, opt := range opts {
+		if err := opt(clone); err != nil {
+			return nil, fmt.Errorf("service %s: %w", service, err)
+		}
+	}
+
+	clone.initHeader()
+
+	return clone, nil
+}
+
+// WithBasePath is a client option for prefixing the path of the request URLs which are not
+// absolute URLs with p, e.g. with p /v2 the URL /posts/1 becomes /v2/posts/1 and the
+// (relative) URL posts/1 becomes v2/posts/1.
+func WithBasePath(p string) Opt {
+	return func(c *Client) error {
+		p = strings.Trim(p, "/")
+		if p == "" {
+			return errors.New("base path cannot be empty")
+		}
+
+		if _, err := url.Parse(p); err != nil {
+			return err
+		}
+
+		c.basePath = p
+		c.absoluteBasePath = false
+
+		return nil
+	}
+}
+
+// WithPathPrefix is a client option for prefixing the path of the request URLs which are not
+// absolute URLs with the absolute path p, e.g. with p /api/v2 the URLs posts/1 and /posts/1 both
+// become /api/v2/posts/1. Unlike WithBasePath, the path of the BaseURL is not used, the prefix
+// cannot be lost by the resolution of the URLs against the BaseURL (see NewRequest).
+func WithPathPrefix(p string) Opt {
+	return func(c *Client) error {
+		if err := WithBasePath(p)(c); err != nil {
+			return err
+		}
+
+		c.absoluteBasePath = true
+
+		return nil
+	}
+}
+
+// WithBaseURL is a client option for setting the base URL, e.g. as service option (see
+// WithServiceOptions) to send the requests of a service to another host. The service shares
+// the HTTP client (transport), the headers and the basic authentication with the client.
+func WithBaseURL(baseURL string) Opt {
+	return func(c *Client) error {
+		if baseURL == "" {
+			return errors.New("base URL cannot be empty")
+		}
+
+		return c.SetBaseURL(baseURL)
+	}
+}
+
+// withBasePath returns rel prefixed with the base path of the client.
+func (c *Client) withBasePath(rel *url.URL) *url.URL {
+	if c.basePath == "" || rel.IsAbs() || rel.Host != "" {
+		return rel
+	}
+
+	u := *rel
+
+	prefix := c.basePath + "/"
+	if c.absoluteBasePath || strings.HasPrefix(rel.Path, "/") {
+		prefix = "/" + prefix
+	}
+
+	u.Path = prefix + strings.TrimLeft(rel.Path, "/")
+
+	if rel.RawPath != "" {
+		u.RawPath = prefix + strings.TrimLeft(rel.RawPath, "/")
+	}
+
+	return &u
+}
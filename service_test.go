@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceClient(t *testing.T) {
+	t.Run("without service options", func(t *testing.T) {
+		c, err := New(baseurl)
+		assert.Nil(t, err)
+
+		s, err := c.ServiceClient("Node")
+		assert.Nil(t, err)
+		assert.True(t, s == c)
+	})
+
+	t.Run("with service options", func(t *testing.T) {
+		c, err := New(baseurl+"/api/",
+			WithServiceOptions("Node", WithContentType(ContentTypeYAML), WithBasePath("v2")),
+			WithServiceOptions("Node", WithHeader(map[string][]string{"X-Service": {"node"}})),
+		)
+		assert.Nil(t, err)
+
+		s, err := c.ServiceClient("Node")
+		assert.Nil(t, err)
+		assert.False(t, s == c)
+		assert.Equal(t, ContentTypeYAML, s.ContentType)
+		assert.Equal(t, "v2", s.basePath)
+		assert.Equal(t, "node", s.header.Get("X-Service"))
+
+		assert.Equal(t, ContentTypeJSON, c.ContentType)
+		assert.Equal(t, "", c.basePath)
+		assert.Nil(t, c.header)
+
+		other, err := c.ServiceClient("Item")
+		assert.Nil(t, err)
+		assert.True(t, other == c)
+	})
+
+	t.Run("service on another host", func(t *testing.T) {
+		c, err := New(baseurl+"/api/",
+			WithUsername(username), WithPassword(password),
+			WithServiceOptions("Search", WithBaseURL("https://search.domain/")),
+		)
+		assert.Nil(t, err)
+
+		s, err := c.ServiceClient("Search")
+		assert.Nil(t, err)
+		assert.True(t, s.client == c.client)
+
+		req, err := s.NewRequest(http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://search.domain/nodes", req.URL.String())
+		assert.NotEmpty(t, req.Header.Get("Authorization"))
+
+		assert.Equal(t, baseurl+"/api/", c.GetBaseURL().String())
+	})
+
+	t.Run("invalid service option", func(t *testing.T) {
+		c, err := New(baseurl, WithServiceOptions("Node", WithContentType("")))
+		assert.Nil(t, err)
+
+		_, err = c.ServiceClient("Node")
+		assert.EqualError(t, err, "service Node: content type cannot be empty")
+	})
+
+	t.Run("empty service", func(t *testing.T) {
+		_, err := New(baseurl, WithServiceOptions(""))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("empty base url", func(t *testing.T) {
+		_, err := New(baseurl, WithBaseURL(""))
+		assert.EqualError(t, err, "base URL cannot be empty")
+	})
+}
+
+func TestWithBasePath(t *testing.T) {
+	tt := []struct {
+		path string
+		url  string
+		want string
+	}{
+		{"v2", "/posts/1", "https://hostname.domain/v2/posts/1"},
+		{"/v2/", "posts/1", "https://hostname.domain/api/v2/posts/1"},
+		{"v2", "https://other.domain/posts", "https://other.domain/posts"},
+		{"v2", "/posts/a%2Fb", "https://hostname.domain/v2/posts/a%2Fb"},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.url, func(t *testing.T) {
+			c, err := New(baseurl+"/api/", WithBasePath(tc.path))
+			assert.Nil(t, err)
+
+			req, err := c.NewRequest(http.MethodGet, tc.url, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, req.URL.String())
+		})
+	}
+
+	t.Run("empty path", func(t *testing.T) {
+		_, err := New(baseurl, WithBasePath("/"))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestWithPathPrefix(t *testing.T) {
+	tt := []struct {
+		url  string
+		want string
+	}{
+		{"posts/1", "https://hostname.domain/api/v2/posts/1"},
+		{"/posts/1", "https://hostname.domain/api/v2/posts/1"},
+		{"posts?page=2", "https://hostname.domain/api/v2/posts?page=2"},
+		{"https://other.domain/posts", "https://other.domain/posts"},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.url, func(t *testing.T) {
+			c, err := New(baseurl+"/ignored/", WithPathPrefix("/api/v2/"))
+			assert.Nil(t, err)
+
+			req, err := c.NewRequest(http.MethodGet, tc.url, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, req.URL.String())
+		})
+	}
+
+	t.Run("base path", func(t *testing.T) {
+		c, err := New(baseurl+"/api/", WithPathPrefix("/v1"), WithBasePath("v2"))
+		assert.Nil(t, err)
+
+		req, err := c.NewRequest(http.MethodGet, "posts", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://hostname.domain/api/v2/posts", req.URL.String())
+	})
+
+	t.Run("empty prefix", func(t *testing.T) {
+		_, err := New(baseurl, WithPathPrefix(""))
+		assert.EqualError(t, err, "base path cannot be empty")
+	})
+}
@@ -0,0 +1,234 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WithSingleflight is a client option for coalescing concurrent identical GET requests (same
+// URL and headers): only one of them is sent and its response is shared by all callers, each
+// decoding the body into its own value. This protects the server from thundering herds on hot
+// resources. The request is sent with the values but without the cancellation of the context of
+// the first caller: a caller whose context is done returns its error, the request is only
+// canceled when all callers returned.
+func WithSingleflight() Opt {
+	return func(c *Client) error {
+		c.group = &flightGroup{flights: map[string]*flight{}}
+		return nil
+	}
+}
+
+// flightGroup are the coalesced requests in flight.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a request in flight, whose response is shared by its callers.
+type flight struct {
+	key    string
+	done   chan struct{}
+	shared *sharedResponse
+	err    error
+
+	// guarded by the mutex of the group
+	callers int
+	landed  bool
+	cancel  context.CancelFunc
+}
+
+// join joins the flight of key and reports whether the caller is the first one, which sends
+// the request and lands the flight. The flight is canceled with cancel if all callers leave
+// before it landed.
+func (g *flightGroup) join(key string, cancel context.CancelFunc) (*flight, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if f, ok := g.flights[key]; ok {
+		f.callers++
+		return f, false
+	}
+
+	f := &flight{key: key, done: make(chan struct{}), callers: 1, cancel: cancel}
+	g.flights[key] = f
+
+	return f, true
+}
+
+// land sets the response of the flight f, later callers of its key start a new flight.
+func (g *flightGroup) land(f *flight, shared *sharedResponse, err error) {
+	g.mu.Lock()
+	g.remove(f)
+	f.shared, f.err = shared, err
+	f.landed = true
+	left := f.callers == 0
+	g.mu.Unlock()
+
+	close(f.done)
+
+	if left {
+		shared.release()
+	}
+}
+
+// leave leaves the flight f. The memory of the response body is released when the last caller
+// left, the flight is canceled if it has not landed yet.
+func (g *flightGroup) leave(f *flight) {
+	g.mu.Lock()
+	f.callers--
+	last, landed := f.callers == 0, f.landed
+
+	if last && !landed {
+		g.remove(f)
+	}
+
+	g.mu.Unlock()
+
+	switch {
+	case last && landed:
+		f.shared.release()
+	case last:
+		f.cancel()
+	}
+}
+
+// remove removes the flight f, so later callers of its key start a new flight.
+func (g *flightGroup) remove(f *flight) {
+	if g.flights[f.key] == f {
+		delete(g.flights, f.key)
+	}
+}
+
+// sharedResponse is the response of coalesced requests with the buffered body.
+type sharedResponse struct {
+	resp *http.Response
+	body []byte
+	buf  *bodyBuffer
+}
+
+// release releases the memory of the buffered body (see WithMemoryBudget), once all callers
+// decoded it.
+func (s *sharedResponse) release() {
+	if s != nil && s.buf != nil {
+		s.buf.release()
+	}
+}
+
+// doShared sends the GET request req unless its response is cached (see WithCache) or an
+// identical request is in flight and decodes the (shared) response body into v.
+func (c *Client) doShared(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
+	key := requestKey(req)
+
+	if shared, ok := c.cache.get(key, c.Now()); ok {
+		resp := *shared.resp
+		resp.Request = req.WithContext(ctx)
+
+		return c.reply(&sharedResponse{resp: &resp, body: shared.body}, nil, v)
+	}
+
+	var (
+		shared *sharedResponse
+		err    error
+	)
+
+	if c.group != nil {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+		f, first := c.group.join(key, cancel)
+		if first {
+			go func() {
+				defer cancel()
+
+				shared, err := c.sendBuffered(fctx, req)
+				c.group.land(f, shared, err)
+			}()
+		} else {
+			cancel()
+		}
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			c.group.leave(f)
+			return nil, ctx.Err()
+		}
+
+		defer c.group.leave(f)
+
+		shared, err = f.shared, f.err
+	} else {
+		shared, err = c.sendBuffered(ctx, req)
+
+		defer shared.release()
+	}
+
+	if shared == nil {
+		return nil, err
+	}
+
+	if err == nil {
+		c.cache.put(key, req.URL, shared, c.Now())
+	}
+
+	return c.reply(shared, err, v)
+}
+
+// sendBuffered sends the request req and returns the response with the buffered body, which
+// has to be released.
+func (c *Client) sendBuffered(ctx context.Context, req *http.Request) (*sharedResponse, error) {
+	resp, err := c.send(ctx, req)
+	if resp == nil {
+		return nil, err
+	}
+
+	buf := &bodyBuffer{budget: c.budget}
+
+	if resp.Body != nil {
+		_, rerr := io.Copy(buf, resp.Body)
+		_ = resp.Body.Close()
+
+		if err == nil {
+			err = rerr
+		}
+	}
+
+	return &sharedResponse{resp: resp, body: buf.Bytes(), buf: buf}, err
+}
+
+// reply returns a copy of the shared response with the body decoded into v.
+func (c *Client) reply(shared *sharedResponse, err error, v interface{}) (*http.Response, error) {
+	resp := *shared.resp
+	resp.Header = shared.resp.Header.Clone()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(shared.body))
+
+	if err != nil {
+		return &resp, err
+	}
+
+	return &resp, c.Unmarshal(&resp, v)
+}
+
+// requestKey returns the key of identical requests.
+func requestKey(req *http.Request) string {
+	b := new(strings.Builder)
+	b.WriteString(req.Method + " " + req.URL.String() + "\n")
+
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		b.WriteString(k + ": " + strings.Join(req.Header[k], ", ") + "\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleflight(t *testing.T) {
+	var hits int32
+
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_ = json.NewEncoder(w).Encode(message{Text: r.URL.Path})
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithSingleflight())
+	assert.Nil(t, err)
+
+	run := func(n int, path string) ([]*message, []error) {
+		msgs, errs := make([]*message, n), make([]error, n)
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				req, err := c.NewRequest(http.MethodGet, path, nil)
+				assert.Nil(t, err)
+
+				msgs[i] = &message{}
+				_, errs[i] = c.Do(context.Background(), req, msgs[i])
+			}(i)
+		}
+
+		time.Sleep(100 * time.Millisecond) // let all callers join
+
+		release <- struct{}{}
+
+		wg.Wait()
+
+		return msgs, errs
+	}
+
+	t.Run("coalesced", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		msgs, errs := run(5, "/nodes/1")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+		for i := range msgs {
+			assert.Nil(t, errs[i])
+			assert.Equal(t, "/nodes/1", msgs[i].Text)
+		}
+
+		assert.False(t, msgs[0] == msgs[1])
+	})
+
+	t.Run("shared error", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		_, errs := run(3, "/error")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+		for _, err := range errs {
+			assert.EqualError(t, err, "404 Not Found")
+		}
+	})
+
+	t.Run("canceled caller", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+
+		go func() {
+			req, err := c.NewRequest(http.MethodGet, "/nodes/2", nil)
+			assert.Nil(t, err)
+
+			_, err = c.Do(ctx, req, &message{})
+			first <- err
+		}()
+
+		assert.Eventually(t, func() bool { return atomic.LoadInt32(&hits) == 1 }, time.Second, time.Millisecond)
+
+		wg := sync.WaitGroup{}
+		msgs := make([]message, 2)
+
+		for i := range msgs {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				req, err := c.NewRequest(http.MethodGet, "/nodes/2", nil)
+				assert.Nil(t, err)
+
+				_, err = c.Do(context.Background(), req, &msgs[i])
+				assert.Nil(t, err)
+			}(i)
+		}
+
+		time.Sleep(100 * time.Millisecond) // let the other callers join
+
+		cancel()
+		assert.Equal(t, context.Canceled, <-first)
+
+		release <- struct{}{}
+
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+		assert.Equal(t, []message{{Text: "/nodes/2"}, {Text: "/nodes/2"}}, msgs)
+	})
+
+	t.Run("not coalesced", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		close(release)
+
+		req, err := c.NewRequest(http.MethodPost, "/nodes", nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, &message{})
+		assert.Nil(t, err)
+
+		req, err = c.NewRequest(http.MethodGet, "/nodes/1", nil)
+		assert.Nil(t, err)
+
+		req.Header.Set("X-Tenant", "other")
+
+		_, err = c.Do(context.Background(), req, &message{})
+		assert.Nil(t, err)
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+	})
+
+	t.Run("key", func(t *testing.T) {
+		a, _ := c.NewRequest(http.MethodGet, "/nodes/1", nil)
+		b, _ := c.NewRequest(http.MethodGet, "/nodes/1", nil)
+		assert.Equal(t, requestKey(a), requestKey(b))
+
+		b.Header.Set("Accept", ContentTypeYAML)
+		assert.NotEqual(t, requestKey(a), requestKey(b))
+	})
+}
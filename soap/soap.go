@@ -0,0 +1,290 @@
+// Package soap sends SOAP 1.1 and 1.2 requests with an httpclient.Client, for the legacy
+// services which are not available as REST APIs:
+//
+//	c, err := httpclient.New("https://legacy.example.com/")
+//	if err != nil {
+//		return err
+//	}
+//
+//	s := soap.New(c, "services/Orders", soap.Version11)
+//
+//	var resp GetOrderResponse
+//	if err := s.Call(ctx, "urn:GetOrder", GetOrderRequest{ID: 1}, &resp); err != nil {
+//		var fault *soap.Fault
+//		if errors.As(err, &fault) {
+//			...
+//		}
+//		return err
+//	}
+//
+// The request and response bodies are encoded with encoding/xml. The authentication, retry,
+// rate limit and other settings of the httpclient.Client apply to the calls.
+package soap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/postfinance/httpclient"
+)
+
+// Version is the SOAP version.
+type Version int
+
+// The SOAP versions.
+const (
+	Version11 Version = iota
+	Version12
+)
+
+// Namespaces of the SOAP envelopes.
+const (
+	Namespace11 = "http://schemas.xmlsoap.org/soap/envelope/"
+	Namespace12 = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// Client sends SOAP requests to an endpoint.
+type Client struct {
+	client   *httpclient.Client
+	endpoint string
+	version  Version
+
+	// Header is encoded into the header of the request envelopes (e.g. WS-Security
+	// credentials), if it is not nil.
+	Header interface{}
+}
+
+// New returns a client for the SOAP endpoint (relative to the BaseURL of c, see
+// httpclient.Client.NewRequest) with the SOAP version. The client uses a clone of c with
+// FaultCallback, so its ResponseCallback should be set before.
+func New(c *httpclient.Client, endpoint string, version Version) *Client {
+	clone := c.Clone()
+	clone.ResponseCallback = FaultCallback(clone.ResponseCallback)
+
+	return &Client{
+		client:   clone,
+		endpoint: endpoint,
+		version:  version,
+	}
+}
+
+// Call sends the SOAP request with the action and the body in and decodes the body of the
+// response into out, which is skipped if it is nil. A fault is returned as *Fault.
+func (c *Client) Call(ctx context.Context, action string, in, out interface{}) error {
+	body, err := c.envelope(in)
+	if err != nil {
+		return err
+	}
+
+	opts := []httpclient.RequestOpt{httpclient.RawBody(body, c.contentType(action))}
+
+	if c.version == Version11 {
+		opts = append(opts,
+			httpclient.SetHeader("SOAPAction", `"`+action+`"`),
+			httpclient.SetHeader("Accept", "text/xml"),
+		)
+	} else {
+		opts = append(opts, httpclient.SetHeader("Accept", "application/soap+xml"))
+	}
+
+	req, err := c.client.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+
+	if _, err := c.client.Do(ctx, req, buf); err != nil {
+		return err
+	}
+
+	env, err := decodeEnvelope(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if env.Body.Fault != nil {
+		return env.Body.Fault.fault(nil)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := xml.Unmarshal(env.Body.Content, out); err != nil {
+		return fmt.Errorf("decode soap body: %w", err)
+	}
+
+	return nil
+}
+
+// contentType returns the content type of the requests with the action.
+func (c *Client) contentType(action string) string {
+	if c.version == Version11 {
+		return "text/xml; charset=utf-8"
+	}
+
+	return fmt.Sprintf("application/soap+xml; charset=utf-8; action=%q", action)
+}
+
+// envelope returns the request envelope with the body in.
+func (c *Client) envelope(in interface{}) ([]byte, error) {
+	ns := Namespace11
+	if c.version == Version12 {
+		ns = Namespace12
+	}
+
+	buf := &bytes.Buffer{}
+
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(buf, `<soap:Envelope xmlns:soap="%s">`, ns)
+
+	if c.Header != nil {
+		buf.WriteString("<soap:Header>")
+
+		if err := xml.NewEncoder(buf).Encode(c.Header); err != nil {
+			return nil, fmt.Errorf("encode soap header: %w", err)
+		}
+
+		buf.WriteString("</soap:Header>")
+	}
+
+	buf.WriteString("<soap:Body>")
+
+	if in != nil {
+		if err := xml.NewEncoder(buf).Encode(in); err != nil {
+			return nil, fmt.Errorf("encode soap body: %w", err)
+		}
+	}
+
+	buf.WriteString("</soap:Body></soap:Envelope>")
+
+	return buf.Bytes(), nil
+}
+
+// Fault is the error returned for SOAP faults. The fields are set from the faultcode,
+// faultstring, faultactor and detail elements of SOAP 1.1 faults or the Code, Reason, Role and
+// Detail elements of SOAP 1.2 faults.
+type Fault struct {
+	Code    string
+	Subcode string // SOAP 1.2 only
+	String  string
+	Actor   string
+	Detail  []byte // the inner XML of the detail element
+	err     error
+}
+
+// Error implements the error interface.
+func (f *Fault) Error() string {
+	return fmt.Sprintf("soap fault %s: %s", f.Code, f.String)
+}
+
+// Unwrap returns the error of the next ResponseCallbackFunc (e.g. *httpclient.HTTPError), nil
+// if the fault was sent with a successful status code.
+func (f *Fault) Unwrap() error {
+	return f.err
+}
+
+// DecodeDetail decodes the detail of the fault into v.
+func (f *Fault) DecodeDetail(v interface{}) error {
+	return xml.Unmarshal(f.Detail, v)
+}
+
+// FaultCallback returns a ResponseCallbackFunc which decodes the SOAP faults of error responses
+// (see next) into a *Fault.
+func FaultCallback(next httpclient.ResponseCallbackFunc) httpclient.ResponseCallbackFunc {
+	return func(r *http.Response) (*http.Response, error) {
+		r, err := next(r)
+		if err == nil || r == nil || r.Body == nil {
+			return r, err
+		}
+
+		buf := &bytes.Buffer{}
+		if _, rerr := buf.ReadFrom(r.Body); rerr != nil {
+			return r, err
+		}
+
+		env, derr := decodeEnvelope(buf.Bytes())
+		if derr != nil || env.Body.Fault == nil {
+			return r, err
+		}
+
+		return r, env.Body.Fault.fault(err)
+	}
+}
+
+// envelope is a SOAP 1.1 or 1.2 response envelope.
+type envelope struct {
+	Body struct {
+		Content []byte `xml:",innerxml"`
+		Fault   *fault `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// decodeEnvelope decodes the response envelope data.
+func decodeEnvelope(data []byte) (*envelope, error) {
+	env := &envelope{}
+
+	if err := xml.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("decode soap envelope: %w", err)
+	}
+
+	return env, nil
+}
+
+// fault is a SOAP 1.1 or 1.2 fault element.
+type fault struct {
+	// SOAP 1.1
+	FaultCode   string   `xml:"faultcode"`
+	FaultString string   `xml:"faultstring"`
+	FaultActor  string   `xml:"faultactor"`
+	Detail11    innerXML `xml:"detail"`
+
+	// SOAP 1.2
+	Code struct {
+		Value   string `xml:"Value"`
+		Subcode struct {
+			Value string `xml:"Value"`
+		} `xml:"Subcode"`
+	} `xml:"Code"`
+	Reason struct {
+		Text []string `xml:"Text"`
+	} `xml:"Reason"`
+	Role     string   `xml:"Role"`
+	Detail12 innerXML `xml:"Detail"`
+}
+
+// innerXML is the inner XML of an element.
+type innerXML struct {
+	Content []byte `xml:",innerxml"`
+}
+
+// fault returns the Fault of f with the error err of the next ResponseCallbackFunc.
+func (f *fault) fault(err error) *Fault {
+	if f.FaultCode != "" || f.FaultString != "" {
+		return &Fault{
+			Code:   f.FaultCode,
+			String: f.FaultString,
+			Actor:  f.FaultActor,
+			Detail: bytes.TrimSpace(f.Detail11.Content),
+			err:    err,
+		}
+	}
+
+	reason := ""
+	if len(f.Reason.Text) > 0 {
+		reason = f.Reason.Text[0]
+	}
+
+	return &Fault{
+		Code:    f.Code.Value,
+		Subcode: f.Code.Subcode.Value,
+		String:  reason,
+		Actor:   f.Role,
+		Detail:  bytes.TrimSpace(f.Detail12.Content),
+		err:     err,
+	}
+}
@@ -0,0 +1,173 @@
+package soap
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/postfinance/httpclient"
+	"github.com/stretchr/testify/assert"
+)
+
+type getOrder struct {
+	XMLName xml.Name `xml:"urn:orders GetOrder"`
+	ID      int      `xml:"ID"`
+}
+
+type getOrderResponse struct {
+	XMLName xml.Name `xml:"urn:orders GetOrderResponse"`
+	Total   float64  `xml:"Total"`
+}
+
+type authHeader struct {
+	XMLName xml.Name `xml:"urn:auth Auth"`
+	Token   string   `xml:"Token"`
+}
+
+const (
+	response11 = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <o:GetOrderResponse xmlns:o="urn:orders"><o:Total>30.5</o:Total></o:GetOrderResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+	fault11 = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>unknown order</faultstring>
+      <detail><OrderFault><ID>2</ID></OrderFault></detail>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+	fault12 = `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+  <env:Body>
+    <env:Fault>
+      <env:Code><env:Value>env:Sender</env:Value><env:Subcode><env:Value>o:UnknownOrder</env:Value></env:Subcode></env:Code>
+      <env:Reason><env:Text xml:lang="en">unknown order</env:Text></env:Reason>
+      <env:Detail><OrderFault><ID>2</ID></OrderFault></env:Detail>
+    </env:Fault>
+  </env:Body>
+</env:Envelope>`
+)
+
+func TestCall(t *testing.T) {
+	var (
+		header http.Header
+		body   string
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		header, body = r.Header, string(b)
+
+		switch r.URL.Path {
+		case "/orders":
+			_, _ = w.Write([]byte(response11))
+		case "/fault11":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(fault11))
+		case "/fault12":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(fault12))
+		case "/fault200":
+			_, _ = w.Write([]byte(fault11))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := httpclient.New(ts.URL, httpclient.WithUsername("cognitive"), httpclient.WithPassword("distortions"))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("SOAP 1.1", func(t *testing.T) {
+		s := New(c, "orders", Version11)
+		s.Header = authHeader{Token: "secret"}
+
+		var resp getOrderResponse
+
+		assert.Nil(t, s.Call(ctx, "urn:GetOrder", getOrder{ID: 1}, &resp))
+		assert.Equal(t, 30.5, resp.Total)
+		assert.Equal(t, `"urn:GetOrder"`, header.Get("SOAPAction"))
+		assert.Equal(t, "text/xml; charset=utf-8", header.Get("Content-Type"))
+		assert.Equal(t, "text/xml", header.Get("Accept"))
+		assert.Equal(t, xml.Header+`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">`+
+			`<soap:Header><Auth xmlns="urn:auth"><Token>secret</Token></Auth></soap:Header>`+
+			`<soap:Body><GetOrder xmlns="urn:orders"><ID>1</ID></GetOrder></soap:Body></soap:Envelope>`, body)
+
+		user, password, ok := (&http.Request{Header: header}).BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "cognitive", user)
+		assert.Equal(t, "distortions", password)
+	})
+
+	t.Run("SOAP 1.2", func(t *testing.T) {
+		s := New(c, "orders", Version12)
+
+		assert.Nil(t, s.Call(ctx, "urn:GetOrder", getOrder{ID: 1}, nil))
+		assert.Equal(t, "", header.Get("SOAPAction"))
+		assert.Equal(t, `application/soap+xml; charset=utf-8; action="urn:GetOrder"`, header.Get("Content-Type"))
+		assert.Contains(t, body, `<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body>`)
+	})
+
+	t.Run("faults", func(t *testing.T) {
+		type orderFault struct {
+			ID int `xml:"ID"`
+		}
+
+		for _, tc := range []struct {
+			endpoint string
+			version  Version
+			expected Fault
+		}{
+			{"fault11", Version11, Fault{Code: "soap:Client", String: "unknown order", Detail: []byte("<OrderFault><ID>2</ID></OrderFault>")}},
+			{"fault12", Version12, Fault{Code: "env:Sender", Subcode: "o:UnknownOrder", String: "unknown order", Detail: []byte("<OrderFault><ID>2</ID></OrderFault>")}},
+		} {
+			t.Run(tc.endpoint, func(t *testing.T) {
+				err := New(c, tc.endpoint, tc.version).Call(ctx, "urn:GetOrder", getOrder{ID: 2}, nil)
+
+				var f *Fault
+
+				assert.True(t, errors.As(err, &f))
+				assert.Equal(t, tc.expected.Code, f.Code)
+				assert.Equal(t, tc.expected.Subcode, f.Subcode)
+				assert.Equal(t, tc.expected.String, f.String)
+				assert.Equal(t, tc.expected.Detail, f.Detail)
+				assert.EqualError(t, err, "soap fault "+tc.expected.Code+": unknown order")
+
+				var httpErr *httpclient.HTTPError
+
+				assert.True(t, errors.As(err, &httpErr))
+				assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+
+				var detail orderFault
+
+				assert.Nil(t, f.DecodeDetail(&detail))
+				assert.Equal(t, 2, detail.ID)
+			})
+		}
+	})
+
+	t.Run("fault with status OK", func(t *testing.T) {
+		err := New(c, "fault200", Version11).Call(ctx, "urn:GetOrder", getOrder{ID: 2}, nil)
+
+		var f *Fault
+
+		assert.True(t, errors.As(err, &f))
+		assert.Nil(t, f.Unwrap())
+	})
+
+	t.Run("no fault", func(t *testing.T) {
+		err := New(c, "missing", Version11).Call(ctx, "urn:GetOrder", getOrder{ID: 2}, nil)
+		assert.EqualError(t, err, "404 Not Found")
+	})
+}
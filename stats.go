@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Stats are the counters of a client since it was created (see Client.Stats), e.g. for debug
+// endpoints and tests.
+type Stats struct {
+	// Requests is the number of requests sent and Inflight the number of requests waiting for
+	// the response headers.
+	Requests int64
+	Inflight int64
+
+	// Responses by status class, before the ResponseCallback.
+	Responses1xx int64
+	Responses2xx int64
+	Responses3xx int64
+	Responses4xx int64
+	Responses5xx int64
+
+	// Requests failed without response by category (see IsTimeout, IsConnectionRefused and
+	// IsDNSError), Errors are the other failures.
+	Timeouts          int64
+	ConnectionRefused int64
+	DNSErrors         int64
+	Errors            int64
+
+	// Retries is the number of attempts of Retry after the first one.
+	Retries int64
+
+	// Bytes transferred (see TransferStats).
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// statsCounter counts the Stats atomically.
+type statsCounter struct {
+	inflight          int64
+	responses         [5]int64
+	timeouts          int64
+	connectionRefused int64
+	dnsErrors         int64
+	errors            int64
+	retries           int64
+}
+
+// Stats returns a snapshot of the Stats of the requests sent by c with Do and the functions
+// using it. Copies of the client (see Clone) share the statistics.
+func (c *Client) Stats() Stats {
+	t := c.TransferStats()
+	s := Stats{
+		Requests:      t.Requests,
+		BytesSent:     t.BytesSent,
+		BytesReceived: t.BytesReceived,
+	}
+
+	if c.stats == nil {
+		return s
+	}
+
+	s.Inflight = atomic.LoadInt64(&c.stats.inflight)
+	s.Responses1xx = atomic.LoadInt64(&c.stats.responses[0])
+	s.Responses2xx = atomic.LoadInt64(&c.stats.responses[1])
+	s.Responses3xx = atomic.LoadInt64(&c.stats.responses[2])
+	s.Responses4xx = atomic.LoadInt64(&c.stats.responses[3])
+	s.Responses5xx = atomic.LoadInt64(&c.stats.responses[4])
+	s.Timeouts = atomic.LoadInt64(&c.stats.timeouts)
+	s.ConnectionRefused = atomic.LoadInt64(&c.stats.connectionRefused)
+	s.DNSErrors = atomic.LoadInt64(&c.stats.dnsErrors)
+	s.Errors = atomic.LoadInt64(&c.stats.errors)
+	s.Retries = atomic.LoadInt64(&c.stats.retries)
+
+	return s
+}
+
+// begin counts a request in flight.
+func (s *statsCounter) begin() {
+	if s != nil {
+		atomic.AddInt64(&s.inflight, 1)
+	}
+}
+
+// end counts the response resp or the error err of a request in flight.
+func (s *statsCounter) end(resp *http.Response, err error) {
+	if s == nil {
+		return
+	}
+
+	atomic.AddInt64(&s.inflight, -1)
+
+	switch {
+	case err == nil && resp != nil:
+		if class := resp.StatusCode/100 - 1; class >= 0 && class < len(s.responses) {
+			atomic.AddInt64(&s.responses[class], 1)
+		}
+	case IsTimeout(err):
+		atomic.AddInt64(&s.timeouts, 1)
+	case IsConnectionRefused(err):
+		atomic.AddInt64(&s.connectionRefused, 1)
+	case IsDNSError(err):
+		atomic.AddInt64(&s.dnsErrors, 1)
+	default:
+		atomic.AddInt64(&s.errors, 1)
+	}
+}
+
+// retry counts a retry.
+func (s *statsCounter) retry() {
+	if s != nil {
+		atomic.AddInt64(&s.retries, 1)
+	}
+}
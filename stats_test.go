@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			_, _ = w.Write([]byte("hello"))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	assert.Nil(t, err)
+
+	get := func(c *Client, ctx context.Context, path string) error {
+		req, err := c.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		assert.Nil(t, err)
+
+		_, err = c.Do(ctx, req, nil)
+
+		return err
+	}
+
+	t.Run("responses", func(t *testing.T) {
+		assert.Nil(t, get(c, context.Background(), "/"))
+		assert.NotNil(t, get(c.Clone(), context.Background(), "/missing"))
+
+		_, err := c.Retry(context.Background(), func(ctx context.Context) (*http.Response, error) {
+			return nil, get(c, ctx, "/unavailable")
+		})
+		assert.NotNil(t, err)
+
+		s := c.Stats()
+		assert.Equal(t, int64(5), s.Requests)
+		assert.Equal(t, int64(1), s.Responses2xx)
+		assert.Equal(t, int64(1), s.Responses4xx)
+		assert.Equal(t, int64(3), s.Responses5xx)
+		assert.Equal(t, int64(2), s.Retries)
+		assert.Equal(t, int64(0), s.Inflight)
+		assert.Equal(t, int64(0), s.Errors)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.NotNil(t, get(c, ctx, "/slow"))
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		assert.Nil(t, l.Close())
+
+		assert.NotNil(t, get(c, context.Background(), "http://"+l.Addr().String()))
+
+		s := c.Stats()
+		assert.Equal(t, int64(1), s.Timeouts)
+		assert.Equal(t, int64(1), s.ConnectionRefused)
+		assert.Equal(t, int64(0), s.Inflight)
+	})
+
+	t.Run("inflight", func(t *testing.T) {
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			_ = get(c, context.Background(), "/slow")
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, int64(1), c.Stats().Inflight)
+
+		<-done
+		assert.Equal(t, int64(0), c.Stats().Inflight)
+		assert.Equal(t, int64(5), c.Stats().BytesReceived)
+	})
+}
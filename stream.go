@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+)
+
+// WithStreaming is a client option for streaming the output of the marshaler into the request
+// body via io.Pipe instead of buffering it, e.g. for large JSON or NDJSON payloads. Bodies of
+// up to threshold bytes are still buffered and sent with a Content-Length. Streamed bodies are
+// sent with the ContentType of the client as Content-Type, marshaling errors abort the request.
+// The requests must be sent (or their bodies closed), otherwise the marshaler blocks forever.
+func WithStreaming(threshold int) Opt {
+	return func(c *Client) error {
+		if threshold < 0 {
+			return errors.New("stream threshold cannot be negative")
+		}
+
+		c.streamThreshold = threshold
+		c.streaming = true
+
+		return nil
+	}
+}
+
+// streamBody runs the marshaler m for v in a goroutine and returns the request body and its
+// content type: the buffered output, if it is not longer than threshold, or a reader streaming
+// the output.
+func streamBody(ctx context.Context, m MarshalerContextFunc, v interface{}, mediaType string, threshold int) (io.Reader, string, error) {
+	pr, pw := io.Pipe()
+	done := make(chan string, 1)
+
+	go func() {
+		contentType, err := m(ctx, pw, v, mediaType)
+		done <- contentType
+
+		pw.CloseWithError(err)
+	}()
+
+	buf := make([]byte, threshold+1)
+
+	n, err := io.ReadFull(pr, buf)
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		return bytes.NewReader(buf[:n]), <-done, nil
+	case err != nil:
+		return nil, "", err
+	}
+
+	return &pipeBody{
+		Reader: io.MultiReader(bytes.NewReader(buf), pr),
+		pipe:   pr,
+	}, mediaType, nil
+}
+
+// pipeBody is a streamed request body, closing it stops the marshaler.
+type pipeBody struct {
+	io.Reader
+	pipe *io.PipeReader
+}
+
+// Close closes the pipe.
+func (b *pipeBody) Close() error {
+	return b.pipe.Close()
+}
@@ -0,0 +1,94 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreaming(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, "%d %v %d", r.ContentLength, r.TransferEncoding, len(b))
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithStreaming(64))
+	assert.Nil(t, err)
+
+	c.ContentType = ContentTypeText
+	ctx := context.Background()
+
+	t.Run("small body", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodPost, "nodes", "small")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), req.ContentLength)
+
+		act := ""
+		_, err = c.Do(ctx, req, &act)
+		assert.Nil(t, err)
+		assert.Equal(t, "5 [] 5", act)
+	})
+
+	t.Run("large body", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodPost, "nodes", strings.Repeat("x", 1000))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), req.ContentLength)
+
+		act := ""
+		_, err = c.Do(ctx, req, &act)
+		assert.Nil(t, err)
+		assert.Equal(t, "-1 [chunked] 1000", act)
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(ctx, http.MethodGet, "nodes", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), req.ContentLength)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		c := c.Clone()
+		c.Marshaler = func(w io.Writer, v interface{}, mediaType string) (string, error) {
+			_, _ = w.Write([]byte("partial"))
+			return mediaType, errors.New("marshal failed")
+		}
+
+		_, err := c.NewRequestWithContext(ctx, http.MethodPost, "nodes", "body")
+		assert.EqualError(t, err, "marshal failed")
+	})
+
+	t.Run("close stops marshaler", func(t *testing.T) {
+		done := make(chan error)
+
+		c := c.Clone()
+		c.Marshaler = func(w io.Writer, v interface{}, mediaType string) (string, error) {
+			var err error
+			for err == nil {
+				_, err = w.Write(make([]byte, 32))
+			}
+
+			done <- err
+
+			return mediaType, err
+		}
+
+		req, err := c.NewRequestWithContext(ctx, http.MethodPost, "nodes", "body")
+		assert.Nil(t, err)
+		assert.Nil(t, req.Body.Close())
+		assert.Equal(t, io.ErrClosedPipe, <-done)
+	})
+
+	t.Run("negative threshold", func(t *testing.T) {
+		_, err := New(ts.URL, WithStreaming(-1))
+		assert.NotNil(t, err)
+	})
+}
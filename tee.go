@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// teeKey is the context key of the response tee writers of a request.
+type teeKey struct{}
+
+// WithResponseTee is a client option for writing the raw response bodies to the writers w (e.g.
+// a file, a hash or a logger) while they are read, e.g. by the Unmarshaler, without buffering
+// them. The writers are shared by the requests of the client and must be safe for concurrent
+// use. An error of a writer is returned by the Read of the body. Bodies not read to the end are
+// only written partially.
+func WithResponseTee(w ...io.Writer) Opt {
+	return func(c *Client) error {
+		for _, tw := range w {
+			if tw == nil {
+				return errors.New("response tee writer cannot be nil")
+			}
+		}
+
+		c.tees = append(append([]io.Writer(nil), c.tees...), w...)
+
+		return nil
+	}
+}
+
+// ResponseTee is a request option for writing the raw response body of the request to the
+// writers w (see WithResponseTee).
+func ResponseTee(w ...io.Writer) RequestOpt {
+	return func(r *http.Request) error {
+		for _, tw := range w {
+			if tw == nil {
+				return errors.New("response tee writer cannot be nil")
+			}
+		}
+
+		tees, _ := r.Context().Value(teeKey{}).([]io.Writer)
+		tees = append(append([]io.Writer(nil), tees...), w...)
+		*r = *r.WithContext(context.WithValue(r.Context(), teeKey{}, tees))
+
+		return nil
+	}
+}
+
+// teeBody is a response body writing the bytes read to a writer.
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+// responseTees returns the response tee writers of the client and the request req.
+func (c *Client) responseTees(req *http.Request) []io.Writer {
+	tees, _ := req.Context().Value(teeKey{}).([]io.Writer)
+	if len(tees) == 0 {
+		return c.tees
+	}
+
+	return append(append([]io.Writer(nil), c.tees...), tees...)
+}
@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("sink failed")
+}
+
+func TestResponseTee(t *testing.T) {
+	body := `{"Text":"hello"}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	clientSink := new(bytes.Buffer)
+
+	c, err := New(ts.URL, WithResponseTee(clientSink))
+	assert.Nil(t, err)
+
+	t.Run("client and request", func(t *testing.T) {
+		h := sha256.New()
+
+		req, err := c.NewRequestWithContext(context.Background(), http.MethodGet, "", nil, ResponseTee(h))
+		assert.Nil(t, err)
+
+		m := message{}
+		_, err = c.Do(context.Background(), req, &m)
+		assert.Nil(t, err)
+		assert.Equal(t, "hello", m.Text)
+
+		sum := sha256.Sum256([]byte(body))
+		assert.Equal(t, hex.EncodeToString(sum[:]), hex.EncodeToString(h.Sum(nil)))
+		assert.Equal(t, body, clientSink.String())
+	})
+
+	t.Run("failing sink", func(t *testing.T) {
+		req, err := c.NewRequestWithContext(context.Background(), http.MethodGet, "", nil, ResponseTee(failingWriter{}))
+		assert.Nil(t, err)
+
+		_, err = c.Do(context.Background(), req, &message{})
+		assert.EqualError(t, err, "sink failed")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := New(ts.URL, WithResponseTee(nil))
+		assert.NotNil(t, err)
+
+		_, err = c.NewRequestWithContext(context.Background(), http.MethodGet, "", nil, ResponseTee(nil))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// WithTokenSource is a client option for authenticating the requests with the OAuth2 bearer
+// tokens of ts, e.g. of a clientcredentials.Config. NewRequest sets the Authorization header to
+// the current token, which is cached and refreshed by ts when it expires (see
+// oauth2.ReuseTokenSource). The token takes precedence over the basic authentication.
+func WithTokenSource(ts oauth2.TokenSource) Opt {
+	return func(c *Client) error {
+		if ts == nil {
+			return errors.New("token source cannot be nil")
+		}
+
+		c.tokenSource = oauth2.ReuseTokenSource(nil, ts)
+
+		return nil
+	}
+}
+
+// setToken sets the Authorization header of req to the token of the token source of the client.
+func (c *Client) setToken(req *http.Request) error {
+	if c.tokenSource == nil {
+		return nil
+	}
+
+	t, err := c.tokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("token source: %w", err)
+	}
+
+	t.SetAuthHeader(req)
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type countingTokenSource struct {
+	n      int
+	expiry time.Duration
+	err    error
+}
+
+func (s *countingTokenSource) Token() (*oauth2.Token, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	s.n++
+
+	return &oauth2.Token{
+		AccessToken: fmt.Sprintf("token%d", s.n),
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(s.expiry),
+	}, nil
+}
+
+func TestWithTokenSource(t *testing.T) {
+	t.Run("cached", func(t *testing.T) {
+		ts := &countingTokenSource{expiry: time.Hour}
+
+		c, err := New(baseurl, WithBasicAuth(username, password), WithTokenSource(ts))
+		assert.Nil(t, err)
+
+		for i := 0; i < 2; i++ {
+			req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+			assert.Nil(t, err)
+			assert.Equal(t, "Bearer token1", req.Header.Get("Authorization"))
+		}
+	})
+
+	t.Run("refreshed", func(t *testing.T) {
+		ts := &countingTokenSource{expiry: time.Second} // expires within the expiry delta
+
+		c, err := New(baseurl, WithTokenSource(ts))
+		assert.Nil(t, err)
+
+		for i := 1; i <= 2; i++ {
+			req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+			assert.Nil(t, err)
+			assert.Equal(t, fmt.Sprintf("Bearer token%d", i), req.Header.Get("Authorization"))
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errToken := errors.New("invalid client")
+
+		c, err := New(baseurl, WithTokenSource(&countingTokenSource{err: errToken}))
+		assert.Nil(t, err)
+
+		_, err = c.NewRequest(http.MethodGet, "nodes", nil)
+		assert.True(t, errors.Is(err, errToken))
+		assert.EqualError(t, err, "token source: invalid client")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := New(baseurl, WithTokenSource(nil))
+		assert.NotNil(t, err)
+	})
+}
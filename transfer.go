@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// TransferStats are the number of requests and bytes transferred by a client (see
+// Client.TransferStats), e.g. for the cost accounting of metered APIs.
+//
+// The bytes are the bodies of the requests and responses as sent and received by the HTTP
+// client, without headers. Compressed bodies are counted compressed, unless the transport
+// decompressed the response transparently (see http.Transport.DisableCompression).
+type TransferStats struct {
+	Requests      int64
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// transferCounter counts the transferred bytes atomically.
+type transferCounter struct {
+	requests int64
+	sent     int64
+	received int64
+}
+
+// TransferStats returns the aggregated TransferStats of the requests sent by c with Do and the
+// functions using it. Copies of the client (see Clone) share the statistics.
+func (c *Client) TransferStats() TransferStats {
+	if c.transfer == nil {
+		return TransferStats{}
+	}
+
+	return TransferStats{
+		Requests:      atomic.LoadInt64(&c.transfer.requests),
+		BytesSent:     atomic.LoadInt64(&c.transfer.sent),
+		BytesReceived: atomic.LoadInt64(&c.transfer.received),
+	}
+}
+
+// transferKey is the context key of the transferCounter of a single call.
+type transferKey struct{}
+
+// withTransferCounter returns ctx with a new transferCounter for a single call.
+func withTransferCounter(ctx context.Context) (context.Context, *transferCounter) {
+	t := new(transferCounter)
+	return context.WithValue(ctx, transferKey{}, t), t
+}
+
+// countTransfer wraps the body of req to count the bytes sent into the counters of the client
+// and the call (see withTransferCounter).
+func (c *Client) countTransfer(ctx context.Context, req *http.Request) []*transferCounter {
+	counters := make([]*transferCounter, 0, 2)
+
+	if c.transfer != nil {
+		counters = append(counters, c.transfer)
+	}
+
+	if t, ok := ctx.Value(transferKey{}).(*transferCounter); ok {
+		counters = append(counters, t)
+	}
+
+	for _, t := range counters {
+		atomic.AddInt64(&t.requests, 1)
+	}
+
+	if len(counters) > 0 && req.Body != nil && req.Body != http.NoBody {
+		req.Body = &countingBody{ReadCloser: req.Body, counters: counters, sent: true}
+	}
+
+	return counters
+}
+
+// countingBody is a body counting the bytes read.
+type countingBody struct {
+	io.ReadCloser
+	counters []*transferCounter
+	sent     bool
+}
+
+// Read reads from the body and adds the bytes read to the counters.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	for _, t := range b.counters {
+		if b.sent {
+			atomic.AddInt64(&t.sent, int64(n))
+		} else {
+			atomic.AddInt64(&t.received, int64(n))
+		}
+	}
+
+	return n, err
+}
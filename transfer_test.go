@@ -0,0 +1,51 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferStats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(w, r.Body)
+		_, _ = w.Write([]byte(" "))
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("result", func(t *testing.T) {
+		r, err := Post[message](ctx, c, "/", message{Text: "hello"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(`{"Text":"hello"}`+"\n")), r.BytesSent)
+		assert.Equal(t, r.BytesSent+1, r.BytesReceived)
+
+		r, err = Get[message](ctx, c, "/")
+		assert.NotNil(t, err) // a blank is no JSON
+		assert.Equal(t, int64(0), r.BytesSent)
+		assert.Equal(t, int64(1), r.BytesReceived)
+	})
+
+	t.Run("aggregated", func(t *testing.T) {
+		assert.Equal(t, TransferStats{Requests: 2, BytesSent: 17, BytesReceived: 19}, c.TransferStats())
+
+		req, err := c.NewRequest(http.MethodPost, "/", message{Text: "hi"})
+		assert.Nil(t, err)
+
+		_, err = c.Clone().Do(ctx, req, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, TransferStats{Requests: 3, BytesSent: 31, BytesReceived: 34}, c.TransferStats())
+	})
+
+	t.Run("without New", func(t *testing.T) {
+		assert.Equal(t, TransferStats{}, (&Client{}).TransferStats())
+	})
+}
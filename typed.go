@@ -0,0 +1,147 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// DoTyped sends the request req with the client c and returns the response body decoded into
+// a value of type T (see Do). For pointer types (e.g. *Post) a new value is allocated and the
+// body is decoded into it. On errors the zero value of T is returned.
+func DoTyped[T any](ctx context.Context, c *Client, req *http.Request) (T, *http.Response, error) {
+	var v T
+
+	resp, err := c.Do(ctx, req, target(&v))
+	if err != nil {
+		var zero T
+		return zero, resp, err
+	}
+
+	return v, resp, nil
+}
+
+// DoResult sends the request req with the client c and returns the Result with the response
+// body decoded into a value of type T. If a response was received, the Result is returned
+// with errors too, e.g. to check the status code.
+func DoResult[T any](ctx context.Context, c *Client, req *http.Request) (*Result[T], error) {
+	start := c.Now()
+	buf := &bodyBuffer{budget: c.budget}
+
+	defer buf.release()
+
+	ctx, transfer := withTransferCounter(ctx)
+
+	resp, err := c.Do(ctx, req, buf)
+	if resp == nil {
+		return nil, err
+	}
+
+	rateLimit, ok := ParseRateLimit(resp.Header, c.Now())
+
+	r := &Result[T]{
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		Start:         start,
+		Duration:      c.Now().Sub(start),
+		BytesSent:     atomic.LoadInt64(&transfer.sent),
+		BytesReceived: atomic.LoadInt64(&transfer.received),
+		Response:      resp,
+		body:          buf.Bytes(),
+	}
+
+	if ok {
+		r.RateLimit = &rateLimit
+	}
+
+	if err != nil {
+		return r, err
+	}
+
+	decoded := *resp
+	decoded.Body = ioutil.NopCloser(bytes.NewReader(r.body))
+
+	if err := c.Unmarshal(&decoded, target(&r.Value)); err != nil {
+		return r, err
+	}
+
+	return r, nil
+}
+
+// Result is the result of a request sent with DoResult or the generic helpers (e.g. Get).
+type Result[T any] struct {
+	Value      T
+	StatusCode int
+	Header     http.Header
+	// Start is the time the request was sent and Duration the time until the response body
+	// was read, according to the clock of the client (see WithClock).
+	Start    time.Time
+	Duration time.Duration
+	// BytesSent and BytesReceived are the sizes of the request and response bodies as
+	// transferred (see TransferStats).
+	BytesSent     int64
+	BytesReceived int64
+	// RateLimit is the quota announced by the server, nil if there are no rate limit
+	// headers (see ParseRateLimit).
+	RateLimit *RateLimitInfo
+	// Response is the response, its body is already read and closed (see Body).
+	Response *http.Response
+	body     []byte
+}
+
+// Body returns the raw response body.
+func (r *Result[T]) Body() []byte {
+	return r.body
+}
+
+// Get sends a GET request for urlStr with the request options opts (see NewRequestWithContext)
+// and returns the Result with the response body decoded into a value of type T.
+func Get[T any](ctx context.Context, c *Client, urlStr string, opts ...RequestOpt) (*Result[T], error) {
+	return send[T](ctx, c, http.MethodGet, urlStr, nil, opts)
+}
+
+// Post sends a POST request for urlStr with the encoded body and the request options opts (see
+// NewRequestWithContext) and returns the Result with the response body decoded into a value of
+// type Out. The type of the body is inferred, e.g. Post[*Node](ctx, c, "/nodes", node).
+func Post[Out, In any](ctx context.Context, c *Client, urlStr string, body In, opts ...RequestOpt) (*Result[Out], error) {
+	return send[Out](ctx, c, http.MethodPost, urlStr, body, opts)
+}
+
+// Put sends a PUT request for urlStr with the encoded body and the request options opts (see
+// NewRequestWithContext) and returns the Result with the response body decoded into a value of
+// type Out.
+func Put[Out, In any](ctx context.Context, c *Client, urlStr string, body In, opts ...RequestOpt) (*Result[Out], error) {
+	return send[Out](ctx, c, http.MethodPut, urlStr, body, opts)
+}
+
+// Patch sends a PATCH request for urlStr with the encoded body and the request options opts (see
+// NewRequestWithContext) and returns the Result with the response body decoded into a value of
+// type Out.
+func Patch[Out, In any](ctx context.Context, c *Client, urlStr string, body In, opts ...RequestOpt) (*Result[Out], error) {
+	return send[Out](ctx, c, http.MethodPatch, urlStr, body, opts)
+}
+
+// send creates and sends a request and returns the Result.
+func send[T any](ctx context.Context, c *Client, method, urlStr string, body interface{}, opts []RequestOpt) (*Result[T], error) {
+	req, err := c.NewRequestWithContext(ctx, method, urlStr, body, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return DoResult[T](ctx, c, req)
+}
+
+// target returns the value the response body is decoded into for v: for pointer types a new
+// value is allocated (like new(Post)) and returned, otherwise v.
+func target[T any](v *T) interface{} {
+	if rv := reflect.ValueOf(v).Elem(); rv.Kind() == reflect.Ptr {
+		rv.Set(reflect.New(rv.Type().Elem()))
+		return *v
+	}
+
+	return v
+}
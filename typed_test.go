@@ -0,0 +1,128 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoTyped(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		m := message{Text: r.Method}
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&m)
+		}
+
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		_ = json.NewEncoder(w).Encode(m)
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL)
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("value", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/messages/1", nil)
+		assert.Nil(t, err)
+
+		m, resp, err := DoTyped[message](ctx, c, req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "GET", m.Text)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/messages/1", nil)
+		assert.Nil(t, err)
+
+		m, _, err := DoTyped[*message](ctx, c, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, m)
+		assert.Equal(t, "GET", m.Text)
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/messages/1", nil)
+		assert.Nil(t, err)
+
+		b, _, err := DoTyped[*bytes.Buffer](ctx, c, req)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"Text":"GET"}`, b.String())
+	})
+
+	t.Run("result", func(t *testing.T) {
+		req, err := c.NewRequest(http.MethodGet, "/messages/1", nil)
+		assert.Nil(t, err)
+
+		r, err := DoResult[*message](ctx, c, req)
+		assert.Nil(t, err)
+		assert.Equal(t, "GET", r.Value.Text)
+		assert.Equal(t, http.StatusOK, r.StatusCode)
+		assert.Equal(t, ContentTypeJSON, r.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"Text":"GET"}`, string(r.Body()))
+		assert.False(t, r.Start.IsZero())
+		assert.True(t, r.Duration > 0)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		r, err := Get[*message](ctx, c, "/error")
+		assert.NotNil(t, err)
+		assert.Nil(t, r.Value)
+		assert.Equal(t, http.StatusNotFound, r.StatusCode)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		r, err := Get[message](ctx, c, "/messages")
+		assert.Nil(t, err)
+		assert.Equal(t, "GET", r.Value.Text)
+	})
+
+	t.Run("post put patch", func(t *testing.T) {
+		r, err := Post[*message](ctx, c, "/messages", message{"post"})
+		assert.Nil(t, err)
+		assert.Equal(t, "post", r.Value.Text)
+
+		r, err = Put[*message](ctx, c, "/messages/1", message{"put"})
+		assert.Nil(t, err)
+		assert.Equal(t, "put", r.Value.Text)
+
+		r, err = Patch[*message](ctx, c, "/messages/1", &message{"patch"})
+		assert.Nil(t, err)
+		assert.Equal(t, "patch", r.Value.Text)
+	})
+
+	t.Run("unmarshaler without writer", func(t *testing.T) {
+		c, err := New(ts.URL, WithIdempotencyCache(time.Minute), WithUnmarshalerContext(func(_ context.Context, r *http.Response, v interface{}, _ string) error {
+			return json.NewDecoder(r.Body).Decode(v)
+		}))
+		assert.Nil(t, err)
+
+		r, err := Get[*message](ctx, c, "/messages/1")
+		assert.Nil(t, err)
+		assert.Equal(t, "GET", r.Value.Text)
+		assert.JSONEq(t, `{"Text":"GET"}`, string(r.Body()))
+
+		r, err = Post[*message](ctx, c, "/messages", message{"post"}, SetHeader("Idempotency-Key", "key"))
+		assert.Nil(t, err)
+		assert.Equal(t, "post", r.Value.Text)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		r, err := Get[message](ctx, c, ":")
+		assert.NotNil(t, err)
+		assert.Nil(t, r)
+	})
+}
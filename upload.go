@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"sort"
+)
+
+// NewUploadRequest creates a request with the content of the file name of fsys as body, e.g.
+// from an embed.FS with test fixtures or an in-memory file system. The Content-Type is derived
+// from the file extension (default application/octet-stream), the Accept header is the
+// ContentType of the client.
+func (c *Client) NewUploadRequest(ctx context.Context, method, urlStr string, fsys fs.FS, name string, opts ...RequestOpt) (*http.Request, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newRequest(ctx, method, urlStr, bytes.NewReader(b), fileContentType(name), c.ContentType, opts)
+}
+
+// NewMultipartRequest creates a request with a multipart/form-data body with the files names of
+// fsys in the form field field and the form values of fields.
+func (c *Client) NewMultipartRequest(ctx context.Context, method, urlStr string, fsys fs.FS, field string, names []string, fields map[string]string, opts ...RequestOpt) (*http.Request, error) {
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+
+	for _, k := range sortedKeys(fields) {
+		if err := w.WriteField(k, fields[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range names {
+		if err := writeFile(w, fsys, field, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return c.newRequest(ctx, method, urlStr, buf, w.FormDataContentType(), c.ContentType, opts)
+}
+
+// writeFile writes the file name of fsys as part of the form field field to w.
+func writeFile(w *multipart.Writer, fsys fs.FS, field, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     field,
+		"filename": path.Base(name),
+	}))
+	h.Set("Content-Type", fileContentType(name))
+
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(part, f)
+
+	return err
+}
+
+// fileContentType returns the content type of the file name derived from its extension.
+func fileContentType(name string) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+
+	return "application/octet-stream"
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpload(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fixtures/node.json": {Data: []byte(`{"name":"node"}`)},
+		"fixtures/logo.png":  {Data: []byte("png")},
+		"fixtures/data":      {Data: []byte("data")},
+	}
+
+	c, err := New(baseurl, WithHeader(http.Header{"X-Test": {"upload"}}))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	t.Run("file", func(t *testing.T) {
+		req, err := c.NewUploadRequest(ctx, http.MethodPut, "nodes/1", fsys, "fixtures/node.json")
+		assert.Nil(t, err)
+		assert.Equal(t, "https://hostname.domain/nodes/1", req.URL.String())
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, ContentTypeJSON, req.Header.Get("Accept"))
+		assert.Equal(t, "upload", req.Header.Get("X-Test"))
+		assert.Equal(t, int64(15), req.ContentLength)
+
+		b, err := ioutil.ReadAll(req.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"name":"node"}`, string(b))
+	})
+
+	t.Run("unknown extension", func(t *testing.T) {
+		req, err := c.NewUploadRequest(ctx, http.MethodPut, "data", fsys, "fixtures/data")
+		assert.Nil(t, err)
+		assert.Equal(t, "application/octet-stream", req.Header.Get("Content-Type"))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := c.NewUploadRequest(ctx, http.MethodPut, "data", fsys, "fixtures/missing")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		req, err := c.NewMultipartRequest(ctx, http.MethodPost, "uploads", fsys, "file",
+			[]string{"fixtures/node.json", "fixtures/logo.png"}, map[string]string{"b": "2", "a": "1"})
+		assert.Nil(t, err)
+
+		assert.Nil(t, req.ParseMultipartForm(1024))
+		assert.Equal(t, []string{"1"}, req.MultipartForm.Value["a"])
+		assert.Equal(t, []string{"2"}, req.MultipartForm.Value["b"])
+
+		files := req.MultipartForm.File["file"]
+		assert.Len(t, files, 2)
+		assert.Equal(t, "node.json", files[0].Filename)
+		assert.Equal(t, "image/png", files[1].Header.Get("Content-Type"))
+
+		f, err := files[1].Open()
+		assert.Nil(t, err)
+
+		b, err := ioutil.ReadAll(f)
+		assert.Nil(t, err)
+		assert.Equal(t, "png", string(b))
+	})
+
+	t.Run("multipart missing file", func(t *testing.T) {
+		_, err := c.NewMultipartRequest(ctx, http.MethodPost, "uploads", fsys, "file", []string{"missing"}, nil)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,222 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// DefaultPartSize is the default part size of the Uploader.
+const DefaultPartSize = 8 << 20
+
+// UploadPart is an uploaded part of an Uploader.
+type UploadPart struct {
+	// Number is the number of the part, starting with 1.
+	Number int
+	Size   int64
+	// ETag is the ETag header of the response.
+	ETag string
+}
+
+// Uploader uploads large files in parts (e.g. S3 multipart uploads): it splits the file into
+// parts, uploads them concurrently, each retried independently with the RetryPolicy of the
+// client (see Retry), and completes the upload. The vendor specific calls are hooks, e.g. calling
+// the services of a generated client:
+//
+//	u := httpclient.NewUploader(c)
+//	u.Init = func(ctx context.Context) (string, error) {
+//		r, _, err := client.Upload.Init(ctx, name)
+//		return r.UploadID, err
+//	}
+//	u.PartRequest = func(ctx context.Context, id string, n int, data []byte) (*http.Request, error) {
+//		return c.NewRequestWithContext(ctx, http.MethodPut, name, nil,
+//			httpclient.QueryValues(url.Values{"uploadId": {id}, "partNumber": {strconv.Itoa(n)}}),
+//			httpclient.RawBody(data, "application/octet-stream"))
+//	}
+//	u.Complete = func(ctx context.Context, id string, parts []httpclient.UploadPart) error {
+//		_, err := client.Upload.Complete(ctx, name, id, parts)
+//		return err
+//	}
+type Uploader struct {
+	Client *Client
+
+	// PartSize is the size of the parts (default DefaultPartSize) and Concurrency the maximum
+	// number of parts uploaded concurrently (default DefaultConcurrency). At most Concurrency
+	// parts are held in memory.
+	PartSize    int64
+	Concurrency int
+
+	// Init starts the upload and returns its ID (optional).
+	Init func(ctx context.Context) (string, error)
+
+	// PartRequest returns the request uploading the data of part n of the upload id
+	// (required). It is called for every attempt.
+	PartRequest func(ctx context.Context, id string, n int, data []byte) (*http.Request, error)
+
+	// Complete completes the upload with the uploaded parts ordered by number (optional).
+	Complete func(ctx context.Context, id string, parts []UploadPart) error
+
+	// Abort aborts the upload if it failed after Init (optional).
+	Abort func(ctx context.Context, id string) error
+}
+
+// NewUploader returns an Uploader with the client c and the default settings.
+func NewUploader(c *Client) *Uploader {
+	return &Uploader{
+		Client:      c,
+		PartSize:    DefaultPartSize,
+		Concurrency: DefaultConcurrency,
+	}
+}
+
+// Upload uploads the data read from r and returns the uploaded parts. If the upload fails after
+// Init, it is aborted with Abort.
+func (u *Uploader) Upload(ctx context.Context, r io.Reader) ([]UploadPart, error) {
+	if u.PartRequest == nil {
+		return nil, errors.New("part request cannot be nil")
+	}
+
+	var id string
+
+	if u.Init != nil {
+		var err error
+
+		if id, err = u.Init(ctx); err != nil {
+			return nil, fmt.Errorf("init upload: %w", err)
+		}
+	}
+
+	parts, err := u.upload(ctx, id, r)
+	if err == nil && u.Complete != nil {
+		if err = u.Complete(ctx, id, parts); err != nil {
+			err = fmt.Errorf("complete upload: %w", err)
+		}
+	}
+
+	if err != nil {
+		if u.Abort != nil {
+			if aerr := u.Abort(ctx, id); aerr != nil {
+				err = errors.Join(err, fmt.Errorf("abort upload: %w", aerr))
+			}
+		}
+
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+// upload uploads the parts of r concurrently.
+func (u *Uploader) upload(ctx context.Context, id string, r io.Reader) ([]UploadPart, error) {
+	partSize := u.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+
+	concurrency := u.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		parts []UploadPart
+		errs  []error
+	)
+
+	sem := make(chan struct{}, concurrency)
+
+	for n := 1; ctx.Err() == nil; n++ {
+		select {
+		case <-ctx.Done():
+			continue
+		case sem <- struct{}{}:
+		}
+
+		data := make([]byte, partSize)
+
+		size, err := io.ReadFull(r, data)
+		if errors.Is(err, io.EOF) && n > 1 {
+			<-sem
+			break
+		}
+
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			<-sem
+
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+
+			break
+		}
+
+		wg.Add(1)
+
+		go func(n int, data []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			part, err := u.part(ctx, id, n, data)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("part %d: %w", n, err))
+				cancel()
+
+				return
+			}
+
+			parts = append(parts, part)
+		}(n, data[:size])
+
+		if size < len(data) {
+			break
+		}
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+
+	return parts, nil
+}
+
+// part uploads part n with retries.
+func (u *Uploader) part(ctx context.Context, id string, n int, data []byte) (UploadPart, error) {
+	resp, err := u.Client.Retry(ctx, func(ctx context.Context) (*http.Response, error) {
+		req, err := u.PartRequest(ctx, id, n, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return u.Client.Do(ctx, req, nil)
+	})
+	if err != nil {
+		return UploadPart{}, err
+	}
+
+	return UploadPart{
+		Number: n,
+		Size:   int64(len(data)),
+		ETag:   resp.Header.Get("ETag"),
+	}, nil
+}
@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploader(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 25))
+
+	var (
+		mu       sync.Mutex
+		parts    = map[int][]byte{}
+		attempts = map[int]int{}
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("partNumber"))
+		data, _ := ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts[n]++
+
+		switch {
+		case r.URL.Query().Get("uploadId") != "42":
+			w.WriteHeader(http.StatusBadRequest)
+		case n == 2 && attempts[n] == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			parts[n] = data
+			w.Header().Set("ETag", strconv.Quote(strconv.Itoa(n)))
+		}
+	}))
+	defer ts.Close()
+
+	c, err := New(ts.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}))
+	assert.Nil(t, err)
+
+	var aborted, completed []UploadPart
+
+	newUploader := func(id string) *Uploader {
+		u := NewUploader(c)
+		u.PartSize = 100
+		u.Concurrency = 2
+		u.Init = func(context.Context) (string, error) {
+			return id, nil
+		}
+		u.PartRequest = func(ctx context.Context, id string, n int, data []byte) (*http.Request, error) {
+			return c.NewRequestWithContext(ctx, http.MethodPut, "/file", nil,
+				QueryValues(url.Values{"uploadId": {id}, "partNumber": {strconv.Itoa(n)}}),
+				RawBody(data, "application/octet-stream"))
+		}
+		u.Complete = func(_ context.Context, _ string, parts []UploadPart) error {
+			completed = parts
+			return nil
+		}
+		u.Abort = func(context.Context, string) error {
+			aborted = append(aborted, UploadPart{})
+			return nil
+		}
+
+		return u
+	}
+
+	t.Run("upload", func(t *testing.T) {
+		result, err := newUploader("42").Upload(context.Background(), bytes.NewReader(content))
+		assert.Nil(t, err)
+		assert.Equal(t, []UploadPart{
+			{Number: 1, Size: 100, ETag: `"1"`},
+			{Number: 2, Size: 100, ETag: `"2"`},
+			{Number: 3, Size: 50, ETag: `"3"`},
+		}, result)
+		assert.Equal(t, result, completed)
+		assert.Equal(t, content, bytes.Join([][]byte{parts[1], parts[2], parts[3]}, nil))
+		assert.Equal(t, 2, attempts[2])
+		assert.Empty(t, aborted)
+	})
+
+	t.Run("failed part", func(t *testing.T) {
+		completed = nil
+
+		_, err := newUploader("1").Upload(context.Background(), bytes.NewReader(content))
+		assert.Contains(t, err.Error(), "400 Bad Request")
+		assert.Nil(t, completed)
+		assert.Len(t, aborted, 1)
+	})
+
+	t.Run("without part request", func(t *testing.T) {
+		_, err := NewUploader(c).Upload(context.Background(), bytes.NewReader(content))
+		assert.EqualError(t, err, "part request cannot be nil")
+	})
+}
@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Warmup establishes n connections (including the TLS handshakes) to the BaseURL of the client
+// and parks them in the idle pool of the transport, so the first requests do not suffer from
+// the connection setup latency. It sends n concurrent HEAD requests to the BaseURL (rewritten
+// by the rewrite rules of the client), their status codes are ignored. The transport keeps at
+// most MaxIdleConnsPerHost idle connections (2 for http.DefaultTransport) and HTTP/2
+// multiplexes the requests on a single connection.
+func (c *Client) Warmup(ctx context.Context, n int) error {
+	if n < 1 {
+		return errors.New("number of connections must be at least 1")
+	}
+
+	errs := make([]error, n)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			errs[i] = c.warmup(ctx)
+		}(i)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// warmup sends a HEAD request to the BaseURL.
+func (c *Client) warmup(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodHead, "", http.NoBody, c.ContentType, c.ContentType, nil)
+	if err != nil {
+		return err
+	}
+
+	if req, err = c.prepare(req); err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return redactError(err)
+	}
+
+	// the connection is only reused if the body is read to the end and closed
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	return resp.Body.Close()
+}
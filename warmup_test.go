@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarmup(t *testing.T) {
+	var conns int32
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	ts.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.StartTLS()
+
+	defer ts.Close()
+
+	transport := ts.Client().Transport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+
+	defer transport.CloseIdleConnections()
+
+	c, err := New(ts.URL, WithHTTPClient(&http.Client{Transport: transport}))
+	assert.Nil(t, err)
+
+	t.Run("warmup", func(t *testing.T) {
+		assert.Nil(t, c.Warmup(context.Background(), 3))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&conns))
+
+		wg := sync.WaitGroup{}
+
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				req, err := c.NewRequest(http.MethodGet, "nodes", nil)
+				assert.Nil(t, err)
+
+				_, err = c.Do(context.Background(), req, nil)
+				assert.Nil(t, err)
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, int32(3), atomic.LoadInt32(&conns))
+	})
+
+	t.Run("invalid number", func(t *testing.T) {
+		assert.NotNil(t, c.Warmup(context.Background(), 0))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.NotNil(t, c.Warmup(ctx, 2))
+	})
+}